@@ -7,15 +7,36 @@ import (
 
 	"github.com/joho/godotenv"
 	authController "github.com/raffops/chat_auth/internal/app/auth/controller"
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
 	authService "github.com/raffops/chat_auth/internal/app/auth/service"
+	authzController "github.com/raffops/chat_auth/internal/app/authz/controller"
+	authzModels "github.com/raffops/chat_auth/internal/app/authz/model"
+	authzRepository "github.com/raffops/chat_auth/internal/app/authz/repository"
+	authzService "github.com/raffops/chat_auth/internal/app/authz/service"
+	mfaController "github.com/raffops/chat_auth/internal/app/mfa/controller"
+	mfaRepository "github.com/raffops/chat_auth/internal/app/mfa/repository"
+	mfaService "github.com/raffops/chat_auth/internal/app/mfa/service"
+	purgeModels "github.com/raffops/chat_auth/internal/app/purge/model"
+	purgeRepository "github.com/raffops/chat_auth/internal/app/purge/repository"
+	purgeService "github.com/raffops/chat_auth/internal/app/purge/service"
+	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	sessionController "github.com/raffops/chat_auth/internal/app/sessionManager/controller"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
 	sessionRepository "github.com/raffops/chat_auth/internal/app/sessionManager/repository"
 	sessionService "github.com/raffops/chat_auth/internal/app/sessionManager/service"
+	tokenController "github.com/raffops/chat_auth/internal/app/token/controller"
+	tokenModels "github.com/raffops/chat_auth/internal/app/token/model"
+	tokenRepository "github.com/raffops/chat_auth/internal/app/token/repository"
+	tokenService "github.com/raffops/chat_auth/internal/app/token/service"
+	userController "github.com/raffops/chat_auth/internal/app/user/controller"
 	user "github.com/raffops/chat_auth/internal/app/user/repository"
 	"github.com/raffops/chat_auth/internal/server"
+	"github.com/raffops/chat_commons/pkg/database/dynamodb"
 	"github.com/raffops/chat_commons/pkg/database/postgres"
 	"github.com/raffops/chat_commons/pkg/database/redis"
 	"github.com/raffops/chat_commons/pkg/encryptor"
 	"github.com/raffops/chat_commons/pkg/logger"
+	"github.com/raffops/chat_commons/pkg/passwordHasher"
 	"go.uber.org/zap"
 )
 
@@ -31,6 +52,24 @@ func main() {
 		logger.Fatal("cannot connect to database", zap.Error(err))
 	}
 	userRepo := user.NewPostgresUserRepository(userDatabase)
+	loginRepo := user.NewLoginRepository(userDatabase)
+	identityRepo := user.NewIdentityRepository(userDatabase)
+	providersFile, err := os.ReadFile(os.Getenv("AUTH_PROVIDERS_FILE"))
+	if err != nil {
+		logger.Fatal("cannot read auth providers file", zap.Error(err))
+	}
+	providersConfig, errProviders := authModels.ParseProviders(providersFile)
+	if errProviders != nil {
+		logger.Fatal("cannot parse auth providers", zap.Error(errProviders))
+	}
+	oidcProviders, errProviders := authController.UseProviders(
+		ctx,
+		providersConfig,
+		user.NewAuthTypeRepository(userDatabase),
+	)
+	if errProviders != nil {
+		logger.Fatal("cannot register auth providers", zap.Error(errProviders))
+	}
 	sessionTimeout, err := time.ParseDuration(os.Getenv("SESSION_TIMEOUT"))
 	if err != nil {
 		logger.Fatal("cannot parse session timeout", zap.Error(err))
@@ -38,16 +77,162 @@ func main() {
 
 	defaultEncryptor := encryptor.NewDefaultEncryptor()
 
-	sessionRepo := sessionRepository.NewRedisRepository(redis.GetRedisConn(ctx), defaultEncryptor)
+	var sessionRepo sessionManager.ReaderWriterRepository
+	switch os.Getenv("SESSION_REPOSITORY") {
+	case "", "redis":
+		sessionRepo = sessionRepository.NewRedisRepository(redis.GetRedisConn(ctx), defaultEncryptor)
+	case "memory":
+		sessionRepo = sessionRepository.NewMemoryRepository(defaultEncryptor)
+	case "dynamodb":
+		dynamoRepo, errRepo := sessionRepository.NewDynamodbRepository(
+			ctx,
+			dynamodb.GetDynamodbConn(ctx),
+			defaultEncryptor,
+		)
+		if errRepo != nil {
+			logger.Fatal("cannot create dynamodb session repository", zap.Error(errRepo))
+		}
+		sessionRepo = dynamoRepo
+	case "postgres":
+		sessionRepo = sessionRepository.NewPostgresRepository(userDatabase, defaultEncryptor)
+		reapInterval, err := time.ParseDuration(os.Getenv("SESSION_REAP_INTERVAL"))
+		if err != nil {
+			logger.Fatal("cannot parse session reap interval", zap.Error(err))
+		}
+		sessionRepository.StartReaping(ctx, userDatabase, reapInterval)
+	default:
+		logger.Fatal("session repository not found", zap.String("repository", os.Getenv("SESSION_REPOSITORY")))
+	}
+	refreshTokenTimeout, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TIMEOUT"))
+	if err != nil {
+		logger.Fatal("cannot parse refresh token timeout", zap.Error(err))
+	}
+	policySrv, errPolicy := authzService.NewPolicyService(
+		ctx,
+		authzRepository.NewPolicyRepository(userDatabase),
+		authzModels.DefaultPolicy(os.Getenv("GRPC_DEFAULT_POLICY")),
+	)
+	if errPolicy != nil {
+		logger.Fatal("cannot load grpc method policies", zap.Error(errPolicy))
+	}
+	policyReloadInterval, err := time.ParseDuration(os.Getenv("POLICY_RELOAD_INTERVAL"))
+	if err != nil {
+		logger.Fatal("cannot parse policy reload interval", zap.Error(err))
+	}
+	policySrv.StartReloading(ctx, policyReloadInterval)
+	sessionLimits, errLimits := sessionModels.ParseLimits(os.Getenv("SESSION_LIMITS"))
+	if errLimits != nil {
+		logger.Fatal("cannot parse session limits", zap.Error(errLimits))
+	}
+	sessionExpirations, errExpirations := sessionModels.ParseExpirations(os.Getenv("SESSION_EXPIRATIONS"))
+	if errExpirations != nil {
+		logger.Fatal("cannot parse session expirations", zap.Error(errExpirations))
+	}
 	sessionSrv := sessionService.NewDefaultService(
 		sessionRepo,
 		sessionTimeout,
+		refreshTokenTimeout,
 		os.Getenv("SESSION_MANAGER_SECRET"),
+		policySrv,
+		sessionLimits,
+		sessionExpirations,
+	)
+	accessTokenTimeout, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TIMEOUT"))
+	if err != nil {
+		logger.Fatal("cannot parse access token timeout", zap.Error(err))
+	}
+	keyRing, errKeyRing := tokenRepository.NewPemKeyRing(
+		ctx,
+		os.Getenv("TOKEN_KEYS_DIR"),
+		accessTokenTimeout,
+		tokenModels.JWKSMaxAge,
+	)
+	if errKeyRing != nil {
+		logger.Fatal("cannot load token signing keys", zap.Error(errKeyRing))
+	}
+	keysReloadInterval, err := time.ParseDuration(os.Getenv("TOKEN_KEYS_RELOAD_INTERVAL"))
+	if err != nil {
+		logger.Fatal("cannot parse token keys reload interval", zap.Error(err))
+	}
+	keyRing.StartReloading(ctx, keysReloadInterval)
+	tokenSrv := tokenService.NewJwtService(keyRing, accessTokenTimeout)
+	mfaSrv := mfaService.NewDefaultService(
+		mfaRepository.NewPostgresRepository(userDatabase),
+		sessionRepo,
+		defaultEncryptor,
+		os.Getenv("MFA_ENCRYPTION_SECRET"),
+		os.Getenv("MFA_ISSUER"),
+	)
+
+	authSrv := authService.NewDefaultService(
+		userRepo,
+		loginRepo,
+		identityRepo,
+		sessionRepo,
+		sessionSrv,
+		tokenSrv,
+		mfaSrv,
+		passwordHasher.NewBcryptHasher(),
+	)
+	loginCloseInterval, err := time.ParseDuration(os.Getenv("LOGIN_CLOSE_INTERVAL"))
+	if err != nil {
+		logger.Fatal("cannot parse login close interval", zap.Error(err))
+	}
+	authSrv.StartClosingLogins(ctx, loginCloseInterval)
+	permissionsCacheTtl, err := time.ParseDuration(os.Getenv("PERMISSIONS_CACHE_TTL"))
+	if err != nil {
+		logger.Fatal("cannot parse permissions cache ttl", zap.Error(err))
+	}
+	authzSrv := authzService.NewDefaultService(
+		authzRepository.NewPostgresRepository(userDatabase),
+		sessionSrv,
+		permissionsCacheTtl,
+	)
+	purgeRetention, err := time.ParseDuration(os.Getenv("PURGE_RETENTION"))
+	if err != nil {
+		logger.Fatal("cannot parse purge retention", zap.Error(err))
+	}
+	purgeSrv, errPurge := purgeService.NewDefaultService(
+		purgeRepository.NewPostgresRepository(userDatabase),
+		purgeModels.Mode(os.Getenv("PURGE_MODE")),
+		purgeRetention,
+	)
+	if errPurge != nil {
+		logger.Fatal("cannot create purge service", zap.Error(errPurge))
+	}
+	purgeInterval, err := time.ParseDuration(os.Getenv("PURGE_INTERVAL"))
+	if err != nil {
+		logger.Fatal("cannot parse purge interval", zap.Error(err))
+	}
+	purgeSrv.StartPurging(ctx, purgeInterval)
+	controller := authController.NewController(
+		userRepo,
+		sessionSrv,
+		authSrv,
+		authzSrv,
+		oidcProviders,
+		providersConfig,
+	)
+
+	s := server.NewServer(
+		controller,
+		sessionSrv,
+		tokenController.NewController(tokenSrv),
+		mfaController.NewController(userRepo, mfaSrv),
+		authzSrv,
+		authzController.NewPolicyController(policySrv),
+		userController.NewController(userRepo, loginRepo, sessionSrv),
+		sessionController.NewController(userRepo, sessionSrv),
 	)
-	authSrv := authService.NewDefaultService(userRepo, sessionRepo, sessionSrv)
-	controller := authController.NewController(userRepo, sessionSrv, authSrv)
 
-	s := server.NewServer(controller, sessionSrv)
+	grpcServer := server.NewGrpcServer(authController.NewGrpcController(userRepo, sessionSrv), sessionSrv, authzSrv)
+	go func() {
+		logger.Info("grpc server started")
+		errGrpc := grpcServer.ListenAndServe()
+		if errGrpc != nil {
+			logger.Fatal("cannot start grpc server", zap.Error(errGrpc))
+		}
+	}()
 
 	logger.Info("server started")
 	err = s.ListenAndServe()
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+
+	"github.com/joho/godotenv"
+	sessionRepository "github.com/raffops/chat_auth/internal/app/sessionManager/repository"
+	"github.com/raffops/chat_commons/pkg/database/redis"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// main moves the session index to the sets of sessions by user, once, before the auth
+// servers using the sets are deployed.
+func main() {
+	ctx := context.Background()
+	err := godotenv.Load(".env")
+	if err != nil {
+		logger.Fatal("cannot load .env file", zap.Error(err))
+	}
+
+	migrated, errMigrate := sessionRepository.MigrateSessionIndex(ctx, redis.GetRedisConn(ctx))
+	if errMigrate != nil {
+		logger.Fatal("cannot migrate session index", zap.Int("migrated", migrated), zap.Error(errMigrate))
+	}
+	logger.Info("session index migrated", zap.Int("migrated", migrated))
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	purgeModels "github.com/raffops/chat_auth/internal/app/purge/model"
+	purgeRepository "github.com/raffops/chat_auth/internal/app/purge/repository"
+	purgeService "github.com/raffops/chat_auth/internal/app/purge/service"
+	"github.com/raffops/chat_commons/pkg/database/postgres"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// main purges the deleted users once, to run the purge out of the auth server, like
+// from a cron job.
+func main() {
+	err := godotenv.Load(".env")
+	if err != nil {
+		logger.Fatal("cannot load .env file", zap.Error(err))
+	}
+
+	userDatabase, err := postgres.GetPostgresConn(true)
+	if err != nil {
+		logger.Fatal("cannot connect to database", zap.Error(err))
+	}
+	retention, err := time.ParseDuration(os.Getenv("PURGE_RETENTION"))
+	if err != nil {
+		logger.Fatal("cannot parse purge retention", zap.Error(err))
+	}
+	purgeSrv, errPurge := purgeService.NewDefaultService(
+		purgeRepository.NewPostgresRepository(userDatabase),
+		purgeModels.Mode(os.Getenv("PURGE_MODE")),
+		retention,
+	)
+	if errPurge != nil {
+		logger.Fatal("cannot create purge service", zap.Error(errPurge))
+	}
+
+	purged, errPurge := purgeSrv.Purge(context.Background())
+	if errPurge != nil {
+		logger.Fatal("cannot purge deleted users", zap.Error(errPurge))
+	}
+	logger.Info("deleted users purged", zap.Int("purged", purged))
+}
@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+)
+
+// withClient injects the client of the request in its context, to be recorded in the
+// login history. The address is the one of the connection, so behind a proxy it is the
+// address of the proxy.
+func withClient(r *http.Request) context.Context {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return authModels.ContextWithClient(r.Context(), authModels.Client{Ip: ip, UserAgent: r.UserAgent()})
+}
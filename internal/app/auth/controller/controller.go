@@ -7,16 +7,19 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
-	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
-	"github.com/markbates/goth/providers/github"
-	"github.com/markbates/goth/providers/google"
 	"github.com/raffops/chat_auth/internal/app/auth"
 	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/authz"
+	authzModels "github.com/raffops/chat_auth/internal/app/authz/model"
+	"github.com/raffops/chat_auth/internal/app/oidc"
+	oidcModels "github.com/raffops/chat_auth/internal/app/oidc/model"
 	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
 	"github.com/raffops/chat_auth/internal/app/user"
 	userModels "github.com/raffops/chat_auth/internal/app/user/models"
 	"github.com/raffops/chat_commons/pkg/errs"
@@ -24,41 +27,13 @@ import (
 	"go.uber.org/zap"
 )
 
-func init() {
-	sanityCheck()
-	goth.UseProviders(
-		google.New(
-			os.Getenv("GOOGLE_APPLICATION_KEY"),
-			os.Getenv("GOOGLE_APPLICATION_SECRET"),
-			"http://localhost:8080/login/google/callback",
-		),
-		github.New(
-			os.Getenv("GITHUB_APPLICATION_KEY"),
-			os.Getenv("GITHUB_APPLICATION_SECRET"),
-			"http://localhost:8080/login/github/callback",
-		),
-	)
-
-	key := os.Getenv("SESSION_SECRET") // Replace with your SESSION_SECRET or similar
-	maxAge := 86400 * 30               // 30 days
-	isProd := false                    // SetEncryptedHashmap to true when serving over https
-
-	store := sessions.NewCookieStore([]byte(key))
-	store.MaxAge(maxAge)
-	store.Options.Path = "/"
-	store.Options.HttpOnly = true // HttpOnly should always be enabled
-	store.Options.Secure = isProd
-
-	gothic.Store = store
-}
+// signUpTimeout is how long the identity of a callback waits for its sign up to be completed.
+const signUpTimeout = 10 * time.Minute
 
 func sanityCheck() {
 	envVariables := []string{
-		"GOOGLE_APPLICATION_KEY",
-		"GOOGLE_APPLICATION_SECRET",
-		"GITHUB_APPLICATION_KEY",
-		"GITHUB_APPLICATION_SECRET",
 		"SESSION_SECRET",
+		"APP_ENV",
 	}
 
 	for _, envVariable := range envVariables {
@@ -72,6 +47,9 @@ type controller struct {
 	userRepo       user.ReaderRepository
 	sessionService sessionManager.Service
 	authService    auth.Service
+	authzService   authz.Service
+	oidcProviders  map[string]oidc.Provider
+	providers      authModels.ProvidersConfig
 }
 
 func (c *controller) DeleteUser(w http.ResponseWriter, r *http.Request) {
@@ -85,26 +63,30 @@ func (c *controller) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		)
 		return
 	}
-	userToDelete, err := c.userRepo.GetUser(ctx, "username", username)
+	userToDelete, err := c.userRepo.GetUser(ctx, "username", username, false)
 	if err != nil {
 		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
 		return
 	}
 
-	sessionId := r.Header.Get("Authorization")
-	sessionId, _ = strings.CutPrefix(sessionId, "Bearer ")
-
-	session, err := c.sessionService.GetSession(ctx, sessionId)
+	principal, err := sessionModels.PrincipalFromRequest(r)
 	if err != nil {
 		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
 		return
 	}
-	if session["role"] != authModels.RoleAdmin && session["user_id"] != userToDelete.Id {
-		http.Error(w,
-			errs.NewError(errs.ErrNotAuthorized, fmt.Errorf("not authorized to delete this user")).Error(),
-			http.StatusForbidden,
-		)
-		return
+	if principal.UserId != userToDelete.Id {
+		allowed, err := c.authzService.HasPermission(ctx, principal.Role, authzModels.PermissionDeleteUser)
+		if err != nil {
+			http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+			return
+		}
+		if !allowed {
+			http.Error(w,
+				errs.NewError(errs.ErrNotAuthorized, fmt.Errorf("not authorized to delete this user")).Error(),
+				http.StatusForbidden,
+			)
+			return
+		}
 	}
 
 	err = c.authService.DeleteUser(ctx, userToDelete)
@@ -135,66 +117,61 @@ func (c *controller) Logout(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Session logged out"))
 }
 
+// SignUp completes the sign up of the identity kept by the callback, with the username of the
+// request. The identity is kept until the user is created, so a username already taken can be
+// retried with another one.
 func (c *controller) SignUp(w http.ResponseWriter, r *http.Request) {
-	session, err := gothic.Store.Get(r, "session-name")
-	if err != nil {
+	var request authModels.SignUpRequest
+	errDecode := json.NewDecoder(r.Body).Decode(&request)
+	if errDecode != nil {
 		http.Error(w,
-			errs.NewError(errs.ErrInternal, err).Error(),
-			http.StatusInternalServerError,
+			errs.NewError(errs.ErrBadRequest, fmt.Errorf("invalid sign up request")).Error(),
+			http.StatusBadRequest,
 		)
 		return
 	}
-	ctx := r.Context()
-
-	username := session.Values["username"].(string)
-	email := session.Values["email"].(string)
-	authType := session.Values["authType"].(string)
-	role := session.Values["role"].(string)
-
-	authTypeId, ok := userModels.MapAuthTypeString[authType]
-	if !ok {
+	session, err := gothic.Store.Get(r, "session-name")
+	if err != nil {
 		http.Error(w,
-			errs.NewError(errs.ErrBadRequest,
-				fmt.Errorf("auth type %s not found", authType),
-			).Error(),
+			errs.NewError(errs.ErrInternal, err).Error(),
 			http.StatusInternalServerError,
 		)
 		return
 	}
-	roleId, ok := authModels.MapRoleString[role]
-	if !ok {
-		http.Error(w,
-			errs.NewError(errs.ErrBadRequest,
-				fmt.Errorf("role %s not found", role),
-			).Error(),
-			http.StatusInternalServerError,
-		)
+
+	identity, role, errPending := pendingSignUp(session)
+	if errPending != nil {
+		http.Error(w, errPending.Error(), errs.GetHttpStatusCode(errPending))
 		return
 	}
-	token, errSignup := c.authService.SignUp(ctx, username, email, authTypeId, roleId)
-
+	token, errSignup := c.authService.SignUp(withClient(r), request.Username, identity, role)
 	if errSignup != nil {
 		http.Error(w, errSignup.Error(), errs.GetHttpStatusCode(errSignup))
 		return
 	}
 
-	response := map[string]any{
-		"token": token,
+	clearPendingSignUp(session)
+	if err = session.Save(r, w); err != nil {
+		logger.Error("cannot clear pending sign up", zap.Error(err))
 	}
+	responseString, _ := json.Marshal(token)
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(responseString)
+}
 
-	responseString, _ := json.Marshal(response)
-	_, err = w.Write(responseString)
-	if err != nil {
+// Login starts the login with the provider. Every login has its own state, checked by the
+// callback before it expires. The providers served by the 'oidc' client use PKCE, and so do the
+// goth providers of pkceProviderTypes. The optional 'redirect_uri' must be in the allowlist.
+func (c *controller) Login(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectUri := query.Get("redirect_uri")
+	if redirectUri != "" && !c.providers.AllowsRedirectUri(redirectUri) {
 		http.Error(w,
-			errs.NewError(errs.ErrInternal, err).Error(),
-			http.StatusInternalServerError,
+			errs.NewError(errs.ErrBadRequest, fmt.Errorf("redirect uri %s not allowed", redirectUri)).Error(),
+			http.StatusBadRequest,
 		)
 		return
 	}
-	w.WriteHeader(http.StatusCreated)
-}
-
-func (c *controller) Login(w http.ResponseWriter, r *http.Request) {
 	session, err := gothic.Store.Get(r, "session-name")
 	if err != nil {
 		http.Error(w,
@@ -204,8 +181,12 @@ func (c *controller) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session.Values["username"] = r.URL.Query().Get("username")
-	err = session.Save(r, w)
+	// a login that is not the one started by 'LinkIdentity' logs in, instead of linking
+	if query.Get("link") != "true" {
+		delete(session.Values, "linkUserId")
+		delete(session.Values, "linkSessionId")
+	}
+	authRequest, err := oidcModels.NewAuthRequest()
 	if err != nil {
 		http.Error(w,
 			errs.NewError(errs.ErrInternal, err).Error(),
@@ -213,11 +194,8 @@ func (c *controller) Login(w http.ResponseWriter, r *http.Request) {
 		)
 		return
 	}
-	gothic.BeginAuthHandler(w, r)
-}
-
-func (c *controller) Callback(w http.ResponseWriter, r *http.Request) {
-	session, err := gothic.Store.Get(r, "session-name")
+	saveAuthRequest(session, authRequest, redirectUri)
+	err = session.Save(r, w)
 	if err != nil {
 		http.Error(w,
 			errs.NewError(errs.ErrInternal, err).Error(),
@@ -225,7 +203,40 @@ func (c *controller) Callback(w http.ResponseWriter, r *http.Request) {
 		)
 		return
 	}
-	u, err := gothic.CompleteUserAuth(w, r)
+	providerName := mux.Vars(r)["provider"]
+	if provider, ok := c.oidcProviders[providerName]; ok {
+		http.Redirect(w, r, provider.AuthCodeUrl(authRequest), http.StatusFound)
+		return
+	}
+	// gothic sends the state of the query, instead of one of its own
+	query.Set("state", authRequest.State)
+	r.URL.RawQuery = query.Encode()
+	authUrl, errAuth := gothic.GetAuthURL(w, r)
+	if errAuth != nil {
+		http.Error(w, errs.NewError(errs.ErrBadRequest, errAuth).Error(), http.StatusBadRequest)
+		return
+	}
+	if c.gothUsesPkce(providerName) {
+		authUrl, errAuth = withCodeChallenge(authUrl, authRequest.CodeVerifier)
+		if errAuth != nil {
+			http.Error(w, errs.NewError(errs.ErrInternal, errAuth).Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	http.Redirect(w, r, authUrl, http.StatusTemporaryRedirect)
+}
+
+// gothUsesPkce reports whether the goth provider named name uses PKCE, see pkceProviderTypes.
+func (c *controller) gothUsesPkce(name string) bool {
+	config, ok := c.providers.Provider(name)
+	return ok && pkceProviderTypes[config.Type]
+}
+
+// Callback completes the login with the provider, and finds the user by the subject of its
+// identity. Unknown identities are sent to sign up, unless the login was started to link the
+// identity to the user of a session.
+func (c *controller) Callback(w http.ResponseWriter, r *http.Request) {
+	session, err := gothic.Store.Get(r, "session-name")
 	if err != nil {
 		http.Error(w,
 			errs.NewError(errs.ErrInternal, err).Error(),
@@ -233,98 +244,167 @@ func (c *controller) Callback(w http.ResponseWriter, r *http.Request) {
 		)
 		return
 	}
-	if u.Email == "" {
-		http.Error(w,
-			errs.NewError(errs.ErrInternal,
-				fmt.Errorf("email not found in %s response", u.Provider),
-			).Error(),
-			http.StatusInternalServerError,
-		)
+	redirectUri, _ := session.Values["authRedirectUri"].(string)
+	authRequest, errState := completeAuthRequest(w, r, session)
+	if errState != nil {
+		writeCallbackError(w, r, redirectUri, errState)
 		return
 	}
 
-	ctx := r.Context()
-	username := session.Values["username"]
 	authType := mux.Vars(r)["provider"]
-	getUser, errGetUser := c.userRepo.GetUser(ctx, "username", username)
-	if errGetUser != nil && errors.Is(errGetUser.SvcError(), errs.ErrNotFound) {
-		redirectToSignUp(w, r, session, u.Email, authType)
-		return
+	u := userModels.User{Role: authModels.RoleUser}
+	var identity userModels.Identity
+	if provider, ok := c.oidcProviders[authType]; ok {
+		var errOidc errs.ChatError
+		u, identity, errOidc = provider.Exchange(r.Context(), r.URL.Query().Get("code"), authRequest)
+		if errOidc != nil {
+			writeCallbackError(w, r, redirectUri, errOidc)
+			return
+		}
+	} else {
+		if c.gothUsesPkce(authType) {
+			// the goth sessions of pkceProviderTypes send the code verifier of the callback query
+			query := r.URL.Query()
+			query.Set("code_verifier", authRequest.CodeVerifier)
+			r.URL.RawQuery = query.Encode()
+		}
+		gothUser, err := gothic.CompleteUserAuth(w, r)
+		if err != nil {
+			writeCallbackError(w, r, redirectUri, errs.NewError(errs.ErrInternal, err))
+			return
+		}
+		u.Username = gothUser.NickName
+		u.Email = gothUser.Email
+		identity = userModels.Identity{Provider: authType, Subject: gothUser.UserID, Email: gothUser.Email}
 	}
-	if errGetUser != nil {
-		http.Error(w, errGetUser.Error(), errs.GetHttpStatusCode(errGetUser))
+	if identity.Subject == "" || u.Email == "" {
+		writeCallbackError(w, r, redirectUri, errs.NewError(errs.ErrInternal,
+			fmt.Errorf("subject or email not found in %s response", authType),
+		))
 		return
 	}
 
-	token, errLogin := c.authService.Login(ctx, getUser.Username, u.Email)
-	if errLogin != nil {
-		http.Error(w, errLogin.Error(), errs.GetHttpStatusCode(errLogin))
+	if _, ok := session.Values["linkUserId"]; ok {
+		c.completeLink(w, r, session, identity, redirectUri)
 		return
 	}
 
-	response := map[string]any{
-		"token": token,
+	ctx := withClient(r)
+	token, errLogin := c.authService.Login(ctx, identity)
+	if errLogin != nil && errors.Is(errLogin.SvcError(), errs.ErrNotFound) {
+		startSignUp(w, r, session, u, identity, redirectUri)
+		return
 	}
-	responseString, _ := json.Marshal(response)
-	_, err = w.Write(responseString)
-	if err != nil {
-		http.Error(w,
-			errs.NewError(errs.ErrInternal, err).Error(),
-			http.StatusInternalServerError,
-		)
+	if errLogin != nil {
+		writeCallbackError(w, r, redirectUri, errLogin)
+		return
 	}
-	w.WriteHeader(http.StatusOK)
+	writeCallback(w, r, redirectUri, http.StatusOK, token)
 }
 
 func (c *controller) Refresh(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	bearerToken := r.Header.Get("Authorization")
-	token, ok := strings.CutPrefix(bearerToken, "Bearer ")
-	if !ok || token == "" {
+	var request struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	errDecode := json.NewDecoder(r.Body).Decode(&request)
+	if errDecode != nil || request.RefreshToken == "" {
 		http.Error(
 			w,
-			errs.NewError(errs.ErrNotAuthorized, fmt.Errorf("token not found")).Error(),
+			errs.NewError(errs.ErrNotAuthorized, fmt.Errorf("refresh token not found")).Error(),
 			http.StatusUnauthorized,
 		)
+		return
 	}
-	err := c.authService.Refresh(ctx, token)
+	token, err := c.authService.Refresh(ctx, request.RefreshToken)
 	if err != nil {
 		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	_, errWrite := w.Write([]byte("Session refreshed"))
+
+	responseString, _ := json.Marshal(token)
+	_, errWrite := w.Write(responseString)
 	if errWrite != nil {
-		return
+		http.Error(w,
+			errs.NewError(errs.ErrInternal, errWrite).Error(),
+			http.StatusInternalServerError,
+		)
 	}
 }
 
-func redirectToSignUp(
+// startSignUp keeps the identity in the cookie session for 'SignUp', which completes it with
+// the username chosen by the user. The username at the provider is only suggested.
+func startSignUp(
 	w http.ResponseWriter,
 	r *http.Request,
 	session *sessions.Session,
-	email string,
-	authType string,
+	u userModels.User,
+	identity userModels.Identity,
+	redirectUri string,
 ) {
-	session.Values["email"] = email
-	session.Values["authType"] = authType
-	session.Values["role"] = authModels.MapRole[authModels.RoleUser]
+	session.Values["signUpProvider"] = identity.Provider
+	session.Values["signUpSubject"] = identity.Subject
+	session.Values["signUpEmail"] = identity.Email
+	session.Values["signUpRole"] = authModels.MapRole[u.Role]
+	session.Values["signUpExpiresAt"] = time.Now().Add(signUpTimeout).Unix()
 	err := session.Save(r, w)
 	if err != nil {
-		http.Error(
-			w,
-			errs.NewError(errs.ErrInternal, err).Error(),
-			http.StatusInternalServerError,
-		)
+		writeCallbackError(w, r, redirectUri, errs.NewError(errs.ErrInternal, err))
 		return
 	}
-	http.Redirect(w, r, "/signUp", http.StatusFound)
+	writeCallback(w, r, redirectUri, http.StatusAccepted, authModels.PendingSignUp{
+		SignUpRequired:    true,
+		Provider:          identity.Provider,
+		Email:             identity.Email,
+		SuggestedUsername: u.Username,
+	})
 }
 
-func NewController(userRepository user.ReaderRepository, sessionService sessionManager.Service, authService auth.Service) auth.Controller {
+// pendingSignUp returns the identity kept by 'startSignUp' and the role of its user.
+func pendingSignUp(session *sessions.Session) (userModels.Identity, authModels.RoleId, errs.ChatError) {
+	identity := userModels.Identity{}
+	identity.Provider, _ = session.Values["signUpProvider"].(string)
+	identity.Subject, _ = session.Values["signUpSubject"].(string)
+	identity.Email, _ = session.Values["signUpEmail"].(string)
+	role, _ := session.Values["signUpRole"].(string)
+	expiresAt, _ := session.Values["signUpExpiresAt"].(int64)
+	if identity.Subject == "" || time.Now().Unix() > expiresAt {
+		return userModels.Identity{}, 0, errs.NewError(errs.ErrBadRequest,
+			fmt.Errorf("no pending sign up, log in with a provider first"),
+		)
+	}
+	roleId, ok := authModels.MapRoleString[role]
+	if !ok {
+		return userModels.Identity{}, 0, errs.NewError(errs.ErrInternal, fmt.Errorf("role %s not found", role))
+	}
+	return identity, roleId, nil
+}
+
+func clearPendingSignUp(session *sessions.Session) {
+	for _, key := range []string{"signUpProvider", "signUpSubject", "signUpEmail", "signUpRole", "signUpExpiresAt"} {
+		delete(session.Values, key)
+	}
+}
+
+func NewController(
+	userRepository user.ReaderRepository,
+	sessionService sessionManager.Service,
+	authService auth.Service,
+	authzService authz.Service,
+	oidcProviders map[string]oidc.Provider,
+	providersConfig authModels.ProvidersConfig,
+) auth.Controller {
+	sanityCheck()
+
+	store := sessions.NewCookieStore([]byte(os.Getenv("SESSION_SECRET")))
+	store.Options = cookieOptions(os.Getenv("APP_ENV"))
+	gothic.Store = store
 	return &controller{
 		userRepo:       userRepository,
 		sessionService: sessionService,
 		authService:    authService,
+		authzService:   authzService,
+		oidcProviders:  oidcProviders,
+		providers:      providersConfig,
 	}
 }
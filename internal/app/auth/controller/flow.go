@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/sessions"
+	oidcModels "github.com/raffops/chat_auth/internal/app/oidc/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+const (
+	// authStateTimeout is how long a login waits for the callback of its provider.
+	authStateTimeout = 10 * time.Minute
+	// cookieMaxAge bounds the cookie session, which only holds the logins and sign ups in progress.
+	cookieMaxAge = 30 * time.Minute
+)
+
+var errExpiredState = errors.New("login expired, start it again")
+
+// cookieOptions are the options of the cookie session in the environment of APP_ENV. The cookie
+// is only sent over https, except on 'local'. It is 'SameSite=Lax' in every environment, since
+// the callback is a redirect from the site of the provider, which a 'Strict' cookie does not
+// follow.
+func cookieOptions(appEnv string) *sessions.Options {
+	return &sessions.Options{
+		Path:     "/",
+		MaxAge:   int(cookieMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   appEnv != "local",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// saveAuthRequest keeps the auth request of a login in the cookie session until its callback, with
+// its expiration and the redirect uri of the client.
+func saveAuthRequest(session *sessions.Session, request oidcModels.AuthRequest, redirectUri string) {
+	session.Values["authState"] = request.State
+	session.Values["authNonce"] = request.Nonce
+	session.Values["authCodeVerifier"] = request.CodeVerifier
+	session.Values["authExpiresAt"] = time.Now().Add(authStateTimeout).Unix()
+	session.Values["authRedirectUri"] = redirectUri
+}
+
+// completeAuthRequest checks the state of the callback against the one saved by the login, and
+// returns the auth request. The values of the login are removed from the session, so the callback
+// cannot be replayed.
+func completeAuthRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+	session *sessions.Session,
+) (oidcModels.AuthRequest, errs.ChatError) {
+	request := oidcModels.AuthRequest{}
+	request.State, _ = session.Values["authState"].(string)
+	request.Nonce, _ = session.Values["authNonce"].(string)
+	request.CodeVerifier, _ = session.Values["authCodeVerifier"].(string)
+	expiresAt, _ := session.Values["authExpiresAt"].(int64)
+	for _, key := range []string{"authState", "authNonce", "authCodeVerifier", "authExpiresAt", "authRedirectUri"} {
+		delete(session.Values, key)
+	}
+	if err := session.Save(r, w); err != nil {
+		return oidcModels.AuthRequest{}, errs.NewError(errs.ErrInternal, err)
+	}
+
+	query := r.URL.Query()
+	if request.State == "" || subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(request.State)) != 1 {
+		return oidcModels.AuthRequest{}, errs.NewError(errs.ErrNotAuthenticated, fmt.Errorf("invalid state"))
+	}
+	if time.Now().Unix() > expiresAt {
+		return oidcModels.AuthRequest{}, errs.NewError(errs.ErrNotAuthenticated, errExpiredState)
+	}
+	if query.Get("error") != "" {
+		return oidcModels.AuthRequest{}, errs.NewError(errs.ErrNotAuthenticated,
+			fmt.Errorf("login failed: %s %s", query.Get("error"), query.Get("error_description")),
+		)
+	}
+	return request, nil
+}
+
+// withCodeChallenge adds the 'S256' code challenge of the code verifier to the auth url of a goth
+// provider.
+func withCodeChallenge(authUrl, codeVerifier string) (string, error) {
+	parsed, err := url.Parse(authUrl)
+	if err != nil {
+		return "", err
+	}
+	query := parsed.Query()
+	query.Set("code_challenge", oidcModels.CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// writeCallback answers the callback with the response, as JSON, or in the fragment of the
+// redirect uri chosen on the login, so SPAs receive it on their own page. The fragment is not
+// sent to servers, so the tokens stay in the browser.
+func writeCallback(w http.ResponseWriter, r *http.Request, redirectUri string, status int, response interface{}) {
+	if redirectUri == "" {
+		responseString, _ := json.Marshal(response)
+		w.WriteHeader(status)
+		_, _ = w.Write(responseString)
+		return
+	}
+	redirectWithFragment(w, r, redirectUri, fragment(response))
+}
+
+// writeCallbackError answers the callback with the error, in the fragment of the redirect uri as
+// 'error' and 'error_description', like RFC 6749 does, when there is one.
+func writeCallbackError(w http.ResponseWriter, r *http.Request, redirectUri string, err errs.ChatError) {
+	status := errs.GetHttpStatusCode(err)
+	if redirectUri == "" {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	redirectWithFragment(w, r, redirectUri, url.Values{
+		"error":             {strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")},
+		"error_description": {err.Error()},
+	})
+}
+
+func redirectWithFragment(w http.ResponseWriter, r *http.Request, redirectUri string, values url.Values) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	http.Redirect(w, r, redirectUri+"#"+values.Encode(), http.StatusFound)
+}
+
+// fragment encodes the JSON fields of the response as the values of a fragment. Lists are
+// joined with commas.
+func fragment(response interface{}) url.Values {
+	data, _ := json.Marshal(response)
+	fields := map[string]json.RawMessage{}
+	_ = json.Unmarshal(data, &fields)
+	values := url.Values{}
+	for name, raw := range fields {
+		var text string
+		var list []string
+		switch {
+		case json.Unmarshal(raw, &text) == nil:
+			values.Set(name, text)
+		case json.Unmarshal(raw, &list) == nil:
+			values.Set(name, strings.Join(list, ","))
+		default:
+			values.Set(name, string(raw))
+		}
+	}
+	return values
+}
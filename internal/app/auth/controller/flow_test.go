@@ -0,0 +1,314 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/providers/fitbit"
+	"github.com/markbates/goth/providers/google"
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/oidc"
+	oidcModels "github.com/raffops/chat_auth/internal/app/oidc/model"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	authMock "github.com/raffops/chat_auth/test/mocks/auth"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/stretchr/testify/mock"
+)
+
+const allowedRedirectUri = "https://chat.example.com/auth"
+
+// fakeProvider authenticates every code as the same identity, and keeps the auth request of
+// the last exchange.
+type fakeProvider struct {
+	exchanged oidcModels.AuthRequest
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) AuthCodeUrl(request oidcModels.AuthRequest) string {
+	return "https://provider.example.com/authorize?" + url.Values{"state": {request.State}}.Encode()
+}
+
+func (p *fakeProvider) Exchange(
+	ctx context.Context,
+	code string,
+	request oidcModels.AuthRequest,
+) (userModels.User, userModels.Identity, errs.ChatError) {
+	p.exchanged = request
+	identity := userModels.Identity{Provider: "fake", Subject: "1080", Email: "john@doe"}
+	return userModels.User{Username: "jon", Email: "john@doe"}, identity, nil
+}
+
+func (p *fakeProvider) Verify(ctx context.Context, idToken, nonce string) (oidcModels.Claims, errs.ChatError) {
+	return oidcModels.Claims{}, nil
+}
+
+func newFlowController(t *testing.T) (*controller, *authMock.Service, *fakeProvider) {
+	t.Setenv("SESSION_SECRET", "0123456789abcdef0123456789abcdef")
+	t.Setenv("APP_ENV", "local")
+	authSrv := authMock.NewService(t)
+	provider := &fakeProvider{}
+	c := NewController(
+		nil,
+		nil,
+		authSrv,
+		nil,
+		map[string]oidc.Provider{"fake": provider},
+		authModels.ProvidersConfig{RedirectUris: []string{allowedRedirectUri}},
+	)
+	return c.(*controller), authSrv, provider
+}
+
+// startLogin starts a login with the fake provider, and returns the response with the state
+// sent to the provider.
+func startLogin(c *controller, query string) (*httptest.ResponseRecorder, string) {
+	w := httptest.NewRecorder()
+	r := mux.SetURLVars(httptest.NewRequest("GET", "/login/fake?"+query, nil), map[string]string{"provider": "fake"})
+	c.Login(w, r)
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		return w, ""
+	}
+	return w, location.Query().Get("state")
+}
+
+// callback sends the callback of the provider with the state and the cookies of the browser.
+func callback(c *controller, state string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	query := url.Values{"state": {state}, "code": {"code"}}.Encode()
+	r := httptest.NewRequest("GET", "/callback/fake?"+query, nil)
+	for _, cookie := range cookies {
+		r.AddCookie(cookie)
+	}
+	c.Callback(w, mux.SetURLVars(r, map[string]string{"provider": "fake"}))
+	return w
+}
+
+// expireLogin returns the cookies of the login with its state expired.
+func expireLogin(t *testing.T, cookies []*http.Cookie) []*http.Cookie {
+	r := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range cookies {
+		r.AddCookie(cookie)
+	}
+	session, err := gothic.Store.Get(r, "session-name")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	session.Values["authExpiresAt"] = time.Now().Add(-time.Second).Unix()
+	w := httptest.NewRecorder()
+	if err = session.Save(r, w); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	return w.Result().Cookies()
+}
+
+func TestController_CallbackChecksState(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     func(state string) string
+		cookies   func(t *testing.T, cookies []*http.Cookie) []*http.Cookie
+		wantCode  int
+		wantError string
+	}{
+		{
+			name:     "Test valid state",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:      "Test state mismatch",
+			state:     func(state string) string { return "other" + state },
+			wantCode:  http.StatusUnauthorized,
+			wantError: "invalid state",
+		},
+		{
+			name:      "Test callback without the login cookie",
+			cookies:   func(t *testing.T, cookies []*http.Cookie) []*http.Cookie { return nil },
+			wantCode:  http.StatusUnauthorized,
+			wantError: "invalid state",
+		},
+		{
+			name:      "Test expired state",
+			cookies:   expireLogin,
+			wantCode:  http.StatusUnauthorized,
+			wantError: errExpiredState.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, authSrv, provider := newFlowController(t)
+			if tt.wantCode == http.StatusOK {
+				authSrv.EXPECT().Login(mock.Anything, mock.Anything).
+					Return(authModels.Token{SessionId: "session"}, nil).Once()
+			}
+			login, state := startLogin(c, "")
+			if login.Code != http.StatusFound || state == "" {
+				t.Fatalf("Login() got = %v, want a redirect with the state", login.Code)
+			}
+			cookies := login.Result().Cookies()
+			if tt.cookies != nil {
+				cookies = tt.cookies(t, cookies)
+			}
+			if tt.state != nil {
+				state = tt.state(state)
+			}
+
+			w := callback(c, state, cookies)
+			if w.Code != tt.wantCode {
+				t.Fatalf("Callback() got = %v, want %v", w.Code, tt.wantCode)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantError) {
+				t.Errorf("Callback() body = %v, want %v", w.Body.String(), tt.wantError)
+			}
+			if tt.wantCode == http.StatusOK && provider.exchanged.CodeVerifier == "" {
+				t.Errorf("Callback() exchanged the code without the code verifier")
+			}
+		})
+	}
+}
+
+func TestController_CallbackCannotBeReplayed(t *testing.T) {
+	c, authSrv, _ := newFlowController(t)
+	authSrv.EXPECT().Login(mock.Anything, mock.Anything).Return(authModels.Token{SessionId: "session"}, nil).Once()
+	login, state := startLogin(c, "")
+
+	first := callback(c, state, login.Result().Cookies())
+	if first.Code != http.StatusOK {
+		t.Fatalf("Callback() got = %v, want %v", first.Code, http.StatusOK)
+	}
+	cleared := first.Result().Cookies()
+	if len(cleared) == 0 {
+		t.Fatalf("Callback() did not clear the values of the login")
+	}
+	replay := callback(c, state, cleared)
+	if replay.Code != http.StatusUnauthorized {
+		t.Errorf("Callback() replayed got = %v, want %v", replay.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestController_LoginChecksRedirectUri(t *testing.T) {
+	tests := []struct {
+		name        string
+		redirectUri string
+		wantCode    int
+	}{
+		{
+			name:        "Test allowed redirect uri",
+			redirectUri: allowedRedirectUri,
+			wantCode:    http.StatusFound,
+		},
+		{
+			name:        "Test redirect uri with another path",
+			redirectUri: allowedRedirectUri + "/admin",
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name:        "Test redirect uri of another site",
+			redirectUri: "https://evil.example.com/auth",
+			wantCode:    http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, authSrv, _ := newFlowController(t)
+			w, state := startLogin(c, url.Values{"redirect_uri": {tt.redirectUri}}.Encode())
+			if w.Code != tt.wantCode {
+				t.Fatalf("Login() got = %v, want %v", w.Code, tt.wantCode)
+			}
+			if tt.wantCode != http.StatusFound {
+				if len(w.Result().Cookies()) != 0 {
+					t.Errorf("Login() with a rejected redirect uri started the login")
+				}
+				return
+			}
+
+			authSrv.EXPECT().Login(mock.Anything, mock.Anything).
+				Return(authModels.Token{SessionId: "session"}, nil).Once()
+			callbackResponse := callback(c, state, w.Result().Cookies())
+			location := callbackResponse.Header().Get("Location")
+			if callbackResponse.Code != http.StatusFound || !strings.HasPrefix(location, tt.redirectUri+"#") {
+				t.Errorf("Callback() got = %v to %v, want a redirect to %v",
+					callbackResponse.Code, location, tt.redirectUri)
+			}
+		})
+	}
+}
+
+func TestController_GothLoginUsesPkce(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider goth.Provider
+		wantPkce bool
+	}{
+		{
+			name:     "Test provider sending the code verifier",
+			provider: fitbit.New("key", "secret", "https://auth.example.com/login/fitbit/callback"),
+			wantPkce: true,
+		},
+		{
+			name:     "Test provider exchanging the code without the code verifier",
+			provider: google.New("key", "secret", "https://auth.example.com/login/google/callback"),
+			wantPkce: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, authSrv, _ := newFlowController(t)
+			name := tt.provider.Name()
+			c.providers.Providers = []authModels.ProviderConfig{{Name: name, Type: name}}
+			goth.UseProviders(tt.provider)
+			t.Cleanup(goth.ClearProviders)
+			var codeVerifier string
+			completeUserAuth := gothic.CompleteUserAuth
+			gothic.CompleteUserAuth = func(w http.ResponseWriter, r *http.Request) (goth.User, error) {
+				codeVerifier = r.URL.Query().Get("code_verifier")
+				return goth.User{UserID: "1080", Email: "john@doe", NickName: "jon"}, nil
+			}
+			t.Cleanup(func() { gothic.CompleteUserAuth = completeUserAuth })
+
+			login := httptest.NewRecorder()
+			loginRequest := httptest.NewRequest("GET", "/login/"+name, nil)
+			c.Login(login, mux.SetURLVars(loginRequest, map[string]string{"provider": name}))
+			location, err := url.Parse(login.Header().Get("Location"))
+			if login.Code != http.StatusTemporaryRedirect || err != nil {
+				t.Fatalf("Login() got = %v, want a redirect to the provider", login.Code)
+			}
+			challenge := location.Query().Get("code_challenge")
+			if (challenge != "") != tt.wantPkce {
+				t.Fatalf("Login() code_challenge = %q, want PKCE %v", challenge, tt.wantPkce)
+			}
+
+			authSrv.EXPECT().Login(mock.Anything, mock.Anything).
+				Return(authModels.Token{SessionId: "session"}, nil).Once()
+			w := httptest.NewRecorder()
+			query := url.Values{"state": {location.Query().Get("state")}, "code": {"code"}}.Encode()
+			r := httptest.NewRequest("GET", "/login/"+name+"/callback?"+query, nil)
+			for _, cookie := range login.Result().Cookies() {
+				r.AddCookie(cookie)
+			}
+			c.Callback(w, mux.SetURLVars(r, map[string]string{"provider": name}))
+			if w.Code != http.StatusOK {
+				t.Fatalf("Callback() got = %v, want %v", w.Code, http.StatusOK)
+			}
+			if !tt.wantPkce {
+				if codeVerifier != "" {
+					t.Errorf("Callback() sent the code verifier %q to a provider without PKCE", codeVerifier)
+				}
+				return
+			}
+			if oidcModels.CodeChallenge(codeVerifier) != challenge ||
+				location.Query().Get("code_challenge_method") != "S256" {
+				t.Errorf("Callback() code verifier %q does not match the challenge %q", codeVerifier, challenge)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	"github.com/raffops/chat_auth/internal/app/user"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/raffops/chat_auth/pkg/proto"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultListUsersLimit = 20
+	maxListUsersLimit     = 100
+)
+
+// grpcController serves the 'AuthService' gRPC API, used by the other chat services to
+// validate sessions and read users.
+type grpcController struct {
+	proto.UnimplementedAuthServiceServer
+	userRepo       user.ReaderRepository
+	sessionService sessionManager.Service
+}
+
+// ValidateSession reports whether the session exists. An invalid session is not an
+// error, so callers only need to check 'valid'.
+func (c *grpcController) ValidateSession(
+	ctx context.Context,
+	req *proto.ValidateSessionRequest,
+) (*proto.ValidateSessionResponse, error) {
+	if req.GetSessionId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "session_id is required")
+	}
+	session, err := c.sessionService.GetSession(ctx, req.GetSessionId())
+	if err != nil {
+		return &proto.ValidateSessionResponse{Valid: false}, nil
+	}
+	return &proto.ValidateSessionResponse{Valid: true, Session: parseSession(req.GetSessionId(), session)}, nil
+}
+
+func (c *grpcController) GetSession(ctx context.Context, req *proto.GetSessionRequest) (*proto.Session, error) {
+	if req.GetSessionId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "session_id is required")
+	}
+	session, err := c.sessionService.GetSession(ctx, req.GetSessionId())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "session not found")
+	}
+	return parseSession(req.GetSessionId(), session), nil
+}
+
+func (c *grpcController) RevokeSession(
+	ctx context.Context,
+	req *proto.RevokeSessionRequest,
+) (*proto.RevokeSessionResponse, error) {
+	if req.GetSessionId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "session_id is required")
+	}
+	err := c.sessionService.FinishSession(ctx, req.GetSessionId())
+	if err != nil {
+		return nil, getGrpcError(err)
+	}
+	return &proto.RevokeSessionResponse{}, nil
+}
+
+func (c *grpcController) RevokeUserSessions(
+	ctx context.Context,
+	req *proto.RevokeUserSessionsRequest,
+) (*proto.RevokeUserSessionsResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
+	err := c.sessionService.FinishUserSessions(ctx, req.GetUserId())
+	if err != nil {
+		return nil, getGrpcError(err)
+	}
+	return &proto.RevokeUserSessionsResponse{}, nil
+}
+
+// GetUser fetches a user by its id or username.
+func (c *grpcController) GetUser(ctx context.Context, req *proto.GetUserRequest) (*proto.User, error) {
+	var key, value string
+	switch k := req.GetKey().(type) {
+	case *proto.GetUserRequest_Id:
+		key, value = "id", k.Id
+	case *proto.GetUserRequest_Username:
+		key, value = "username", k.Username
+	}
+	if value == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "id or username is required")
+	}
+	u, err := c.userRepo.GetUser(ctx, key, value, false)
+	if err != nil {
+		return nil, getGrpcError(err)
+	}
+	return parseUser(u), nil
+}
+
+// ListUsers lists the users sorted by creation, newest first. Role, status and auth
+// type are optional filters, given by their names, like 'admin' or 'active'.
+func (c *grpcController) ListUsers(
+	ctx context.Context,
+	req *proto.ListUsersRequest,
+) (*proto.ListUsersResponse, error) {
+	page := userModels.Pagination{Limit: int(req.GetLimit()), Offset: int(req.GetOffset())}
+	if page.Limit == 0 {
+		page.Limit = defaultListUsersLimit
+	}
+	if page.Limit < 0 || page.Limit > maxListUsersLimit || page.Offset < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxListUsersLimit)
+	}
+
+	filters := make([]userModels.Filter, 0)
+	if req.GetRole() != "" {
+		role, ok := authModels.MapRoleString[req.GetRole()]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid role %s", req.GetRole())
+		}
+		filters = append(filters, equalFilter("role", role))
+	}
+	if req.GetStatus() != "" {
+		statusId, ok := userModels.MapStatusString[req.GetStatus()]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid status %s", req.GetStatus())
+		}
+		filters = append(filters, equalFilter("status", statusId))
+	}
+	if req.GetAuthType() != "" {
+		authType, ok := userModels.MapAuthTypeString[req.GetAuthType()]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid auth type %s", req.GetAuthType())
+		}
+		filters = append(filters, equalFilter("auth_type", authType))
+	}
+
+	users, err := c.userRepo.ListUsers(
+		ctx,
+		[]string{"id", "username", "email", "auth_type", "role", "status"},
+		filters,
+		nil,
+		page,
+		false,
+	)
+	if err != nil {
+		return nil, getGrpcError(err)
+	}
+	response := &proto.ListUsersResponse{Users: make([]*proto.User, 0, len(users))}
+	for _, u := range users {
+		response.Users = append(response.Users, parseUser(u))
+	}
+	return response, nil
+}
+
+func equalFilter(key string, value any) userModels.Filter {
+	return userModels.Filter{Key: key, Value: value, Comparison: userModels.ComparisonEqual}
+}
+
+func parseSession(sessionId string, session map[string]interface{}) *proto.Session {
+	userId, _ := session["user_id"].(string)
+	role, _ := session["role"].(float64)
+	statusId, _ := session["status"].(float64)
+	authType, _ := session["auth_type"].(float64)
+	mfa, _ := session["mfa"].(bool)
+	return &proto.Session{
+		SessionId: sessionId,
+		UserId:    userId,
+		Role:      authModels.MapRole[authModels.RoleId(role)],
+		Status:    userModels.MapStatus[userModels.StatusId(statusId)],
+		AuthType:  userModels.MapAuthType[userModels.AuthTypeId(authType)],
+		Mfa:       mfa,
+	}
+}
+
+func parseUser(u userModels.User) *proto.User {
+	return &proto.User{
+		Id:       u.Id,
+		Username: u.Username,
+		Email:    u.Email,
+		Role:     authModels.MapRole[u.Role],
+		Status:   userModels.MapStatus[u.Status],
+		AuthType: userModels.MapAuthType[u.AuthType],
+	}
+}
+
+// getGrpcError is the gRPC counterpart of 'errs.GetHttpStatusCode'.
+func getGrpcError(err errs.ChatError) error {
+	switch svcErr := err.SvcError(); {
+	case errors.Is(svcErr, errs.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(svcErr, errs.ErrBadRequest):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(svcErr, errs.ErrNotAuthorized), errors.Is(svcErr, errs.ErrNotAuthenticated):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(svcErr, errs.ErrConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func NewGrpcController(userRepo user.ReaderRepository, sessionService sessionManager.Service) proto.AuthServiceServer {
+	return &grpcController{userRepo: userRepo, sessionService: sessionService}
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+// ListIdentities lists the identities linked to the caller.
+func (c *controller) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	principal, err := sessionModels.PrincipalFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	identities, err := c.authService.ListIdentities(r.Context(), principal.UserId)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	responseString, _ := json.Marshal(identities)
+	_, _ = w.Write(responseString)
+}
+
+// LinkIdentity starts linking an identity of the provider to the caller. The session of the
+// caller is kept in the cookie session, and the returned url logs in with the provider, whose
+// callback links the identity instead of logging in.
+func (c *controller) LinkIdentity(w http.ResponseWriter, r *http.Request) {
+	principal, err := sessionModels.PrincipalFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	provider := mux.Vars(r)["provider"]
+	if _, ok := c.oidcProviders[provider]; !ok {
+		if _, errProvider := goth.GetProvider(provider); errProvider != nil {
+			http.Error(w,
+				errs.NewError(errs.ErrNotFound, fmt.Errorf("provider %s not found", provider)).Error(),
+				http.StatusNotFound,
+			)
+			return
+		}
+	}
+
+	session, errSession := gothic.Store.Get(r, "session-name")
+	if errSession != nil {
+		http.Error(w,
+			errs.NewError(errs.ErrInternal, errSession).Error(),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+	session.Values["linkUserId"] = principal.UserId
+	session.Values["linkSessionId"] = principal.SessionId
+	errSession = session.Save(r, w)
+	if errSession != nil {
+		http.Error(w,
+			errs.NewError(errs.ErrInternal, errSession).Error(),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	responseString, _ := json.Marshal(map[string]string{
+		"redirect_url": "/login/" + url.PathEscape(provider) + "?link=true",
+	})
+	_, _ = w.Write(responseString)
+}
+
+// UnlinkIdentity unlinks one of the identities of the caller.
+func (c *controller) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	principal, err := sessionModels.PrincipalFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	identityId, errParse := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if errParse != nil {
+		http.Error(w,
+			errs.NewError(errs.ErrBadRequest, fmt.Errorf("invalid identity id")).Error(),
+			http.StatusBadRequest,
+		)
+		return
+	}
+	err = c.authService.UnlinkIdentity(r.Context(), principal.UserId, identityId)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// completeLink links the identity of the callback to the user that started 'LinkIdentity'. The
+// session of the user must still be active, so a link cannot outlive a logout.
+func (c *controller) completeLink(
+	w http.ResponseWriter,
+	r *http.Request,
+	session *sessions.Session,
+	identity userModels.Identity,
+	redirectUri string,
+) {
+	userId, _ := session.Values["linkUserId"].(string)
+	sessionId, _ := session.Values["linkSessionId"].(string)
+	delete(session.Values, "linkUserId")
+	delete(session.Values, "linkSessionId")
+	errSave := session.Save(r, w)
+	if errSave != nil {
+		writeCallbackError(w, r, redirectUri, errs.NewError(errs.ErrInternal, errSave))
+		return
+	}
+
+	ctx := r.Context()
+	linkSession, err := c.sessionService.GetSession(ctx, sessionId)
+	if err != nil || userId == "" || linkSession["user_id"] != userId {
+		writeCallbackError(w, r, redirectUri, errs.NewError(errs.ErrNotAuthenticated,
+			fmt.Errorf("session of the link not found"),
+		))
+		return
+	}
+	linked, err := c.authService.LinkIdentity(ctx, userId, identity)
+	if err != nil {
+		writeCallbackError(w, r, redirectUri, err)
+		return
+	}
+	writeCallback(w, r, redirectUri, http.StatusCreated, linked)
+}
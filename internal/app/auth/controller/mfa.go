@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+func (c *controller) LoginWithMfa(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		MfaChallenge string `json:"mfa_challenge"`
+		Code         string `json:"code"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil || request.MfaChallenge == "" || request.Code == "" {
+		http.Error(w,
+			errs.NewError(errs.ErrBadRequest, fmt.Errorf("mfa challenge and code are required")).Error(),
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	token, errLogin := c.authService.LoginWithMfa(withClient(r), request.MfaChallenge, request.Code)
+	if errLogin != nil {
+		http.Error(w, errLogin.Error(), errs.GetHttpStatusCode(errLogin))
+		return
+	}
+
+	responseString, _ := json.Marshal(token)
+	_, _ = w.Write(responseString)
+}
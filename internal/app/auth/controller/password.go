@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+type passwordRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (c *controller) SignUpWithPassword(w http.ResponseWriter, r *http.Request) {
+	var request passwordRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, errs.NewError(errs.ErrBadRequest, err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	token, errSignup := c.authService.SignUpWithPassword(
+		withClient(r),
+		request.Username,
+		request.Email,
+		request.Password,
+	)
+	if errSignup != nil {
+		http.Error(w, errSignup.Error(), errs.GetHttpStatusCode(errSignup))
+		return
+	}
+
+	responseString, _ := json.Marshal(token)
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(responseString)
+}
+
+func (c *controller) LoginWithPassword(w http.ResponseWriter, r *http.Request) {
+	var request passwordRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, errs.NewError(errs.ErrBadRequest, err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	token, errLogin := c.authService.LoginWithPassword(withClient(r), request.Username, request.Password)
+	if errLogin != nil {
+		http.Error(w, errLogin.Error(), errs.GetHttpStatusCode(errLogin))
+		return
+	}
+
+	responseString, _ := json.Marshal(token)
+	_, _ = w.Write(responseString)
+}
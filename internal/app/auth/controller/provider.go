@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/amazon"
+	"github.com/markbates/goth/providers/battlenet"
+	"github.com/markbates/goth/providers/bitbucket"
+	"github.com/markbates/goth/providers/bitly"
+	"github.com/markbates/goth/providers/box"
+	"github.com/markbates/goth/providers/classlink"
+	"github.com/markbates/goth/providers/dailymotion"
+	"github.com/markbates/goth/providers/deezer"
+	"github.com/markbates/goth/providers/digitalocean"
+	"github.com/markbates/goth/providers/discord"
+	"github.com/markbates/goth/providers/dropbox"
+	"github.com/markbates/goth/providers/eveonline"
+	"github.com/markbates/goth/providers/facebook"
+	"github.com/markbates/goth/providers/fitbit"
+	"github.com/markbates/goth/providers/gitea"
+	"github.com/markbates/goth/providers/github"
+	"github.com/markbates/goth/providers/gitlab"
+	"github.com/markbates/goth/providers/google"
+	"github.com/markbates/goth/providers/heroku"
+	"github.com/markbates/goth/providers/hubspot"
+	"github.com/markbates/goth/providers/influxcloud"
+	"github.com/markbates/goth/providers/instagram"
+	"github.com/markbates/goth/providers/intercom"
+	"github.com/markbates/goth/providers/kakao"
+	"github.com/markbates/goth/providers/line"
+	"github.com/markbates/goth/providers/linkedin"
+	"github.com/markbates/goth/providers/mailru"
+	"github.com/markbates/goth/providers/mastodon"
+	"github.com/markbates/goth/providers/meetup"
+	"github.com/markbates/goth/providers/nextcloud"
+	"github.com/markbates/goth/providers/onedrive"
+	"github.com/markbates/goth/providers/oura"
+	"github.com/markbates/goth/providers/patreon"
+	"github.com/markbates/goth/providers/paypal"
+	"github.com/markbates/goth/providers/salesforce"
+	"github.com/markbates/goth/providers/seatalk"
+	"github.com/markbates/goth/providers/shopify"
+	"github.com/markbates/goth/providers/slack"
+	"github.com/markbates/goth/providers/soundcloud"
+	"github.com/markbates/goth/providers/spotify"
+	"github.com/markbates/goth/providers/strava"
+	"github.com/markbates/goth/providers/stripe"
+	"github.com/markbates/goth/providers/tiktok"
+	"github.com/markbates/goth/providers/twitch"
+	"github.com/markbates/goth/providers/typetalk"
+	"github.com/markbates/goth/providers/uber"
+	"github.com/markbates/goth/providers/vk"
+	"github.com/markbates/goth/providers/wepay"
+	"github.com/markbates/goth/providers/yahoo"
+	"github.com/markbates/goth/providers/yammer"
+	"github.com/markbates/goth/providers/yandex"
+	"github.com/markbates/goth/providers/zoom"
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/oidc"
+	oidcModels "github.com/raffops/chat_auth/internal/app/oidc/model"
+	oidcService "github.com/raffops/chat_auth/internal/app/oidc/service"
+	"github.com/raffops/chat_auth/internal/app/user"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+// oidcTimeout bounds the requests to the OpenID Connect providers.
+const oidcTimeout = 10 * time.Second
+
+type providerFactory func(clientKey, secret, callbackURL string, scopes ...string) goth.Provider
+
+func newProviderFactory[P goth.Provider](newProvider func(string, string, string, ...string) P) providerFactory {
+	return func(clientKey, secret, callbackURL string, scopes ...string) goth.Provider {
+		return newProvider(clientKey, secret, callbackURL, scopes...)
+	}
+}
+
+// providerFactories are the goth providers created from a client id, secret and scopes. The
+// providers configured otherwise can usually be reached with OpenID Connect discovery instead.
+var providerFactories = map[string]providerFactory{
+	"amazon":       newProviderFactory(amazon.New),
+	"battlenet":    newProviderFactory(battlenet.New),
+	"bitbucket":    newProviderFactory(bitbucket.New),
+	"bitly":        newProviderFactory(bitly.New),
+	"box":          newProviderFactory(box.New),
+	"classlink":    newProviderFactory(classlink.New),
+	"dailymotion":  newProviderFactory(dailymotion.New),
+	"deezer":       newProviderFactory(deezer.New),
+	"digitalocean": newProviderFactory(digitalocean.New),
+	"discord":      newProviderFactory(discord.New),
+	"dropbox":      newProviderFactory(dropbox.New),
+	"eveonline":    newProviderFactory(eveonline.New),
+	"facebook":     newProviderFactory(facebook.New),
+	"fitbit":       newProviderFactory(fitbit.New),
+	"gitea":        newProviderFactory(gitea.New),
+	"github":       newProviderFactory(github.New),
+	"gitlab":       newProviderFactory(gitlab.New),
+	"google":       newProviderFactory(google.New),
+	"heroku":       newProviderFactory(heroku.New),
+	"hubspot":      newProviderFactory(hubspot.New),
+	"influxcloud":  newProviderFactory(influxcloud.New),
+	"instagram":    newProviderFactory(instagram.New),
+	"intercom":     newProviderFactory(intercom.New),
+	"kakao":        newProviderFactory(kakao.New),
+	"line":         newProviderFactory(line.New),
+	"linkedin":     newProviderFactory(linkedin.New),
+	"mailru":       newProviderFactory(mailru.New),
+	"mastodon":     newProviderFactory(mastodon.New),
+	"meetup":       newProviderFactory(meetup.New),
+	"nextcloud":    newProviderFactory(nextcloud.New),
+	"onedrive":     newProviderFactory(onedrive.New),
+	"oura":         newProviderFactory(oura.New),
+	"patreon":      newProviderFactory(patreon.New),
+	"paypal":       newProviderFactory(paypal.New),
+	"salesforce":   newProviderFactory(salesforce.New),
+	"seatalk":      newProviderFactory(seatalk.New),
+	"shopify":      newProviderFactory(shopify.New),
+	"slack":        newProviderFactory(slack.New),
+	"soundcloud":   newProviderFactory(soundcloud.New),
+	"spotify":      newProviderFactory(spotify.New),
+	"strava":       newProviderFactory(strava.New),
+	"stripe":       newProviderFactory(stripe.New),
+	"tiktok":       newProviderFactory(tiktok.New),
+	"twitch":       newProviderFactory(twitch.New),
+	"typetalk":     newProviderFactory(typetalk.New),
+	"uber":         newProviderFactory(uber.New),
+	"vk":           newProviderFactory(vk.New),
+	"wepay":        newProviderFactory(wepay.New),
+	"yahoo":        newProviderFactory(yahoo.New),
+	"yammer":       newProviderFactory(yammer.New),
+	"yandex":       newProviderFactory(yandex.New),
+	"zoom":         newProviderFactory(zoom.New),
+}
+
+// pkceProviderTypes are the goth providers whose sessions send the 'code_verifier' of the callback
+// when exchanging the code, so their logins use PKCE. goth exchanges the code of the other types
+// without it, and a provider enforcing PKCE would then reject the exchange of a login sent with a
+// challenge, so they are left without. Providers that support OpenID Connect get PKCE with the
+// 'openidConnect' type instead.
+var pkceProviderTypes = map[string]bool{
+	"fitbit": true,
+	"zoom":   true,
+}
+
+// NewProvider creates the goth provider of a configuration, named after it so that
+// '/login/<name>' reaches it.
+func NewProvider(config authModels.ProviderConfig, callbackBaseUrl string) (goth.Provider, errs.ChatError) {
+	clientKey, secret, err := getCredentials(config)
+	if err != nil {
+		return nil, err
+	}
+	newProvider, ok := providerFactories[config.Type]
+	if !ok {
+		return nil, errs.NewError(errs.ErrBadRequest,
+			fmt.Errorf("provider type %s not supported", config.Type),
+		)
+	}
+	provider := newProvider(clientKey, secret, config.CallbackUrl(callbackBaseUrl), config.Scopes...)
+	provider.SetName(config.Name)
+	return provider, nil
+}
+
+// NewOidcProvider creates the OpenID Connect client of a configuration. It fetches the
+// discovery document of the provider, to find its endpoints and keys.
+func NewOidcProvider(
+	ctx context.Context,
+	config authModels.ProviderConfig,
+	callbackBaseUrl string,
+) (oidc.Provider, errs.ChatError) {
+	clientKey, secret, err := getCredentials(config)
+	if err != nil {
+		return nil, err
+	}
+	return oidcService.NewProvider(ctx, config.Name, oidcModels.Config{
+		DiscoveryUrl: config.DiscoveryUrl,
+		ClientId:     clientKey,
+		ClientSecret: secret,
+		RedirectUrl:  config.CallbackUrl(callbackBaseUrl),
+		Scopes:       config.Scopes,
+		AdminGroups:  config.AdminGroups,
+	}, &http.Client{Timeout: oidcTimeout})
+}
+
+func getCredentials(config authModels.ProviderConfig) (string, string, errs.ChatError) {
+	clientKey, ok := os.LookupEnv(config.ClientIdEnv)
+	if !ok {
+		return "", "", errs.NewError(errs.ErrBadRequest,
+			fmt.Errorf("environment variable %s not set", config.ClientIdEnv),
+		)
+	}
+	secret, ok := os.LookupEnv(config.ClientSecretEnv)
+	if !ok {
+		return "", "", errs.NewError(errs.ErrBadRequest,
+			fmt.Errorf("environment variable %s not set", config.ClientSecretEnv),
+		)
+	}
+	return clientKey, secret, nil
+}
+
+// UseProviders registers the configured goth providers in goth, and the auth types of all the
+// providers in the 'auth_type' table. The auth types of the table are then loaded in
+// 'userModels.MapAuthType', so it must run before serving. The OpenID Connect providers are
+// returned by name, for the controller.
+func UseProviders(
+	ctx context.Context,
+	config authModels.ProvidersConfig,
+	authTypeRepo user.AuthTypeRepository,
+) (map[string]oidc.Provider, errs.ChatError) {
+	providers := make([]goth.Provider, 0, len(config.Providers))
+	oidcProviders := make(map[string]oidc.Provider)
+	names := make([]string, 0, len(config.Providers))
+	for _, providerConfig := range config.Providers {
+		names = append(names, providerConfig.Name)
+		if providerConfig.Type == authModels.ProviderTypeOpenIdConnect {
+			provider, err := NewOidcProvider(ctx, providerConfig, config.CallbackBaseUrl)
+			if err != nil {
+				return nil, err
+			}
+			oidcProviders[providerConfig.Name] = provider
+			continue
+		}
+		provider, err := NewProvider(providerConfig, config.CallbackBaseUrl)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	if err := authTypeRepo.CreateAuthTypes(ctx, names); err != nil {
+		return nil, err
+	}
+	authTypes, err := authTypeRepo.ListAuthTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for name, id := range authTypes {
+		userModels.RegisterAuthType(id, name)
+	}
+
+	goth.ClearProviders()
+	goth.UseProviders(providers...)
+	return oidcProviders, nil
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"net/http"
+	"time"
 
 	auth "github.com/raffops/chat_auth/internal/app/auth/model"
 	user "github.com/raffops/chat_auth/internal/app/user/models"
@@ -11,22 +12,30 @@ import (
 
 type Controller interface {
 	SignUp(w http.ResponseWriter, r *http.Request)
+	SignUpWithPassword(w http.ResponseWriter, r *http.Request)
 	Login(w http.ResponseWriter, r *http.Request)
+	LoginWithPassword(w http.ResponseWriter, r *http.Request)
+	LoginWithMfa(w http.ResponseWriter, r *http.Request)
 	Callback(w http.ResponseWriter, r *http.Request)
 	Refresh(w http.ResponseWriter, r *http.Request)
 	Logout(w http.ResponseWriter, r *http.Request)
 	DeleteUser(w http.ResponseWriter, r *http.Request)
+	ListIdentities(w http.ResponseWriter, r *http.Request)
+	LinkIdentity(w http.ResponseWriter, r *http.Request)
+	UnlinkIdentity(w http.ResponseWriter, r *http.Request)
 }
 
 type Service interface {
-	SignUp(
-		ctx context.Context,
-		username, email string,
-		authType user.AuthTypeId,
-		role auth.RoleId,
-	) (string, errs.ChatError)
-	Login(ctx context.Context, username, email string) (string, errs.ChatError)
-	Refresh(ctx context.Context, sessionId string) errs.ChatError
+	SignUp(ctx context.Context, username string, identity user.Identity, role auth.RoleId) (auth.Token, errs.ChatError)
+	SignUpWithPassword(ctx context.Context, username, email, password string) (auth.Token, errs.ChatError)
+	Login(ctx context.Context, identity user.Identity) (auth.Token, errs.ChatError)
+	LoginWithPassword(ctx context.Context, username, password string) (auth.Token, errs.ChatError)
+	LoginWithMfa(ctx context.Context, challenge, code string) (auth.Token, errs.ChatError)
+	Refresh(ctx context.Context, refreshToken string) (auth.Token, errs.ChatError)
 	Logout(ctx context.Context, sessionId string) errs.ChatError
 	DeleteUser(ctx context.Context, userToDelete user.User) errs.ChatError
+	StartClosingLogins(ctx context.Context, interval time.Duration)
+	LinkIdentity(ctx context.Context, userId string, identity user.Identity) (user.Identity, errs.ChatError)
+	UnlinkIdentity(ctx context.Context, userId string, identityId int64) errs.ChatError
+	ListIdentities(ctx context.Context, userId string) ([]user.Identity, errs.ChatError)
 }
@@ -0,0 +1,22 @@
+package auth
+
+import "context"
+
+// Client is the device a user logs in from, recorded in the login history.
+type Client struct {
+	Ip        string
+	UserAgent string
+}
+
+type clientKey struct{}
+
+func ContextWithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFromContext returns the client injected by the auth controller, or an empty
+// client for logins that did not go through it.
+func ClientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+// ProviderTypeOpenIdConnect configures a generic OpenID Connect provider from its discovery document,
+// with the 'oidc' client instead of goth.
+const ProviderTypeOpenIdConnect = "openidConnect"
+
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// ProviderConfig is an OAuth provider users log in with, on '/login/<Name>'. Type is the goth
+// provider, like 'google', and defaults to the name. The client id and secret are read from the
+// environment variables named by ClientIdEnv and ClientSecretEnv. AdminGroups are the groups of
+// an OpenID Connect provider whose users sign up as admins.
+type ProviderConfig struct {
+	Name            string   `json:"name"`
+	Type            string   `json:"type,omitempty"`
+	ClientIdEnv     string   `json:"client_id_env"`
+	ClientSecretEnv string   `json:"client_secret_env"`
+	Scopes          []string `json:"scopes,omitempty"`
+	DiscoveryUrl    string   `json:"discovery_url,omitempty"`
+	AdminGroups     []string `json:"admin_groups,omitempty"`
+}
+
+// CallbackUrl is where the provider redirects the users back to, under callbackBaseUrl.
+func (p ProviderConfig) CallbackUrl(callbackBaseUrl string) string {
+	return strings.TrimSuffix(callbackBaseUrl, "/") + "/login/" + p.Name + "/callback"
+}
+
+// ProvidersConfig configures the OAuth providers. RedirectUris is the allowlist of the urls a
+// login may send the user back to once authenticated, like the pages of SPAs, with the response
+// of the callback in the fragment.
+type ProvidersConfig struct {
+	CallbackBaseUrl string           `json:"callback_base_url"`
+	Providers       []ProviderConfig `json:"providers"`
+	RedirectUris    []string         `json:"redirect_uris,omitempty"`
+}
+
+// AllowsRedirectUri reports whether the redirect uri is in the allowlist. URIs are compared
+// exactly, as recommended by RFC 9700.
+func (c ProvidersConfig) AllowsRedirectUri(redirectUri string) bool {
+	return slices.Contains(c.RedirectUris, redirectUri)
+}
+
+// Provider returns the configuration of the provider named name.
+func (c ProvidersConfig) Provider(name string) (ProviderConfig, bool) {
+	for _, provider := range c.Providers {
+		if provider.Name == name {
+			return provider, true
+		}
+	}
+	return ProviderConfig{}, false
+}
+
+// ParseProviders parses the JSON configuration of the OAuth providers. The names become auth
+// types, so they must be unique and cannot be 'password'.
+func ParseProviders(data []byte) (ProvidersConfig, errs.ChatError) {
+	var config ProvidersConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return ProvidersConfig{}, errs.NewError(errs.ErrBadRequest, err)
+	}
+	if config.CallbackBaseUrl == "" {
+		return ProvidersConfig{}, errs.NewError(errs.ErrBadRequest, fmt.Errorf("callback base url not set"))
+	}
+	names := make(map[string]bool, len(config.Providers))
+	for i, provider := range config.Providers {
+		if !providerNamePattern.MatchString(provider.Name) || provider.Name == "password" {
+			return ProvidersConfig{}, errs.NewError(errs.ErrBadRequest,
+				fmt.Errorf("invalid provider name %q", provider.Name),
+			)
+		}
+		if names[provider.Name] {
+			return ProvidersConfig{}, errs.NewError(errs.ErrBadRequest,
+				fmt.Errorf("provider %s configured twice", provider.Name),
+			)
+		}
+		names[provider.Name] = true
+		if provider.ClientIdEnv == "" || provider.ClientSecretEnv == "" {
+			return ProvidersConfig{}, errs.NewError(errs.ErrBadRequest,
+				fmt.Errorf("client id or secret of provider %s not set", provider.Name),
+			)
+		}
+		if provider.Type == "" {
+			config.Providers[i].Type = provider.Name
+		}
+		if config.Providers[i].Type == ProviderTypeOpenIdConnect && provider.DiscoveryUrl == "" {
+			return ProvidersConfig{}, errs.NewError(errs.ErrBadRequest,
+				fmt.Errorf("discovery url of provider %s not set", provider.Name),
+			)
+		}
+	}
+	for _, redirectUri := range config.RedirectUris {
+		if err := validateRedirectUri(redirectUri); err != nil {
+			return ProvidersConfig{}, err
+		}
+	}
+	return config, nil
+}
+
+// validateRedirectUri accepts absolute http and https urls without fragment, which would hide the
+// response of the callback.
+func validateRedirectUri(redirectUri string) errs.ChatError {
+	parsed, err := url.Parse(redirectUri)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" ||
+		parsed.Fragment != "" || strings.Contains(redirectUri, "#") {
+		return errs.NewError(errs.ErrBadRequest, fmt.Errorf("invalid redirect uri %q", redirectUri))
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseProviders(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    ProvidersConfig
+		wantErr bool
+	}{
+		{
+			name: "Test providers",
+			config: `{"callback_base_url": "https://auth.example.com", "providers": [
+				{"name": "google", "client_id_env": "GOOGLE_KEY", "client_secret_env": "GOOGLE_SECRET"},
+				{"name": "keycloak", "type": "openidConnect", "client_id_env": "KC_KEY",
+					"client_secret_env": "KC_SECRET", "scopes": ["email"],
+					"discovery_url": "https://kc.example.com/.well-known/openid-configuration"}
+			]}`,
+			want: ProvidersConfig{
+				CallbackBaseUrl: "https://auth.example.com",
+				Providers: []ProviderConfig{
+					{Name: "google", Type: "google", ClientIdEnv: "GOOGLE_KEY", ClientSecretEnv: "GOOGLE_SECRET"},
+					{
+						Name:            "keycloak",
+						Type:            ProviderTypeOpenIdConnect,
+						ClientIdEnv:     "KC_KEY",
+						ClientSecretEnv: "KC_SECRET",
+						Scopes:          []string{"email"},
+						DiscoveryUrl:    "https://kc.example.com/.well-known/openid-configuration",
+					},
+				},
+			},
+		},
+		{name: "Test invalid json", config: `{`, wantErr: true},
+		{name: "Test missing callback base url", config: `{"providers": []}`, wantErr: true},
+		{
+			name: "Test password name",
+			config: `{"callback_base_url": "http://localhost:8080", "providers": [
+				{"name": "password", "client_id_env": "KEY", "client_secret_env": "SECRET"}]}`,
+			wantErr: true,
+		},
+		{
+			name: "Test invalid name",
+			config: `{"callback_base_url": "http://localhost:8080", "providers": [
+				{"name": "Google/1", "client_id_env": "KEY", "client_secret_env": "SECRET"}]}`,
+			wantErr: true,
+		},
+		{
+			name: "Test duplicated name",
+			config: `{"callback_base_url": "http://localhost:8080", "providers": [
+				{"name": "google", "client_id_env": "KEY", "client_secret_env": "SECRET"},
+				{"name": "google", "client_id_env": "KEY", "client_secret_env": "SECRET"}]}`,
+			wantErr: true,
+		},
+		{
+			name: "Test missing client secret",
+			config: `{"callback_base_url": "http://localhost:8080", "providers": [
+				{"name": "google", "client_id_env": "KEY"}]}`,
+			wantErr: true,
+		},
+		{
+			name: "Test redirect uris",
+			config: `{"callback_base_url": "http://localhost:8080", "providers": [],
+				"redirect_uris": ["https://chat.example.com/auth", "http://localhost:3000/"]}`,
+			want: ProvidersConfig{
+				CallbackBaseUrl: "http://localhost:8080",
+				Providers:       []ProviderConfig{},
+				RedirectUris:    []string{"https://chat.example.com/auth", "http://localhost:3000/"},
+			},
+		},
+		{
+			name: "Test relative redirect uri",
+			config: `{"callback_base_url": "http://localhost:8080", "providers": [],
+				"redirect_uris": ["/auth"]}`,
+			wantErr: true,
+		},
+		{
+			name: "Test redirect uri with fragment",
+			config: `{"callback_base_url": "http://localhost:8080", "providers": [],
+				"redirect_uris": ["https://chat.example.com/#/auth"]}`,
+			wantErr: true,
+		},
+		{
+			name: "Test redirect uri with other scheme",
+			config: `{"callback_base_url": "http://localhost:8080", "providers": [],
+				"redirect_uris": ["javascript://chat.example.com/%0aalert(1)"]}`,
+			wantErr: true,
+		},
+		{
+			name: "Test openid connect without discovery url",
+			config: `{"callback_base_url": "http://localhost:8080", "providers": [
+				{"name": "sso", "type": "openidConnect", "client_id_env": "KEY", "client_secret_env": "SECRET"}]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProviders([]byte(tt.config))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseProviders() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseProviders() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderConfig_CallbackUrl(t *testing.T) {
+	provider := ProviderConfig{Name: "github"}
+	got := provider.CallbackUrl("https://auth.example.com/")
+	if want := "https://auth.example.com/login/github/callback"; got != want {
+		t.Errorf("CallbackUrl() got = %v, want %v", got, want)
+	}
+}
+
+func TestProvidersConfig_AllowsRedirectUri(t *testing.T) {
+	config := ProvidersConfig{RedirectUris: []string{"https://chat.example.com/auth"}}
+	tests := []struct {
+		redirectUri string
+		want        bool
+	}{
+		{redirectUri: "https://chat.example.com/auth", want: true},
+		{redirectUri: "https://chat.example.com/auth/"},
+		{redirectUri: "https://chat.example.com/auth?next=/admin"},
+		{redirectUri: "https://chat.example.com.evil.com/auth"},
+		{redirectUri: ""},
+	}
+	for _, tt := range tests {
+		if got := config.AllowsRedirectUri(tt.redirectUri); got != tt.want {
+			t.Errorf("AllowsRedirectUri(%q) got = %v, want %v", tt.redirectUri, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package auth
+
+// SignUpRequest completes the sign up of an identity without a user, on 'POST /signUp',
+// with the username chosen by the user.
+type SignUpRequest struct {
+	Username string `json:"username"`
+}
+
+// PendingSignUp is returned by the callback of an identity without a user. The identity is
+// kept in the cookie session until the sign up is completed with a SignUpRequest.
+//
+// SuggestedUsername is the username of the user at the provider, which may be taken.
+type PendingSignUp struct {
+	SignUpRequired    bool   `json:"sign_up_required"`
+	Provider          string `json:"provider"`
+	Email             string `json:"email"`
+	SuggestedUsername string `json:"suggested_username,omitempty"`
+}
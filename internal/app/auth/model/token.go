@@ -0,0 +1,24 @@
+package auth
+
+// Token is the response returned to the client after a successful authentication.
+//
+// SessionId is the opaque id of the session stored by the session manager, and
+// AccessToken is a short-lived signed JWT that carries the same information.
+// RefreshToken is single use and is exchanged on '/refresh' for a new Token.
+//
+// When the user has multi-factor authentication enabled, the first step of the login
+// only returns MfaRequired and MfaChallenge, which is exchanged on '/login/mfa' along
+// with a TOTP or recovery code for the session.
+//
+// EvictedSessions lists the handles of the sessions of the user revoked to respect the
+// session limit of their role, the ones the sessions are listed with.
+type Token struct {
+	SessionId       string   `json:"token,omitempty"`
+	AccessToken     string   `json:"access_token,omitempty"`
+	RefreshToken    string   `json:"refresh_token,omitempty"`
+	TokenType       string   `json:"token_type,omitempty"`
+	ExpiresIn       int64    `json:"expires_in,omitempty"`
+	MfaRequired     bool     `json:"mfa_required,omitempty"`
+	MfaChallenge    string   `json:"mfa_challenge,omitempty"`
+	EvictedSessions []string `json:"evicted_sessions,omitempty"`
+}
@@ -2,102 +2,454 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/raffops/chat_auth/internal/app/auth"
 	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/mfa"
 	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	"github.com/raffops/chat_auth/internal/app/token"
+	tokenModels "github.com/raffops/chat_auth/internal/app/token/model"
 	"github.com/raffops/chat_auth/internal/app/user"
 	userModels "github.com/raffops/chat_auth/internal/app/user/models"
 	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"github.com/raffops/chat_commons/pkg/passwordHasher"
+	"go.uber.org/zap"
+)
+
+// loginsBatchSize is the number of open logins checked at a time for expired sessions.
+const loginsBatchSize = 100
+
+var (
+	errUserDeleted   = errors.New("user is deleted")
+	errUserNotActive = errors.New("user is not active")
 )
 
 type defaultService struct {
-	userRepo    user.ReaderWriterRepository
-	sessionRepo sessionManager.ReaderRepository
-	sessionSrv  sessionManager.Service
+	userRepo     user.ReaderWriterRepository
+	loginRepo    user.LoginRepository
+	identityRepo user.IdentityRepository
+	sessionRepo  sessionManager.ReaderRepository
+	sessionSrv   sessionManager.Service
+	tokenSrv     token.Service
+	mfaSrv       mfa.Service
+	hasher       passwordHasher.PasswordHasher
 }
 
+// DeleteUser soft deletes the user and finishes its sessions. The user is only committed
+// as deleted if the sessions could be finished.
 func (s defaultService) DeleteUser(ctx context.Context, userToDelete userModels.User) errs.ChatError {
-	err := s.sessionSrv.FinishUserSessions(ctx, userToDelete.Id)
+	tx, errTx := s.userRepo.GetDB().BeginTx(ctx, nil)
+	if errTx != nil {
+		return errs.NewError(errs.ErrInternal, errTx)
+	}
+	defer tx.Rollback()
+
+	_, err := s.userRepo.DeleteUser(ctx, tx, userToDelete)
+	if err != nil {
+		return err
+	}
+	err = s.sessionSrv.FinishUserSessions(ctx, userToDelete.Id)
 	if err != nil {
 		return err
 	}
 
+	errCommit := tx.Commit()
+	if errCommit != nil {
+		return errs.NewError(errs.ErrInternal, errCommit)
+	}
 	return nil
 }
 
+// Logout finishes the session and closes its login in the history of the user.
 func (s defaultService) Logout(ctx context.Context, sessionId string) errs.ChatError {
-	return s.sessionSrv.FinishSession(ctx, sessionId)
+	err := s.sessionSrv.FinishSession(ctx, sessionId)
+	if err != nil {
+		return err
+	}
+	err = s.loginRepo.CloseLogins(ctx, sessionHandles([]string{sessionId}), time.Now())
+	if err != nil {
+		logger.Error("cannot close login",
+			zap.String("session", sessionModels.SessionHandle(sessionId)),
+			zap.Error(err),
+		)
+	}
+	return nil
 }
 
+// SignUp creates a user with the username chosen by the user and the auth type of the
+// identity's provider, and links the identity to it. A username already taken is a conflict.
 func (s defaultService) SignUp(
 	ctx context.Context,
-	username, email string,
-	authType userModels.AuthTypeId,
+	username string,
+	identity userModels.Identity,
 	role authModels.RoleId,
-) (string, errs.ChatError) {
+) (authModels.Token, errs.ChatError) {
+	if err := validateUsername(username); err != nil {
+		return authModels.Token{}, err
+	}
+	authType, ok := userModels.MapAuthTypeString[identity.Provider]
+	if !ok {
+		return authModels.Token{}, errs.NewError(errs.ErrBadRequest,
+			fmt.Errorf("auth type %s not found", identity.Provider),
+		)
+	}
 	u := userModels.User{
 		Username: username,
-		Email:    email,
+		Email:    identity.Email,
 		AuthType: authType,
 		Role:     role,
 		Status:   userModels.StatusActive,
 	}
-	tx, _ := s.userRepo.GetDB().BeginTx(ctx, nil)
+	return s.signUp(ctx, u, identity)
+}
+
+// signUp creates the user, links its identities and starts its first session. The user is
+// only committed if the session could be created.
+func (s defaultService) signUp(
+	ctx context.Context,
+	u userModels.User,
+	identities ...userModels.Identity,
+) (authModels.Token, errs.ChatError) {
+	tx, errTx := s.userRepo.GetDB().BeginTx(ctx, nil)
+	if errTx != nil {
+		return authModels.Token{}, errs.NewError(errs.ErrInternal, errTx)
+	}
 	defer tx.Rollback()
 
 	createUser, err := s.userRepo.CreateUser(ctx, tx, u)
 	if err != nil {
-		return "", err
+		return authModels.Token{}, err
+	}
+	for _, identity := range identities {
+		identity.UserId = createUser.Id
+		_, err = s.identityRepo.CreateIdentity(ctx, tx, identity)
+		if err != nil {
+			return authModels.Token{}, err
+		}
 	}
 
-	sessionId, err := s.sessionSrv.CreateSession(
-		ctx,
-		createUser.Id,
-		map[string]interface{}{
-			"role":      createUser.Role,
-			"status":    createUser.Status,
-			"auth_type": createUser.AuthType},
-	)
+	token, err := s.startSession(ctx, createUser, false)
 	if err != nil {
-		return "", errs.NewError(errs.ErrInternal, err)
+		return authModels.Token{}, err
 	}
 	errCommit := tx.Commit()
 	if errCommit != nil {
-		return "", errs.NewError(errs.ErrInternal, errCommit)
+		return authModels.Token{}, errs.NewError(errs.ErrInternal, errCommit)
+	}
+
+	s.recordLogin(ctx, createUser, token.SessionId)
+	return token, nil
+}
+
+// Login starts the session of the user linked to the identity. Users who signed up before
+// identities were linked have none, so they are found by the email of their auth type, and
+// the identity is linked to them on their first login.
+func (s defaultService) Login(ctx context.Context, identity userModels.Identity) (authModels.Token, errs.ChatError) {
+	linked, err := s.identityRepo.GetIdentity(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		u, err := s.userRepo.GetUser(ctx, "id", linked.UserId, true)
+		if err != nil {
+			return authModels.Token{}, err
+		}
+		return s.login(ctx, u)
+	}
+	if !errors.Is(err.SvcError(), errs.ErrNotFound) || identity.Email == "" {
+		return authModels.Token{}, err
+	}
+
+	u, errUser := s.userRepo.GetUser(ctx, "email", identity.Email, true)
+	if errUser != nil {
+		return authModels.Token{}, errUser
+	}
+	identities, errList := s.identityRepo.ListIdentities(ctx, u.Id)
+	if errList != nil {
+		return authModels.Token{}, errList
+	}
+	if len(identities) > 0 || userModels.MapAuthType[u.AuthType] != identity.Provider {
+		return authModels.Token{}, errs.NewError(errs.ErrConflict,
+			fmt.Errorf("email %s is used by another user, log in to link the %s identity",
+				identity.Email, identity.Provider,
+			),
+		)
+	}
+	if !u.DeletedAt.IsZero() {
+		return authModels.Token{}, errs.NewError(errs.ErrNotAuthorized, errUserDeleted)
+	}
+	identity.UserId = u.Id
+	_, err = s.identityRepo.CreateIdentity(ctx, nil, identity)
+	if err != nil {
+		return authModels.Token{}, err
+	}
+	return s.login(ctx, u)
+}
+
+// login starts the session of a user authenticated by its first factor. If the user has
+// multi-factor authentication enabled, only a challenge is returned, to be completed
+// on 'LoginWithMfa'. Deleted and inactive users cannot log in, whatever their first factor.
+func (s defaultService) login(ctx context.Context, u userModels.User) (authModels.Token, errs.ChatError) {
+	if !u.DeletedAt.IsZero() {
+		return authModels.Token{}, errs.NewError(errs.ErrNotAuthorized, errUserDeleted)
+	}
+	if u.Status != userModels.StatusActive {
+		return authModels.Token{}, errs.NewError(errs.ErrNotAuthorized, errUserNotActive)
+	}
+	mfaEnabled, err := s.mfaSrv.IsEnabled(ctx, u.Id)
+	if err != nil {
+		return authModels.Token{}, err
+	}
+	if !mfaEnabled {
+		token, err := s.startSession(ctx, u, false)
+		if err != nil {
+			return authModels.Token{}, err
+		}
+		s.recordLogin(ctx, u, token.SessionId)
+		return token, nil
 	}
 
-	return sessionId, nil
+	challenge, err := s.mfaSrv.CreateChallenge(ctx, u.Id)
+	if err != nil {
+		return authModels.Token{}, err
+	}
+	return authModels.Token{MfaRequired: true, MfaChallenge: challenge}, nil
+}
+
+// LoginWithMfa completes a login challenge with a TOTP or recovery code. The session is
+// marked with the 'mfa' claim.
+func (s defaultService) LoginWithMfa(ctx context.Context, challenge, code string) (authModels.Token, errs.ChatError) {
+	userId, err := s.mfaSrv.VerifyChallenge(ctx, challenge, code)
+	if err != nil {
+		return authModels.Token{}, err
+	}
+	u, err := s.userRepo.GetUser(ctx, "id", userId, false)
+	if err != nil {
+		return authModels.Token{}, err
+	}
+	if u.Status != userModels.StatusActive {
+		return authModels.Token{}, errs.NewError(errs.ErrNotAuthorized, errUserNotActive)
+	}
+
+	token, err := s.startSession(ctx, u, true)
+	if err != nil {
+		return authModels.Token{}, err
+	}
+	s.recordLogin(ctx, u, token.SessionId)
+	return token, nil
 }
 
-func (s defaultService) Login(ctx context.Context, username, email string) (string, errs.ChatError) {
-	u, err := s.userRepo.GetUser(ctx, "username", username)
+// recordLogin appends the login to the history of the user, with the client injected in
+// the context. The session is recorded by handle, since the history is listed to admins.
+// The history is not required to log in, so failures are only logged.
+func (s defaultService) recordLogin(ctx context.Context, u userModels.User, sessionId string) {
+	client := authModels.ClientFromContext(ctx)
+	_, err := s.loginRepo.RecordLogin(ctx, userModels.Login{
+		UserId:        u.Id,
+		SessionHandle: sessionModels.SessionHandle(sessionId),
+		Provider:      userModels.MapAuthType[u.AuthType],
+		Ip:            client.Ip,
+		UserAgent:     client.UserAgent,
+		LoginTime:     time.Now(),
+	})
 	if err != nil {
-		return "", err
+		logger.Error("cannot record login", zap.String("user_id", u.Id), zap.Error(err))
 	}
-	if u.Email != email {
-		return "", errs.NewError(errs.ErrNotAuthorized, nil)
+}
+
+// closeExpiredLogins closes the open logins whose sessions are gone, because they
+// expired or were revoked, and returns how many were closed. The logins only keep the
+// handle of their session, so they are matched against the sessions indexed for the user.
+// The logout time is the time of the check, since the session is no longer there to tell
+// when it ended.
+func (s defaultService) closeExpiredLogins(ctx context.Context) (int, errs.ChatError) {
+	closed := 0
+	var afterId int64
+	for {
+		logins, err := s.loginRepo.ListOpenLogins(ctx, afterId, loginsBatchSize)
+		if err != nil {
+			return closed, err
+		}
+		now := time.Now()
+		expired := make([]string, 0)
+		active := make(map[string]map[string]bool)
+		for _, login := range logins {
+			handles, ok := active[login.UserId]
+			if !ok {
+				userSessions, err := s.sessionRepo.SetMembers(ctx, "user_sessions", login.UserId, now)
+				if err != nil {
+					return closed, err
+				}
+				handles = make(map[string]bool, len(userSessions))
+				for sessionId := range userSessions {
+					handles[sessionModels.SessionHandle(sessionId)] = true
+				}
+				active[login.UserId] = handles
+			}
+			if !handles[login.SessionHandle] {
+				expired = append(expired, login.SessionHandle)
+			}
+			afterId = login.Id
+		}
+		err = s.loginRepo.CloseLogins(ctx, expired, now)
+		if err != nil {
+			return closed, err
+		}
+		closed += len(expired)
+		if len(logins) < loginsBatchSize {
+			return closed, nil
+		}
 	}
+}
 
-	return s.sessionSrv.CreateSession(
+// StartClosingLogins closes the logins of the expired sessions at every interval until
+// the context is done.
+func (s defaultService) StartClosingLogins(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.closeExpiredLogins(ctx); err != nil {
+					logger.Error("cannot close expired logins", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// startSession creates a session for an authenticated user and issues its tokens. The
+// logins of the sessions evicted by the session limit are closed.
+func (s defaultService) startSession(
+	ctx context.Context,
+	u userModels.User,
+	mfaVerified bool,
+) (authModels.Token, errs.ChatError) {
+	sessionId, evicted, err := s.sessionSrv.CreateSession(
 		ctx,
 		u.Id,
-		map[string]interface{}{"role": u.Role, "status": u.Status, "auth_type": u.AuthType},
+		map[string]interface{}{
+			"role":      u.Role,
+			"status":    u.Status,
+			"auth_type": u.AuthType,
+			"provider":  userModels.MapAuthType[u.AuthType],
+			"mfa":       mfaVerified,
+		},
 	)
+	if err != nil {
+		return authModels.Token{}, err
+	}
+	evictedHandles := sessionHandles(evicted)
+	if len(evicted) > 0 {
+		errClose := s.loginRepo.CloseLogins(ctx, evictedHandles, time.Now())
+		if errClose != nil {
+			logger.Error("cannot close evicted logins", zap.String("user_id", u.Id), zap.Error(errClose))
+		}
+	}
+
+	token, err := s.issueTokens(ctx, u, sessionId, mfaVerified)
+	if err != nil {
+		return authModels.Token{}, err
+	}
+	token.EvictedSessions = evictedHandles
+	return token, nil
+}
+
+// sessionHandles returns the handles the logins of the sessions are recorded with.
+func sessionHandles(sessionIds []string) []string {
+	handles := make([]string, 0, len(sessionIds))
+	for _, sessionId := range sessionIds {
+		handles = append(handles, sessionModels.SessionHandle(sessionId))
+	}
+	return handles
+}
+
+// issueTokens starts the refresh token family of a new session and signs its access token.
+func (s defaultService) issueTokens(
+	ctx context.Context,
+	u userModels.User,
+	sessionId string,
+	mfaVerified bool,
+) (authModels.Token, errs.ChatError) {
+	refreshToken, err := s.sessionSrv.CreateRefreshToken(ctx, u.Id, sessionId)
+	if err != nil {
+		return authModels.Token{}, err
+	}
+	return s.issueToken(ctx, u, sessionId, refreshToken, mfaVerified)
 }
 
-func (s defaultService) Refresh(ctx context.Context, sessionId string) errs.ChatError {
-	return s.sessionSrv.RefreshSession(ctx, sessionId)
+// issueToken signs an access token for the user bound to the given session.
+func (s defaultService) issueToken(
+	ctx context.Context,
+	u userModels.User,
+	sessionId, refreshToken string,
+	mfaVerified bool,
+) (authModels.Token, errs.ChatError) {
+	accessToken, expiresAt, err := s.tokenSrv.Issue(ctx, tokenModels.Claims{
+		UserId:    u.Id,
+		Role:      u.Role,
+		Status:    u.Status,
+		AuthType:  u.AuthType,
+		SessionId: sessionId,
+		Mfa:       mfaVerified,
+	})
+	if err != nil {
+		return authModels.Token{}, err
+	}
+
+	return authModels.Token{
+		SessionId:    sessionId,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(expiresAt).Seconds()),
+	}, nil
+}
+
+// Refresh rotates the refresh token and signs a new access token with the current
+// state of the user.
+func (s defaultService) Refresh(ctx context.Context, refreshToken string) (authModels.Token, errs.ChatError) {
+	sessionId, newRefreshToken, err := s.sessionSrv.RotateRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return authModels.Token{}, err
+	}
+	session, err := s.sessionSrv.GetSession(ctx, sessionId)
+	if err != nil {
+		return authModels.Token{}, err
+	}
+	u, err := s.userRepo.GetUser(ctx, "id", session["user_id"], false)
+	if err != nil {
+		return authModels.Token{}, err
+	}
+
+	mfaVerified, _ := session["mfa"].(bool)
+
+	return s.issueToken(ctx, u, sessionId, newRefreshToken, mfaVerified)
 }
 
 func NewDefaultService(
 	userRepo user.ReaderWriterRepository,
+	loginRepo user.LoginRepository,
+	identityRepo user.IdentityRepository,
 	sessionRepo sessionManager.ReaderRepository,
 	sessionSrv sessionManager.Service,
+	tokenSrv token.Service,
+	mfaSrv mfa.Service,
+	hasher passwordHasher.PasswordHasher,
 ) auth.Service {
 	return &defaultService{
-		userRepo:    userRepo,
-		sessionRepo: sessionRepo,
-		sessionSrv:  sessionSrv,
+		userRepo:     userRepo,
+		loginRepo:    loginRepo,
+		identityRepo: identityRepo,
+		sessionRepo:  sessionRepo,
+		sessionSrv:   sessionSrv,
+		tokenSrv:     tokenSrv,
+		mfaSrv:       mfaSrv,
+		hasher:       hasher,
 	}
 }
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+// LinkIdentity links an identity to the user, who then logs in with any of its identities.
+// An identity already linked to a user, this one included, is a conflict.
+func (s defaultService) LinkIdentity(
+	ctx context.Context,
+	userId string,
+	identity userModels.Identity,
+) (userModels.Identity, errs.ChatError) {
+	if _, ok := userModels.MapAuthTypeString[identity.Provider]; !ok {
+		return userModels.Identity{}, errs.NewError(errs.ErrBadRequest,
+			fmt.Errorf("auth type %s not found", identity.Provider),
+		)
+	}
+	identity.UserId = userId
+	return s.identityRepo.CreateIdentity(ctx, nil, identity)
+}
+
+// UnlinkIdentity unlinks an identity of the user. The last identity of a user without a
+// password cannot be unlinked, since the user could not log in anymore.
+func (s defaultService) UnlinkIdentity(ctx context.Context, userId string, identityId int64) errs.ChatError {
+	u, err := s.userRepo.GetUser(ctx, "id", userId, false)
+	if err != nil {
+		return err
+	}
+	identities, err := s.identityRepo.ListIdentities(ctx, userId)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, identity := range identities {
+		found = found || identity.Id == identityId
+	}
+	if !found {
+		return errs.NewError(errs.ErrNotFound, fmt.Errorf("identity with id=%d not found", identityId))
+	}
+	if len(identities) == 1 && u.AuthType != userModels.AuthTypePassword {
+		return errs.NewError(errs.ErrBadRequest, fmt.Errorf("cannot unlink the last identity of the user"))
+	}
+	return s.identityRepo.DeleteIdentity(ctx, userId, identityId)
+}
+
+// ListIdentities lists the identities linked to the user.
+func (s defaultService) ListIdentities(ctx context.Context, userId string) ([]userModels.Identity, errs.ChatError) {
+	return s.identityRepo.ListIdentities(ctx, userId)
+}
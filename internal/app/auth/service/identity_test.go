@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	mfaMock "github.com/raffops/chat_auth/test/mocks/mfa"
+	sessionMock "github.com/raffops/chat_auth/test/mocks/sessionManager"
+	tokenMock "github.com/raffops/chat_auth/test/mocks/token"
+	userMock "github.com/raffops/chat_auth/test/mocks/user"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDefaultService_LoginLinksLegacyUser(t *testing.T) {
+	identity := userModels.Identity{Provider: "github", Subject: "583231", Email: "john@doe"}
+	u := userModels.User{
+		Id:       "1",
+		Email:    "john@doe",
+		AuthType: userModels.AuthTypeGithub,
+		Status:   userModels.StatusActive,
+	}
+	identityRepo := userMock.NewIdentityRepository(t)
+	identityRepo.EXPECT().GetIdentity(mock.Anything, "github", "583231").
+		Return(userModels.Identity{}, errs.NewError(errs.ErrNotFound, fmt.Errorf("not found"))).Once()
+	identityRepo.EXPECT().ListIdentities(mock.Anything, "1").Return([]userModels.Identity{}, nil).Once()
+	linked := identity
+	linked.UserId = "1"
+	identityRepo.EXPECT().CreateIdentity(mock.Anything, mock.Anything, linked).Return(linked, nil).Once()
+	userRepo := userMock.NewReaderWriterRepository(t)
+	userRepo.EXPECT().GetUser(mock.Anything, "email", "john@doe", true).Return(u, nil).Once()
+	mfaSrv := mfaMock.NewService(t)
+	mfaSrv.EXPECT().IsEnabled(mock.Anything, "1").Return(false, nil).Once()
+	sessionSrv := sessionMock.NewService(t)
+	sessionSrv.EXPECT().CreateSession(mock.Anything, "1", mock.Anything).Return("session", nil, nil).Once()
+	sessionSrv.EXPECT().CreateRefreshToken(mock.Anything, "1", "session").Return("refresh", nil).Once()
+	tokenSrv := tokenMock.NewService(t)
+	tokenSrv.EXPECT().Issue(mock.Anything, mock.Anything).Return("access", time.Now().Add(time.Minute), nil).Once()
+	loginRepo := userMock.NewLoginRepository(t)
+	loginRepo.EXPECT().RecordLogin(mock.Anything, mock.Anything).Return(userModels.Login{Id: 1}, nil).Once()
+
+	s := NewDefaultService(userRepo, loginRepo, identityRepo, nil, sessionSrv, tokenSrv, mfaSrv, nil)
+	token, err := s.Login(context.Background(), identity)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if token.SessionId != "session" {
+		t.Errorf("Login() session got = %v, want %v", token.SessionId, "session")
+	}
+}
+
+func TestDefaultService_LoginRejectsEmailOfOtherUser(t *testing.T) {
+	identity := userModels.Identity{Provider: "github", Subject: "583231", Email: "john@doe"}
+	tests := []struct {
+		name       string
+		u          userModels.User
+		identities []userModels.Identity
+	}{
+		{
+			name: "Test user of another provider",
+			u:    userModels.User{Id: "1", Email: "john@doe", AuthType: userModels.AuthTypeGoogle},
+		},
+		{
+			name:       "Test user with linked identities",
+			u:          userModels.User{Id: "1", Email: "john@doe", AuthType: userModels.AuthTypeGithub},
+			identities: []userModels.Identity{{Id: 1, UserId: "1", Provider: "github", Subject: "1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identityRepo := userMock.NewIdentityRepository(t)
+			identityRepo.EXPECT().GetIdentity(mock.Anything, "github", "583231").
+				Return(userModels.Identity{}, errs.NewError(errs.ErrNotFound, fmt.Errorf("not found"))).Once()
+			identityRepo.EXPECT().ListIdentities(mock.Anything, "1").Return(tt.identities, nil).Once()
+			userRepo := userMock.NewReaderWriterRepository(t)
+			userRepo.EXPECT().GetUser(mock.Anything, "email", "john@doe", true).Return(tt.u, nil).Once()
+
+			s := defaultService{userRepo: userRepo, identityRepo: identityRepo}
+			_, err := s.Login(context.Background(), identity)
+			if err == nil || !errors.Is(err.SvcError(), errs.ErrConflict) {
+				t.Errorf("Login() error = %v, want %v", err, errs.ErrConflict)
+			}
+		})
+	}
+}
+
+func TestDefaultService_UnlinkIdentity(t *testing.T) {
+	google := userModels.Identity{Id: 1, UserId: "1", Provider: "google", Subject: "1080"}
+	github := userModels.Identity{Id: 2, UserId: "1", Provider: "github", Subject: "583231"}
+	tests := []struct {
+		name       string
+		authType   userModels.AuthTypeId
+		identities []userModels.Identity
+		identityId int64
+		wantErr    error
+	}{
+		{
+			name:       "Test unlink one of the identities",
+			authType:   userModels.AuthTypeGoogle,
+			identities: []userModels.Identity{google, github},
+			identityId: 1,
+		},
+		{
+			name:       "Test unlink the last identity",
+			authType:   userModels.AuthTypeGoogle,
+			identities: []userModels.Identity{google},
+			identityId: 1,
+			wantErr:    errs.ErrBadRequest,
+		},
+		{
+			name:       "Test unlink the last identity of a password user",
+			authType:   userModels.AuthTypePassword,
+			identities: []userModels.Identity{github},
+			identityId: 2,
+		},
+		{
+			name:       "Test unlink an identity of another user",
+			authType:   userModels.AuthTypeGoogle,
+			identities: []userModels.Identity{google, github},
+			identityId: 3,
+			wantErr:    errs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := userMock.NewReaderWriterRepository(t)
+			userRepo.EXPECT().GetUser(mock.Anything, "id", "1", false).
+				Return(userModels.User{Id: "1", AuthType: tt.authType}, nil).Once()
+			identityRepo := userMock.NewIdentityRepository(t)
+			identityRepo.EXPECT().ListIdentities(mock.Anything, "1").Return(tt.identities, nil).Once()
+			if tt.wantErr == nil {
+				identityRepo.EXPECT().DeleteIdentity(mock.Anything, "1", tt.identityId).Return(nil).Once()
+			}
+
+			s := defaultService{userRepo: userRepo, identityRepo: identityRepo}
+			err := s.UnlinkIdentity(context.Background(), "1", tt.identityId)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("UnlinkIdentity() error = %v", err)
+			}
+			if tt.wantErr != nil && (err == nil || !errors.Is(err.SvcError(), tt.wantErr)) {
+				t.Errorf("UnlinkIdentity() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultService_SignUpValidatesUsername(t *testing.T) {
+	identity := userModels.Identity{Provider: "github", Subject: "583231", Email: "john@doe"}
+	for _, username := range []string{"", "john", strings.Repeat("j", 101)} {
+		s := defaultService{}
+		_, err := s.SignUp(context.Background(), username, identity, authModels.RoleUser)
+		if err == nil || !errors.Is(err.SvcError(), errs.ErrBadRequest) {
+			t.Errorf("SignUp(%q) error = %v, want %v", username, err, errs.ErrBadRequest)
+		}
+	}
+}
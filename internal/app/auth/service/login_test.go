@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	mfaMock "github.com/raffops/chat_auth/test/mocks/mfa"
+	sessionMock "github.com/raffops/chat_auth/test/mocks/sessionManager"
+	tokenMock "github.com/raffops/chat_auth/test/mocks/token"
+	userMock "github.com/raffops/chat_auth/test/mocks/user"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDefaultService_LoginRecordsLogin(t *testing.T) {
+	ctx := authModels.ContextWithClient(context.Background(), authModels.Client{Ip: "10.0.0.1", UserAgent: "curl/8.0"})
+	u := userModels.User{
+		Id:       "1",
+		Username: "jon",
+		Email:    "john@doe",
+		AuthType: userModels.AuthTypeGoogle,
+		Status:   userModels.StatusActive,
+	}
+	userRepo := userMock.NewReaderWriterRepository(t)
+	userRepo.EXPECT().GetUser(mock.Anything, "id", "1", true).Return(u, nil).Once()
+	identity := userModels.Identity{Provider: "google", Subject: "1080", Email: "john@doe"}
+	identityRepo := userMock.NewIdentityRepository(t)
+	identityRepo.EXPECT().GetIdentity(mock.Anything, "google", "1080").
+		Return(userModels.Identity{Id: 1, UserId: "1", Provider: "google", Subject: "1080"}, nil).Once()
+	mfaSrv := mfaMock.NewService(t)
+	mfaSrv.EXPECT().IsEnabled(mock.Anything, "1").Return(false, nil).Once()
+	sessionSrv := sessionMock.NewService(t)
+	sessionSrv.EXPECT().CreateSession(mock.Anything, "1", mock.Anything).Return("session", nil, nil).Once()
+	sessionSrv.EXPECT().CreateRefreshToken(mock.Anything, "1", "session").Return("refresh", nil).Once()
+	tokenSrv := tokenMock.NewService(t)
+	tokenSrv.EXPECT().Issue(mock.Anything, mock.Anything).Return("access", time.Now().Add(time.Minute), nil).Once()
+	loginRepo := userMock.NewLoginRepository(t)
+	loginRepo.EXPECT().RecordLogin(mock.Anything, mock.MatchedBy(func(login userModels.Login) bool {
+		return login.UserId == "1" && login.SessionHandle == sessionModels.SessionHandle("session") &&
+			login.Provider == "google" &&
+			login.Ip == "10.0.0.1" && login.UserAgent == "curl/8.0"
+	})).Return(userModels.Login{Id: 1}, nil).Once()
+
+	s := NewDefaultService(userRepo, loginRepo, identityRepo, nil, sessionSrv, tokenSrv, mfaSrv, nil)
+	token, err := s.Login(ctx, identity)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if token.SessionId != "session" {
+		t.Errorf("Login() session got = %v, want %v", token.SessionId, "session")
+	}
+}
+
+func TestDefaultService_LoginRejectsInactiveUsers(t *testing.T) {
+	tests := []struct {
+		name    string
+		u       userModels.User
+		wantErr error
+	}{
+		{
+			name:    "Test inactive user",
+			u:       userModels.User{Id: "1", Status: userModels.StatusInactive},
+			wantErr: errUserNotActive,
+		},
+		{
+			name:    "Test deleted user",
+			u:       userModels.User{Id: "1", Status: userModels.StatusActive, DeletedAt: time.Now()},
+			wantErr: errUserDeleted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := userMock.NewReaderWriterRepository(t)
+			userRepo.EXPECT().GetUser(mock.Anything, "id", "1", true).Return(tt.u, nil).Once()
+			identityRepo := userMock.NewIdentityRepository(t)
+			identityRepo.EXPECT().GetIdentity(mock.Anything, "google", "1080").
+				Return(userModels.Identity{Id: 1, UserId: "1", Provider: "google", Subject: "1080"}, nil).Once()
+
+			s := NewDefaultService(userRepo, nil, identityRepo, nil, nil, nil, nil, nil)
+			_, err := s.Login(context.Background(), userModels.Identity{Provider: "google", Subject: "1080"})
+			if err == nil || !errors.Is(err.SvcError(), errs.ErrNotAuthorized) ||
+				!strings.Contains(err.Error(), tt.wantErr.Error()) {
+				t.Errorf("Login() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultService_LoginReportsEvictedSessions(t *testing.T) {
+	u := userModels.User{Id: "1", AuthType: userModels.AuthTypeGoogle, Role: authModels.RoleAdmin}
+	sessionSrv := sessionMock.NewService(t)
+	sessionSrv.EXPECT().CreateSession(mock.Anything, "1", mock.Anything).Return("session", []string{"old"}, nil).Once()
+	sessionSrv.EXPECT().CreateRefreshToken(mock.Anything, "1", "session").Return("refresh", nil).Once()
+	tokenSrv := tokenMock.NewService(t)
+	tokenSrv.EXPECT().Issue(mock.Anything, mock.Anything).Return("access", time.Now().Add(time.Minute), nil).Once()
+	loginRepo := userMock.NewLoginRepository(t)
+	loginRepo.EXPECT().CloseLogins(mock.Anything, []string{sessionModels.SessionHandle("old")}, mock.Anything).
+		Return(nil).Once()
+
+	s := defaultService{loginRepo: loginRepo, sessionSrv: sessionSrv, tokenSrv: tokenSrv}
+	token, err := s.startSession(context.Background(), u, false)
+	if err != nil {
+		t.Fatalf("startSession() error = %v", err)
+	}
+	if len(token.EvictedSessions) != 1 || token.EvictedSessions[0] != sessionModels.SessionHandle("old") {
+		t.Errorf("startSession() evicted got = %v, want the handle of %v", token.EvictedSessions, "old")
+	}
+}
+
+func TestDefaultService_LogoutClosesLogin(t *testing.T) {
+	sessionSrv := sessionMock.NewService(t)
+	sessionSrv.EXPECT().FinishSession(mock.Anything, "session").Return(nil).Once()
+	loginRepo := userMock.NewLoginRepository(t)
+	loginRepo.EXPECT().CloseLogins(mock.Anything, []string{sessionModels.SessionHandle("session")}, mock.Anything).
+		Return(nil).Once()
+
+	s := NewDefaultService(nil, loginRepo, nil, nil, sessionSrv, nil, nil, nil)
+	if err := s.Logout(context.Background(), "session"); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+}
+
+func TestDefaultService_closeExpiredLogins(t *testing.T) {
+	loginRepo := userMock.NewLoginRepository(t)
+	batch := make([]userModels.Login, loginsBatchSize)
+	open := sessionModels.SessionHandle("open")
+	for i := range batch {
+		batch[i] = userModels.Login{Id: int64(i + 1), UserId: "1", SessionHandle: open}
+	}
+	batch[0].SessionHandle = sessionModels.SessionHandle("expired")
+	revoked := userModels.Login{
+		Id:            loginsBatchSize + 1,
+		UserId:        "2",
+		SessionHandle: sessionModels.SessionHandle("revoked"),
+	}
+	loginRepo.EXPECT().ListOpenLogins(mock.Anything, int64(0), loginsBatchSize).Return(batch, nil).Once()
+	loginRepo.EXPECT().ListOpenLogins(mock.Anything, int64(loginsBatchSize), loginsBatchSize).
+		Return([]userModels.Login{revoked}, nil).Once()
+	loginRepo.EXPECT().CloseLogins(mock.Anything, []string{batch[0].SessionHandle}, mock.Anything).Return(nil).Once()
+	loginRepo.EXPECT().CloseLogins(mock.Anything, []string{revoked.SessionHandle}, mock.Anything).Return(nil).Once()
+	sessionRepo := sessionMock.NewReaderRepository(t)
+	sessionRepo.EXPECT().SetMembers(mock.Anything, "user_sessions", "1", mock.Anything).
+		Return(map[string]time.Time{"open": time.Now().Add(time.Hour)}, nil).Once()
+	sessionRepo.EXPECT().SetMembers(mock.Anything, "user_sessions", "2", mock.Anything).
+		Return(map[string]time.Time{}, nil).Once()
+
+	s := defaultService{loginRepo: loginRepo, sessionRepo: sessionRepo}
+	closed, err := s.closeExpiredLogins(context.Background())
+	if err != nil {
+		t.Fatalf("closeExpiredLogins() error = %v", err)
+	}
+	if closed != 2 {
+		t.Errorf("closeExpiredLogins() got = %v, want %v", closed, 2)
+	}
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+const (
+	minUsernameLength = 5
+	maxUsernameLength = 100
+	minPasswordLength = 8
+	// bcrypt ignores everything after the 72nd byte
+	maxPasswordLength = 72
+)
+
+// dummyPasswordHash is compared with the password of unknown users, so the response time does
+// not reveal which usernames exist. It has the cost of 'passwordHasher.BcryptHasher'.
+const dummyPasswordHash = "$2a$14$ZSWrjGvHegX4JUx98fq4VumLIfKhpR.565FulUngI9g9I8opc89d6"
+
+var errInvalidCredentials = errors.New("invalid username or password")
+
+// SignUpWithPassword creates a user with 'userModels.AuthTypePassword' and the 'user' role.
+func (s defaultService) SignUpWithPassword(
+	ctx context.Context,
+	username, email, password string,
+) (authModels.Token, errs.ChatError) {
+	err := validateCredentials(username, email, password)
+	if err != nil {
+		return authModels.Token{}, err
+	}
+
+	passwordHash, errHash := s.hasher.HashPassword(password)
+	if errHash != nil {
+		return authModels.Token{}, errs.NewError(errs.ErrInternal, errHash)
+	}
+
+	u := userModels.User{
+		Username:     username,
+		Email:        email,
+		AuthType:     userModels.AuthTypePassword,
+		Role:         authModels.RoleUser,
+		Status:       userModels.StatusActive,
+		PasswordHash: passwordHash,
+	}
+	return s.signUp(ctx, u)
+}
+
+// LoginWithPassword verifies the credentials before creating the session.
+// Unknown users and wrong passwords return the same 'errs.ErrNotAuthenticated' error, after
+// comparing a password hash in both cases.
+func (s defaultService) LoginWithPassword(
+	ctx context.Context,
+	username, password string,
+) (authModels.Token, errs.ChatError) {
+	u, err := s.userRepo.GetUser(ctx, "username", username, false)
+	if err != nil && !errors.Is(err.SvcError(), errs.ErrNotFound) {
+		return authModels.Token{}, err
+	}
+	found := err == nil && u.AuthType == userModels.AuthTypePassword
+	passwordHash := dummyPasswordHash
+	if found {
+		passwordHash, err = s.userRepo.GetPasswordHash(ctx, u.Id)
+		found = err == nil
+	}
+	if !found {
+		passwordHash = dummyPasswordHash
+	}
+	if !s.hasher.CheckPasswordHash(password, passwordHash) || !found {
+		return authModels.Token{}, errs.NewError(errs.ErrNotAuthenticated, errInvalidCredentials)
+	}
+	return s.login(ctx, u)
+}
+
+func validateCredentials(username, email, password string) errs.ChatError {
+	if err := validateUsername(username); err != nil {
+		return err
+	}
+	if !strings.Contains(email, "@") {
+		return errs.NewError(errs.ErrBadRequest, errors.New("invalid email"))
+	}
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return errs.NewError(
+			errs.ErrBadRequest,
+			fmt.Errorf("password must have between %d and %d characters", minPasswordLength, maxPasswordLength),
+		)
+	}
+	return nil
+}
+
+// validateUsername applies the 'min=5,max=100' rule of 'userModels.User.Username', which counts
+// characters rather than bytes.
+func validateUsername(username string) errs.ChatError {
+	length := utf8.RuneCountInString(username)
+	if length < minUsernameLength || length > maxUsernameLength {
+		return errs.NewError(
+			errs.ErrBadRequest,
+			fmt.Errorf("username must have between %d and %d characters", minUsernameLength, maxUsernameLength),
+		)
+	}
+	return nil
+}
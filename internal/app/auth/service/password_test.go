@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	userMock "github.com/raffops/chat_auth/test/mocks/user"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/stretchr/testify/mock"
+)
+
+// recordingHasher records the hashes the passwords are compared with.
+type recordingHasher struct {
+	checked []string
+}
+
+func (h *recordingHasher) HashPassword(password string) (string, error) {
+	return "hash:" + password, nil
+}
+
+func (h *recordingHasher) CheckPasswordHash(password, hash string) bool {
+	h.checked = append(h.checked, hash)
+	return hash == "hash:"+password
+}
+
+func TestDefaultService_LoginWithPasswordComparesHashOfUnknownUsers(t *testing.T) {
+	tests := []struct {
+		name      string
+		u         userModels.User
+		err       errs.ChatError
+		hash      string
+		errHash   errs.ChatError
+		wantCheck string
+	}{
+		{
+			name:      "Test unknown user",
+			err:       errs.NewError(errs.ErrNotFound, fmt.Errorf("user not found")),
+			wantCheck: dummyPasswordHash,
+		},
+		{
+			name:      "Test user of another auth type",
+			u:         userModels.User{Id: "1", AuthType: userModels.AuthTypeGoogle},
+			wantCheck: dummyPasswordHash,
+		},
+		{
+			name:      "Test user without password hash",
+			u:         userModels.User{Id: "1", AuthType: userModels.AuthTypePassword},
+			errHash:   errs.NewError(errs.ErrNotFound, fmt.Errorf("password not found")),
+			wantCheck: dummyPasswordHash,
+		},
+		{
+			name:      "Test wrong password",
+			u:         userModels.User{Id: "1", AuthType: userModels.AuthTypePassword},
+			hash:      "hash:other password",
+			wantCheck: "hash:other password",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := userMock.NewReaderWriterRepository(t)
+			userRepo.EXPECT().GetUser(mock.Anything, "username", "john.doe", false).Return(tt.u, tt.err).Once()
+			if tt.err == nil && tt.u.AuthType == userModels.AuthTypePassword {
+				userRepo.EXPECT().GetPasswordHash(mock.Anything, "1").Return(tt.hash, tt.errHash).Once()
+			}
+			hasher := &recordingHasher{}
+
+			s := defaultService{userRepo: userRepo, hasher: hasher}
+			_, err := s.LoginWithPassword(context.Background(), "john.doe", "password")
+			if err == nil || !errors.Is(err.SvcError(), errs.ErrNotAuthenticated) {
+				t.Errorf("LoginWithPassword() error = %v, want %v", err, errs.ErrNotAuthenticated)
+			}
+			if len(hasher.checked) != 1 || hasher.checked[0] != tt.wantCheck {
+				t.Errorf("LoginWithPassword() checked = %v, want %v", hasher.checked, tt.wantCheck)
+			}
+		})
+	}
+}
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		wantErr  bool
+	}{
+		{name: "Test ascii username", username: "john.doe"},
+		{name: "Test multibyte username", username: "ジョン・ドウ"},
+		{name: "Test longest multibyte username", username: strings.Repeat("é", maxUsernameLength)},
+		{name: "Test short multibyte username", username: "joão", wantErr: true},
+		{name: "Test long multibyte username", username: strings.Repeat("é", maxUsernameLength+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateUsername(tt.username)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateUsername(%q) error = %v, wantErr %v", tt.username, err, tt.wantErr)
+			}
+		})
+	}
+}
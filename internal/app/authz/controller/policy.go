@@ -0,0 +1,84 @@
+package authz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/authz"
+	authzModels "github.com/raffops/chat_auth/internal/app/authz/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+type policyController struct {
+	policyService authz.PolicyService
+}
+
+func (c *policyController) ListPolicies(w http.ResponseWriter, r *http.Request) {
+	responseString, _ := json.Marshal(c.policyService.ListPolicies(r.Context()))
+	_, _ = w.Write(responseString)
+}
+
+func (c *policyController) AddRule(w http.ResponseWriter, r *http.Request) {
+	var rule authzModels.Rule
+	errDecode := json.NewDecoder(r.Body).Decode(&rule)
+	if errDecode != nil {
+		http.Error(w, errs.NewError(errs.ErrBadRequest, errDecode).Error(), http.StatusBadRequest)
+		return
+	}
+	role, err := validateRule(rule)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+
+	err = c.policyService.AddRule(r.Context(), rule.Method, role)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write([]byte("Rule added"))
+}
+
+// RemoveRule reads the rule from the query string, since the methods contain slashes.
+func (c *policyController) RemoveRule(w http.ResponseWriter, r *http.Request) {
+	rule := authzModels.Rule{
+		Method: r.URL.Query().Get("method"),
+		Role:   r.URL.Query().Get("role"),
+	}
+	role, err := validateRule(rule)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+
+	err = c.policyService.RemoveRule(r.Context(), rule.Method, role)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("Rule removed"))
+}
+
+// validateRule checks that the method is a full gRPC method name, like
+// '/chat.Chat/SendMessage', and returns the id of the role.
+func validateRule(rule authzModels.Rule) (authModels.RoleId, errs.ChatError) {
+	service, method, _ := strings.Cut(strings.TrimPrefix(rule.Method, "/"), "/")
+	if !strings.HasPrefix(rule.Method, "/") || service == "" || method == "" || strings.Contains(method, "/") {
+		return 0, errs.NewError(errs.ErrBadRequest, errors.New("method must be like /package.Service/Method"))
+	}
+	role, ok := authModels.MapRoleString[rule.Role]
+	if !ok {
+		return 0, errs.NewError(errs.ErrBadRequest, fmt.Errorf("role %s not found", rule.Role))
+	}
+	return role, nil
+}
+
+func NewPolicyController(policyService authz.PolicyService) authz.PolicyController {
+	return &policyController{policyService: policyService}
+}
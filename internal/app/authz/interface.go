@@ -0,0 +1,58 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	authz "github.com/raffops/chat_auth/internal/app/authz/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"google.golang.org/grpc"
+)
+
+type PolicyController interface {
+	ListPolicies(w http.ResponseWriter, r *http.Request)
+	AddRule(w http.ResponseWriter, r *http.Request)
+	RemoveRule(w http.ResponseWriter, r *http.Request)
+}
+
+type Repository interface {
+	GetRolePermissions(ctx context.Context) (map[authModels.RoleId][]authz.Permission, errs.ChatError)
+}
+
+type PolicyRepository interface {
+	ListPolicies(ctx context.Context) (map[string][]authModels.RoleId, errs.ChatError)
+	SetPolicy(ctx context.Context, method string, roles []authModels.RoleId) errs.ChatError
+	AddRule(ctx context.Context, method string, role authModels.RoleId) errs.ChatError
+	RemoveRule(ctx context.Context, method string, role authModels.RoleId) errs.ChatError
+}
+
+type Service interface {
+	HasPermission(ctx context.Context, role authModels.RoleId, permission authz.Permission) (bool, errs.ChatError)
+	RequirePermission(permission authz.Permission, next http.HandlerFunc) http.HandlerFunc
+	SetPermission(method string, permission authz.Permission)
+	CheckGrpcPermission(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error
+	CheckGrpcUnaryPermission(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error)
+}
+
+type PolicyService interface {
+	ListPolicies(ctx context.Context) []authz.Policy
+	GetRoles(ctx context.Context, method string) ([]authModels.RoleId, errs.ChatError)
+	SetRoles(ctx context.Context, method string, roles []authModels.RoleId) errs.ChatError
+	AddRule(ctx context.Context, method string, role authModels.RoleId) errs.ChatError
+	RemoveRule(ctx context.Context, method string, role authModels.RoleId) errs.ChatError
+	IsAllowed(ctx context.Context, method string, role authModels.RoleId) bool
+	Reload(ctx context.Context) errs.ChatError
+	StartReloading(ctx context.Context, interval time.Duration)
+}
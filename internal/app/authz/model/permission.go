@@ -0,0 +1,16 @@
+package authz
+
+// Permission is the name of a row of the 'permission' table. Roles are granted
+// permissions through the 'role_permission' table.
+type Permission string
+
+const (
+	PermissionCreateUser                Permission = "Create User"
+	PermissionUpdateUser                Permission = "Update User"
+	PermissionDeleteUser                Permission = "Delete User"
+	PermissionViewUser                  Permission = "View User"
+	PermissionManageUserGroup           Permission = "Manage User Group"
+	PermissionManagePermission          Permission = "Manage Permission"
+	PermissionManageUserGroupPermission Permission = "Manage User Group Permission"
+	PermissionManageSelf                Permission = "Manage Self"
+)
@@ -0,0 +1,26 @@
+package authz
+
+// Policy lists the roles allowed to call a gRPC method, like '/chat.Chat/SendMessage'.
+type Policy struct {
+	Method string   `json:"method"`
+	Roles  []string `json:"roles"`
+}
+
+// Rule grants a role access to a gRPC method.
+type Rule struct {
+	Method string `json:"method"`
+	Role   string `json:"role"`
+}
+
+// DefaultPolicy decides the access to the gRPC methods without any rule.
+type DefaultPolicy string
+
+const (
+	DefaultPolicyDeny  DefaultPolicy = "deny"
+	DefaultPolicyAllow DefaultPolicy = "allow"
+)
+
+var MapDefaultPolicyString = map[string]DefaultPolicy{
+	"deny":  DefaultPolicyDeny,
+	"allow": DefaultPolicyAllow,
+}
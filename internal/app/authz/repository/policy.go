@@ -0,0 +1,143 @@
+package authz
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/lib/pq"
+	authModel "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/authz"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type policyRepository struct {
+	db *sql.DB
+}
+
+// ListPolicies loads the roles allowed to call each gRPC method.
+func (p policyRepository) ListPolicies(ctx context.Context) (map[string][]authModel.RoleId, errs.ChatError) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("method", "role_id").
+		From("public.grpc_method_policy").
+		OrderBy("method", "role_id")
+	queryString, args := sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+
+	rows, err := p.db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.Debug("error closing rows", zap.Error(err))
+		}
+	}(rows)
+
+	policies := map[string][]authModel.RoleId{}
+	for rows.Next() {
+		var method string
+		var roleId int
+		if err := rows.Scan(&method, &roleId); err != nil {
+			return nil, errs.NewError(errs.ErrInternal, err)
+		}
+		policies[method] = append(policies[method], authModel.RoleId(roleId))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	return policies, nil
+}
+
+// SetPolicy replaces the roles allowed to call a method. An empty list removes the method.
+func (p policyRepository) SetPolicy(ctx context.Context, method string, roles []authModel.RoleId) errs.ChatError {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	defer tx.Rollback()
+
+	db := sqlbuilder.NewDeleteBuilder()
+	db.DeleteFrom("public.grpc_method_policy").
+		Where(db.Equal("method", method))
+	queryString, args := db.BuildWithFlavor(sqlbuilder.PostgreSQL)
+	if _, err = tx.ExecContext(ctx, queryString, args...); err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+
+	if len(roles) > 0 {
+		ib := sqlbuilder.NewInsertBuilder()
+		ib.InsertInto("public.grpc_method_policy").
+			Cols("method", "role_id")
+		for _, role := range roles {
+			ib.Values(method, role)
+		}
+		queryString, args = ib.BuildWithFlavor(sqlbuilder.PostgreSQL)
+		if _, err = tx.ExecContext(ctx, queryString, args...); err != nil {
+			return getPolicyError(err, method)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return nil
+}
+
+// AddRule allows a role to call a method. If the rule already exists, the svcError is 'errs.ErrConflict'.
+func (p policyRepository) AddRule(ctx context.Context, method string, role authModel.RoleId) errs.ChatError {
+	ib := sqlbuilder.NewInsertBuilder()
+	ib.InsertInto("public.grpc_method_policy").
+		Cols("method", "role_id").
+		Values(method, role)
+	queryString, args := ib.BuildWithFlavor(sqlbuilder.PostgreSQL)
+
+	_, err := p.db.ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return getPolicyError(err, method)
+	}
+	return nil
+}
+
+// RemoveRule revokes the access of a role to a method. If the rule does not exist,
+// the svcError is 'errs.ErrNotFound'.
+func (p policyRepository) RemoveRule(ctx context.Context, method string, role authModel.RoleId) errs.ChatError {
+	db := sqlbuilder.NewDeleteBuilder()
+	db.DeleteFrom("public.grpc_method_policy").
+		Where(db.Equal("method", method), db.Equal("role_id", role))
+	queryString, args := db.BuildWithFlavor(sqlbuilder.PostgreSQL)
+
+	result, err := p.db.ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	if affected == 0 {
+		return errs.NewError(errs.ErrNotFound, fmt.Errorf("rule for method %s not found", method))
+	}
+	return nil
+}
+
+func getPolicyError(err error, method string) errs.ChatError {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505":
+			return errs.NewError(errs.ErrConflict, fmt.Errorf("rule for method %s already exists", method))
+		case "23503":
+			return errs.NewError(errs.ErrBadRequest, errors.New("role not found"))
+		}
+	}
+	return errs.NewError(errs.ErrInternal, err)
+}
+
+func NewPolicyRepository(db *sql.DB) authz.PolicyRepository {
+	return &policyRepository{db: db}
+}
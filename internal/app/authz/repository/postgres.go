@@ -0,0 +1,65 @@
+package authz
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/huandu/go-sqlbuilder"
+	authModel "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/authz"
+	authzModel "github.com/raffops/chat_auth/internal/app/authz/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+// GetRolePermissions loads the permissions granted to every role. Deleted grants and
+// deleted permissions are ignored.
+func (p repository) GetRolePermissions(
+	ctx context.Context,
+) (map[authModel.RoleId][]authzModel.Permission, errs.ChatError) {
+	queryString, args := BuildRolePermissionsQuery()
+	rows, err := p.db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.Debug("error closing rows", zap.Error(err))
+		}
+	}(rows)
+
+	rolePermissions := map[authModel.RoleId][]authzModel.Permission{}
+	for rows.Next() {
+		var roleId int
+		var permission string
+		if err := rows.Scan(&roleId, &permission); err != nil {
+			return nil, errs.NewError(errs.ErrInternal, err)
+		}
+		role := authModel.RoleId(roleId)
+		rolePermissions[role] = append(rolePermissions[role], authzModel.Permission(permission))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	return rolePermissions, nil
+}
+
+func BuildRolePermissionsQuery() (string, []interface{}) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("rp.role_id", "p.name").
+		From("public.role_permission rp").
+		Join("public.permission p", "p.id = rp.permission_id").
+		Where(sb.IsNull("rp.deleted_at"), sb.IsNull("p.deleted_at")).
+		OrderBy("rp.role_id", "p.name")
+	return sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+func NewPostgresRepository(db *sql.DB) authz.Repository {
+	return &repository{db: db}
+}
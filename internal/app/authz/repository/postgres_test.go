@@ -0,0 +1,16 @@
+package authz
+
+import "testing"
+
+func TestBuildRolePermissionsQuery(t *testing.T) {
+	want := "SELECT rp.role_id, p.name FROM public.role_permission rp " +
+		"JOIN public.permission p ON p.id = rp.permission_id " +
+		"WHERE rp.deleted_at IS NULL AND p.deleted_at IS NULL ORDER BY rp.role_id, p.name"
+	got, args := BuildRolePermissionsQuery()
+	if got != want {
+		t.Errorf("BuildRolePermissionsQuery() \n\tgot = %v\n\twant = %v", got, want)
+	}
+	if len(args) != 0 {
+		t.Errorf("BuildRolePermissionsQuery() got args = %v, want none", args)
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/authz"
+	authzModels "github.com/raffops/chat_auth/internal/app/authz/model"
+	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// service checks the permissions granted to the role of a session.
+//
+// The role→permission mappings are read from the database and kept in memory for
+// cacheTtl, so a change in 'role_permission' takes at most cacheTtl to be enforced.
+type service struct {
+	repo       authz.Repository
+	sessionSrv sessionManager.Service
+	cacheTtl   time.Duration
+
+	mu                     sync.RWMutex
+	rolePermissions        map[authModels.RoleId]map[authzModels.Permission]struct{}
+	loadedAt               time.Time
+	mapMethodsToPermission map[string]authzModels.Permission
+}
+
+func (s *service) HasPermission(
+	ctx context.Context,
+	role authModels.RoleId,
+	permission authzModels.Permission,
+) (bool, errs.ChatError) {
+	rolePermissions, err := s.getRolePermissions(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, ok := rolePermissions[role][permission]
+	return ok, nil
+}
+
+// RequirePermission only calls next if the session sent in the 'Authorization' header
+// belongs to a role granted with the permission. The session is checked by
+// 'CheckRestSession', so it is extended and its principal is injected in the context, like
+// on the routes protected by role.
+func (s *service) RequirePermission(permission authzModels.Permission, next http.HandlerFunc) http.HandlerFunc {
+	roles := slices.Collect(maps.Keys(authModels.MapRole))
+	return s.sessionSrv.CheckRestSession(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := sessionModels.PrincipalFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		allowed, err := s.HasPermission(r.Context(), principal.Role, permission)
+		if err != nil {
+			http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+			return
+		}
+		if !allowed {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}, roles)
+}
+
+// SetPermission sets the permission required by a gRPC method.
+func (s *service) SetPermission(method string, permission authzModels.Permission) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mapMethodsToPermission[method] = permission
+}
+
+// CheckGrpcPermission is a stream interceptor that works as RequirePermission, with the
+// permission set for the method by SetPermission. It runs after 'CheckGrpcSession', which
+// injects the principal in the context. Methods without permission are only checked by the
+// method policy.
+func (s *service) CheckGrpcPermission(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if err := s.checkGrpcPermission(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// CheckGrpcUnaryPermission is the unary counterpart of CheckGrpcPermission, run after
+// 'CheckGrpcUnarySession'.
+func (s *service) CheckGrpcUnaryPermission(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if err := s.checkGrpcPermission(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *service) checkGrpcPermission(ctx context.Context, method string) error {
+	s.mu.RLock()
+	permission, ok := s.mapMethodsToPermission[method]
+	s.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	principal, ok := sessionModels.PrincipalFromContext(ctx)
+	if !ok {
+		return status.Errorf(codes.Unauthenticated, "missing session")
+	}
+	allowed, err := s.HasPermission(ctx, principal.Role, permission)
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot check permission")
+	}
+	if !allowed {
+		return status.Errorf(codes.PermissionDenied, "missing permission")
+	}
+	return nil
+}
+
+// getRolePermissions returns the cached mappings, reloading them once the cache expired.
+// If the reload fails, the expired mappings keep being used.
+func (s *service) getRolePermissions(
+	ctx context.Context,
+) (map[authModels.RoleId]map[authzModels.Permission]struct{}, errs.ChatError) {
+	s.mu.RLock()
+	rolePermissions, loadedAt := s.rolePermissions, s.loadedAt
+	s.mu.RUnlock()
+	if rolePermissions != nil && time.Since(loadedAt) < s.cacheTtl {
+		return rolePermissions, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rolePermissions != nil && time.Since(s.loadedAt) < s.cacheTtl {
+		return s.rolePermissions, nil
+	}
+	loaded, err := s.repo.GetRolePermissions(ctx)
+	if err != nil && s.rolePermissions != nil {
+		logger.Error("cannot reload role permissions", zap.Error(err))
+		return s.rolePermissions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.rolePermissions = make(map[authModels.RoleId]map[authzModels.Permission]struct{}, len(loaded))
+	for role, permissions := range loaded {
+		s.rolePermissions[role] = make(map[authzModels.Permission]struct{}, len(permissions))
+		for _, permission := range permissions {
+			s.rolePermissions[role][permission] = struct{}{}
+		}
+	}
+	s.loadedAt = time.Now()
+	return s.rolePermissions, nil
+}
+
+func sanityCheck() {
+	envVariables := []string{
+		"PERMISSIONS_CACHE_TTL",
+	}
+	for _, envVariable := range envVariables {
+		if _, ok := os.LookupEnv(envVariable); !ok {
+			logger.Fatal("Environment variable not set", zap.String("variable", envVariable))
+		}
+	}
+}
+
+func NewDefaultService(
+	repo authz.Repository,
+	sessionSrv sessionManager.Service,
+	cacheTtl time.Duration,
+) authz.Service {
+	sanityCheck()
+	return &service{
+		repo:                   repo,
+		sessionSrv:             sessionSrv,
+		cacheTtl:               cacheTtl,
+		mapMethodsToPermission: map[string]authzModels.Permission{},
+	}
+}
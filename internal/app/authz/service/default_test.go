@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	authzModels "github.com/raffops/chat_auth/internal/app/authz/model"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	authzMock "github.com/raffops/chat_auth/test/mocks/authz"
+	grpcMock "github.com/raffops/chat_auth/test/mocks/grpc"
+	sessionMock "github.com/raffops/chat_auth/test/mocks/sessionManager"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var seededPermissions = map[authModels.RoleId][]authzModels.Permission{
+	authModels.RoleAdmin: {authzModels.PermissionDeleteUser, authzModels.PermissionManageSelf},
+	authModels.RoleUser:  {authzModels.PermissionManageSelf},
+}
+
+func newTestService(t *testing.T, cacheTtl time.Duration) (*service, *authzMock.Repository, *sessionMock.Service) {
+	os.Setenv("PERMISSIONS_CACHE_TTL", cacheTtl.String())
+	repo := authzMock.NewRepository(t)
+	sessionSrv := sessionMock.NewService(t)
+	return NewDefaultService(repo, sessionSrv, cacheTtl).(*service), repo, sessionSrv
+}
+
+func TestService_HasPermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       authModels.RoleId
+		permission authzModels.Permission
+		want       bool
+	}{
+		{
+			name:       "Test admin can delete users",
+			role:       authModels.RoleAdmin,
+			permission: authzModels.PermissionDeleteUser,
+			want:       true,
+		},
+		{
+			name:       "Test user cannot delete users",
+			role:       authModels.RoleUser,
+			permission: authzModels.PermissionDeleteUser,
+			want:       false,
+		},
+		{
+			name:       "Test user can manage self",
+			role:       authModels.RoleUser,
+			permission: authzModels.PermissionManageSelf,
+			want:       true,
+		},
+		{
+			name:       "Test unknown role",
+			role:       authModels.RoleId(3),
+			permission: authzModels.PermissionManageSelf,
+			want:       false,
+		},
+	}
+	srv, repo, _ := newTestService(t, time.Minute)
+	repo.EXPECT().GetRolePermissions(mock.Anything).Return(seededPermissions, nil).Once()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := srv.HasPermission(context.Background(), tt.role, tt.permission)
+			if err != nil {
+				t.Fatalf("HasPermission() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("HasPermission() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_HasPermissionCacheExpiration(t *testing.T) {
+	ctx := context.Background()
+	srv, repo, _ := newTestService(t, time.Millisecond)
+	repo.EXPECT().GetRolePermissions(mock.Anything).Return(seededPermissions, nil).Once()
+	repo.EXPECT().GetRolePermissions(mock.Anything).
+		Return(map[authModels.RoleId][]authzModels.Permission{}, nil).Once()
+	repo.EXPECT().GetRolePermissions(mock.Anything).
+		Return(nil, errs.NewError(errs.ErrInternal, errors.New("connection refused"))).Once()
+
+	if got, _ := srv.HasPermission(ctx, authModels.RoleAdmin, authzModels.PermissionDeleteUser); !got {
+		t.Fatalf("HasPermission() got = %v, want true", got)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if got, _ := srv.HasPermission(ctx, authModels.RoleAdmin, authzModels.PermissionDeleteUser); got {
+		t.Fatalf("HasPermission() after revoking got = %v, want false", got)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if _, err := srv.HasPermission(ctx, authModels.RoleAdmin, authzModels.PermissionDeleteUser); err != nil {
+		t.Fatalf("HasPermission() with failed reload error = %v, want the expired permissions", err)
+	}
+}
+
+func TestService_RequirePermission(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{
+			name:          "Test admin session",
+			authorization: "Bearer admin",
+			want:          http.StatusOK,
+		},
+		{
+			name:          "Test user session",
+			authorization: "Bearer user",
+			want:          http.StatusForbidden,
+		},
+		{
+			name:          "Test invalid session",
+			authorization: "Bearer invalid",
+			want:          http.StatusUnauthorized,
+		},
+		{
+			name:          "Test missing token",
+			authorization: "",
+			want:          http.StatusUnauthorized,
+		},
+	}
+	sessions := map[string]sessionModels.Principal{
+		"admin": {UserId: "1", Role: authModels.RoleAdmin, SessionId: "admin"},
+		"user":  {UserId: "2", Role: authModels.RoleUser, SessionId: "user"},
+	}
+	srv, repo, sessionSrv := newTestService(t, time.Minute)
+	repo.EXPECT().GetRolePermissions(mock.Anything).Return(seededPermissions, nil).Maybe()
+	sessionSrv.EXPECT().CheckRestSession(mock.Anything, mock.Anything).RunAndReturn(
+		func(next http.HandlerFunc, roles []authModels.RoleId) http.HandlerFunc {
+			if len(roles) != len(authModels.MapRole) {
+				t.Errorf("CheckRestSession() roles = %v, want every role", roles)
+			}
+			return func(w http.ResponseWriter, r *http.Request) {
+				principal, ok := sessions[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+				if !ok {
+					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					return
+				}
+				next(w, r.WithContext(sessionModels.ContextWithPrincipal(r.Context(), principal)))
+			}
+		},
+	).Once()
+	next := func(w http.ResponseWriter, r *http.Request) {
+		if principal, ok := sessionModels.PrincipalFromContext(r.Context()); !ok || principal.UserId != "1" {
+			t.Errorf("RequirePermission() principal = %v, want the admin session", principal)
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	handler := srv.RequirePermission(authzModels.PermissionDeleteUser, next)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("DELETE", "/", nil)
+			r.Header.Set("Authorization", tt.authorization)
+			handler(w, r)
+			if w.Code != tt.want {
+				t.Errorf("RequirePermission() got = %v, want %v", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_CheckGrpcPermission(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		principal *sessionModels.Principal
+		want      codes.Code
+	}{
+		{
+			name:      "Test admin on admin method",
+			method:    "/admin",
+			principal: &sessionModels.Principal{UserId: "1", Role: authModels.RoleAdmin},
+			want:      codes.OK,
+		},
+		{
+			name:      "Test user on admin method",
+			method:    "/admin",
+			principal: &sessionModels.Principal{UserId: "2", Role: authModels.RoleUser},
+			want:      codes.PermissionDenied,
+		},
+		{
+			name:   "Test admin method without session",
+			method: "/admin",
+			want:   codes.Unauthenticated,
+		},
+		{
+			name:      "Test method without permission",
+			method:    "/other",
+			principal: &sessionModels.Principal{UserId: "2", Role: authModels.RoleUser},
+			want:      codes.OK,
+		},
+	}
+	srv, repo, _ := newTestService(t, time.Minute)
+	repo.EXPECT().GetRolePermissions(mock.Anything).Return(seededPermissions, nil).Maybe()
+	srv.SetPermission("/admin", authzModels.PermissionDeleteUser)
+	unaryHandler := func(ctx context.Context, req any) (any, error) { return nil, nil }
+	streamHandler := func(srv any, stream grpc.ServerStream) error { return nil }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = sessionModels.ContextWithPrincipal(ctx, *tt.principal)
+			}
+			_, err := srv.CheckGrpcUnaryPermission(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, unaryHandler)
+			if status.Code(err) != tt.want {
+				t.Errorf("CheckGrpcUnaryPermission() got = %v, want %v", status.Code(err), tt.want)
+			}
+
+			ss := grpcMock.NewServerStream(t)
+			ss.EXPECT().Context().Return(ctx).Maybe()
+			err = srv.CheckGrpcPermission(nil, ss, &grpc.StreamServerInfo{FullMethod: tt.method}, streamHandler)
+			if status.Code(err) != tt.want {
+				t.Errorf("CheckGrpcPermission() got = %v, want %v", status.Code(err), tt.want)
+			}
+		})
+	}
+}
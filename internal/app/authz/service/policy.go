@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/authz"
+	authzModels "github.com/raffops/chat_auth/internal/app/authz/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// policyService keeps in memory the roles allowed to call each gRPC method.
+//
+// The policy is stored by the repository, so every instance shares it. Changes made
+// through this service are applied at once, and changes made by other instances are
+// picked up on the next reload.
+type policyService struct {
+	repo          authz.PolicyRepository
+	defaultPolicy authzModels.DefaultPolicy
+
+	mu       sync.RWMutex
+	policies map[string][]authModels.RoleId
+}
+
+// ListPolicies returns the cached policies sorted by method.
+func (s *policyService) ListPolicies(ctx context.Context) []authzModels.Policy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	policies := make([]authzModels.Policy, 0, len(s.policies))
+	for method, roles := range s.policies {
+		policy := authzModels.Policy{Method: method, Roles: make([]string, 0, len(roles))}
+		for _, role := range roles {
+			policy.Roles = append(policy.Roles, authModels.MapRole[role])
+		}
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Method < policies[j].Method })
+	return policies
+}
+
+func (s *policyService) GetRoles(ctx context.Context, method string) ([]authModels.RoleId, errs.ChatError) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if roles, ok := s.policies[method]; ok {
+		return roles, nil
+	}
+	return nil, errs.NewError(errs.ErrNotFound, fmt.Errorf("method not found"))
+}
+
+// SetRoles replaces the roles allowed to call a method.
+func (s *policyService) SetRoles(ctx context.Context, method string, roles []authModels.RoleId) errs.ChatError {
+	err := s.repo.SetPolicy(ctx, method, roles)
+	if err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+func (s *policyService) AddRule(ctx context.Context, method string, role authModels.RoleId) errs.ChatError {
+	err := s.repo.AddRule(ctx, method, role)
+	if err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+func (s *policyService) RemoveRule(ctx context.Context, method string, role authModels.RoleId) errs.ChatError {
+	err := s.repo.RemoveRule(ctx, method, role)
+	if err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+// IsAllowed reports whether the role can call the method. Methods without rules
+// follow the default policy.
+func (s *policyService) IsAllowed(ctx context.Context, method string, role authModels.RoleId) bool {
+	roles, err := s.GetRoles(ctx, method)
+	if err != nil {
+		return s.defaultPolicy == authzModels.DefaultPolicyAllow
+	}
+	return slices.Contains(roles, role)
+}
+
+// Reload replaces the cached policies with the ones stored by the repository.
+func (s *policyService) Reload(ctx context.Context) errs.ChatError {
+	policies, err := s.repo.ListPolicies(ctx)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies = policies
+	return nil
+}
+
+// StartReloading reloads the policies at every interval until the context is done.
+func (s *policyService) StartReloading(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Reload(ctx); err != nil {
+					logger.Error("cannot reload grpc method policies", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+func policySanityCheck() {
+	envVariables := []string{
+		"GRPC_DEFAULT_POLICY",
+		"POLICY_RELOAD_INTERVAL",
+	}
+	for _, envVariable := range envVariables {
+		if _, ok := os.LookupEnv(envVariable); !ok {
+			logger.Fatal("Environment variable not set", zap.String("variable", envVariable))
+		}
+	}
+}
+
+func NewPolicyService(
+	ctx context.Context,
+	repo authz.PolicyRepository,
+	defaultPolicy authzModels.DefaultPolicy,
+) (authz.PolicyService, errs.ChatError) {
+	policySanityCheck()
+	if _, ok := authzModels.MapDefaultPolicyString[string(defaultPolicy)]; !ok {
+		return nil, errs.NewError(errs.ErrBadRequest, errors.New("default policy must be deny or allow"))
+	}
+	s := &policyService{
+		repo:          repo,
+		defaultPolicy: defaultPolicy,
+		policies:      map[string][]authModels.RoleId{},
+	}
+	err := s.Reload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
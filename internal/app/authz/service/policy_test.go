@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	authzModels "github.com/raffops/chat_auth/internal/app/authz/model"
+	authzMock "github.com/raffops/chat_auth/test/mocks/authz"
+	"github.com/stretchr/testify/mock"
+)
+
+var storedPolicies = map[string][]authModels.RoleId{
+	"/chat.Chat/SendMessage": {authModels.RoleAdmin, authModels.RoleUser},
+	"/chat.Chat/DeleteRoom":  {authModels.RoleAdmin},
+}
+
+func TestPolicyService_IsAllowed(t *testing.T) {
+	os.Setenv("GRPC_DEFAULT_POLICY", "deny")
+	os.Setenv("POLICY_RELOAD_INTERVAL", "1m")
+	tests := []struct {
+		name          string
+		defaultPolicy authzModels.DefaultPolicy
+		method        string
+		role          authModels.RoleId
+		want          bool
+	}{
+		{
+			name:          "Test allowed role",
+			defaultPolicy: authzModels.DefaultPolicyDeny,
+			method:        "/chat.Chat/SendMessage",
+			role:          authModels.RoleUser,
+			want:          true,
+		},
+		{
+			name:          "Test not allowed role",
+			defaultPolicy: authzModels.DefaultPolicyAllow,
+			method:        "/chat.Chat/DeleteRoom",
+			role:          authModels.RoleUser,
+			want:          false,
+		},
+		{
+			name:          "Test unknown method with default deny",
+			defaultPolicy: authzModels.DefaultPolicyDeny,
+			method:        "/chat.Chat/Unknown",
+			role:          authModels.RoleAdmin,
+			want:          false,
+		},
+		{
+			name:          "Test unknown method with default allow",
+			defaultPolicy: authzModels.DefaultPolicyAllow,
+			method:        "/chat.Chat/Unknown",
+			role:          authModels.RoleUser,
+			want:          true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := authzMock.NewPolicyRepository(t)
+			repo.EXPECT().ListPolicies(mock.Anything).Return(storedPolicies, nil).Once()
+			srv, err := NewPolicyService(context.Background(), repo, tt.defaultPolicy)
+			if err != nil {
+				t.Fatalf("NewPolicyService() error = %v", err)
+			}
+			if got := srv.IsAllowed(context.Background(), tt.method, tt.role); got != tt.want {
+				t.Errorf("IsAllowed() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyService_SetRoles(t *testing.T) {
+	os.Setenv("GRPC_DEFAULT_POLICY", "deny")
+	os.Setenv("POLICY_RELOAD_INTERVAL", "1m")
+	ctx := context.Background()
+	repo := authzMock.NewPolicyRepository(t)
+	repo.EXPECT().ListPolicies(mock.Anything).Return(map[string][]authModels.RoleId{}, nil).Once()
+	repo.EXPECT().SetPolicy(mock.Anything, "/chat.Chat/DeleteRoom", []authModels.RoleId{authModels.RoleAdmin}).
+		Return(nil).Once()
+	repo.EXPECT().ListPolicies(mock.Anything).Return(storedPolicies, nil).Once()
+
+	srv, err := NewPolicyService(ctx, repo, authzModels.DefaultPolicyDeny)
+	if err != nil {
+		t.Fatalf("NewPolicyService() error = %v", err)
+	}
+	if srv.IsAllowed(ctx, "/chat.Chat/DeleteRoom", authModels.RoleAdmin) {
+		t.Fatalf("IsAllowed() before SetRoles got = true, want false")
+	}
+	if err := srv.SetRoles(ctx, "/chat.Chat/DeleteRoom", []authModels.RoleId{authModels.RoleAdmin}); err != nil {
+		t.Fatalf("SetRoles() error = %v", err)
+	}
+	if !srv.IsAllowed(ctx, "/chat.Chat/DeleteRoom", authModels.RoleAdmin) {
+		t.Fatalf("IsAllowed() after SetRoles got = false, want true")
+	}
+
+	want := []authzModels.Policy{
+		{Method: "/chat.Chat/DeleteRoom", Roles: []string{"admin"}},
+		{Method: "/chat.Chat/SendMessage", Roles: []string{"admin", "user"}},
+	}
+	if got := srv.ListPolicies(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListPolicies() got = %v, want %v", got, want)
+	}
+}
+
+func TestNewPolicyService_InvalidDefaultPolicy(t *testing.T) {
+	os.Setenv("GRPC_DEFAULT_POLICY", "maybe")
+	os.Setenv("POLICY_RELOAD_INTERVAL", "1m")
+	_, err := NewPolicyService(context.Background(), authzMock.NewPolicyRepository(t), "maybe")
+	if err == nil {
+		t.Fatalf("NewPolicyService() error = nil, want error")
+	}
+}
@@ -0,0 +1,80 @@
+package mfa
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/raffops/chat_auth/internal/app/mfa"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	"github.com/raffops/chat_auth/internal/app/user"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+type controller struct {
+	userRepo   user.ReaderRepository
+	mfaService mfa.Service
+}
+
+func (c *controller) Enroll(w http.ResponseWriter, r *http.Request) {
+	u, err := c.sessionUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+
+	enrollment, err := c.mfaService.Enroll(r.Context(), u.Id, u.Username)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+
+	responseString, _ := json.Marshal(enrollment)
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(responseString)
+}
+
+func (c *controller) ConfirmEnrollment(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Code string `json:"code"`
+	}
+	errDecode := json.NewDecoder(r.Body).Decode(&request)
+	if errDecode != nil || request.Code == "" {
+		http.Error(w,
+			errs.NewError(errs.ErrBadRequest, fmt.Errorf("code not found")).Error(),
+			http.StatusBadRequest,
+		)
+		return
+	}
+	u, err := c.sessionUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+
+	recoveryCodes, err := c.mfaService.ConfirmEnrollment(r.Context(), u.Id, request.Code)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+
+	responseString, _ := json.Marshal(recoveryCodes)
+	_, _ = w.Write(responseString)
+}
+
+// sessionUser returns the user of the session checked by the session middleware.
+func (c *controller) sessionUser(r *http.Request) (userModels.User, errs.ChatError) {
+	principal, err := sessionModels.PrincipalFromRequest(r)
+	if err != nil {
+		return userModels.User{}, err
+	}
+	return c.userRepo.GetUser(r.Context(), "id", principal.UserId, false)
+}
+
+func NewController(userRepository user.ReaderRepository, mfaService mfa.Service) mfa.Controller {
+	return &controller{
+		userRepo:   userRepository,
+		mfaService: mfaService,
+	}
+}
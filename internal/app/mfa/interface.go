@@ -0,0 +1,33 @@
+package mfa
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	mfa "github.com/raffops/chat_auth/internal/app/mfa/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+type Controller interface {
+	Enroll(w http.ResponseWriter, r *http.Request)
+	ConfirmEnrollment(w http.ResponseWriter, r *http.Request)
+}
+
+type Service interface {
+	Enroll(ctx context.Context, userId, accountName string) (mfa.Enrollment, errs.ChatError)
+	ConfirmEnrollment(ctx context.Context, userId, code string) (mfa.RecoveryCodes, errs.ChatError)
+	IsEnabled(ctx context.Context, userId string) (bool, errs.ChatError)
+	CreateChallenge(ctx context.Context, userId string) (string, errs.ChatError)
+	VerifyChallenge(ctx context.Context, challenge, code string) (string, errs.ChatError)
+}
+
+type Repository interface {
+	GetMfa(ctx context.Context, userId string) (mfa.Mfa, errs.ChatError)
+	SaveMfa(ctx context.Context, m mfa.Mfa) errs.ChatError
+	ConfirmMfa(ctx context.Context, tx *sql.Tx, userId string, step int64) errs.ChatError
+	UseStep(ctx context.Context, userId string, step int64) errs.ChatError
+	ReplaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userId string, codeHashes []string) errs.ChatError
+	UseRecoveryCode(ctx context.Context, userId, codeHash string) errs.ChatError
+	GetDB() *sql.DB
+}
@@ -0,0 +1,25 @@
+package mfa
+
+// Mfa is the TOTP factor of a user. The secret is stored encrypted and the factor is
+// only enforced after the user confirmed the enrollment with a valid code.
+//
+// LastUsedStep is the last TOTP time step accepted, so a code cannot be replayed.
+type Mfa struct {
+	UserId          string
+	EncryptedSecret string
+	Confirmed       bool
+	LastUsedStep    int64
+}
+
+// Enrollment is returned when a user starts the enrollment. ProvisioningUri is the
+// 'otpauth://' URI that authenticator apps read from a QR code.
+type Enrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningUri string `json:"provisioning_uri"`
+}
+
+// RecoveryCodes are returned once, when the enrollment is confirmed. Each code can
+// replace a TOTP code a single time.
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,165 @@
+package mfa
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/raffops/chat_auth/internal/app/mfa"
+	mfaModel "github.com/raffops/chat_auth/internal/app/mfa/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func (p repository) GetDB() *sql.DB {
+	return p.db
+}
+
+// GetMfa fetches the TOTP factor of a user.
+// If the user never started an enrollment, the svcError is 'errs.ErrNotFound'.
+func (p repository) GetMfa(ctx context.Context, userId string) (mfaModel.Mfa, errs.ChatError) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("user_id", "encrypted_secret", "confirmed", "last_used_step").
+		From("public.user_mfa").
+		Where(sb.Equal("user_id", userId))
+	queryString, args := sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+
+	var m mfaModel.Mfa
+	err := p.db.QueryRowContext(ctx, queryString, args...).
+		Scan(&m.UserId, &m.EncryptedSecret, &m.Confirmed, &m.LastUsedStep)
+	if errors.Is(err, sql.ErrNoRows) {
+		return mfaModel.Mfa{}, errs.NewError(errs.ErrNotFound, fmt.Errorf("mfa of user %s not found", userId))
+	}
+	if err != nil {
+		return mfaModel.Mfa{}, errs.NewError(errs.ErrInternal, err)
+	}
+	return m, nil
+}
+
+// SaveMfa stores a new unconfirmed factor, replacing a previous unconfirmed one.
+// A confirmed factor is never replaced, and the svcError is 'errs.ErrConflict'.
+func (p repository) SaveMfa(ctx context.Context, m mfaModel.Mfa) errs.ChatError {
+	ib := sqlbuilder.NewInsertBuilder()
+	ib.InsertInto("public.user_mfa").
+		Cols("user_id", "encrypted_secret").
+		Values(m.UserId, m.EncryptedSecret)
+	ib.SQL(
+		"ON CONFLICT (user_id) DO UPDATE " +
+			"SET encrypted_secret = EXCLUDED.encrypted_secret, last_used_step = 0, updated_at = NOW() " +
+			"WHERE user_mfa.confirmed = FALSE",
+	)
+	queryString, args := ib.BuildWithFlavor(sqlbuilder.PostgreSQL)
+
+	result, err := p.db.ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return checkAffected(result, errs.ErrConflict, fmt.Errorf("mfa of user %s already enabled", m.UserId))
+}
+
+// ConfirmMfa enables the factor of a user, marking the step of the code that confirmed it as used.
+func (p repository) ConfirmMfa(ctx context.Context, tx *sql.Tx, userId string, step int64) errs.ChatError {
+	ub := sqlbuilder.NewUpdateBuilder()
+	ub.Update("public.user_mfa").
+		Set(
+			ub.Assign("confirmed", true),
+			ub.Assign("last_used_step", step),
+			ub.Assign("updated_at", sqlbuilder.Raw("NOW()")),
+		).
+		Where(ub.Equal("user_id", userId), ub.Equal("confirmed", false))
+	queryString, args := ub.BuildWithFlavor(sqlbuilder.PostgreSQL)
+
+	result, err := tx.ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return checkAffected(result, errs.ErrConflict, fmt.Errorf("mfa of user %s already enabled", userId))
+}
+
+// UseStep marks a TOTP time step as used. Steps are only accepted in increasing order,
+// otherwise the svcError is 'errs.ErrConflict'.
+func (p repository) UseStep(ctx context.Context, userId string, step int64) errs.ChatError {
+	ub := sqlbuilder.NewUpdateBuilder()
+	ub.Update("public.user_mfa").
+		Set(
+			ub.Assign("last_used_step", step),
+			ub.Assign("updated_at", sqlbuilder.Raw("NOW()")),
+		).
+		Where(ub.Equal("user_id", userId), ub.LessThan("last_used_step", step))
+	queryString, args := ub.BuildWithFlavor(sqlbuilder.PostgreSQL)
+
+	result, err := p.db.ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return checkAffected(result, errs.ErrConflict, errors.New("code already used"))
+}
+
+// ReplaceRecoveryCodes deletes the recovery codes of a user and stores the new ones.
+func (p repository) ReplaceRecoveryCodes(
+	ctx context.Context,
+	tx *sql.Tx,
+	userId string,
+	codeHashes []string,
+) errs.ChatError {
+	db := sqlbuilder.NewDeleteBuilder()
+	db.DeleteFrom("public.user_recovery_code").
+		Where(db.Equal("user_id", userId))
+	queryString, args := db.BuildWithFlavor(sqlbuilder.PostgreSQL)
+	_, err := tx.ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	ib := sqlbuilder.NewInsertBuilder()
+	ib.InsertInto("public.user_recovery_code").
+		Cols("user_id", "code_hash")
+	for _, codeHash := range codeHashes {
+		ib.Values(userId, codeHash)
+	}
+	queryString, args = ib.BuildWithFlavor(sqlbuilder.PostgreSQL)
+	_, err = tx.ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return nil
+}
+
+// UseRecoveryCode consumes a recovery code.
+// If the code does not exist or was already used, the svcError is 'errs.ErrNotFound'.
+func (p repository) UseRecoveryCode(ctx context.Context, userId, codeHash string) errs.ChatError {
+	ub := sqlbuilder.NewUpdateBuilder()
+	ub.Update("public.user_recovery_code").
+		Set(ub.Assign("used_at", sqlbuilder.Raw("NOW()"))).
+		Where(ub.Equal("user_id", userId), ub.Equal("code_hash", codeHash), ub.IsNull("used_at"))
+	queryString, args := ub.BuildWithFlavor(sqlbuilder.PostgreSQL)
+
+	result, err := p.db.ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return checkAffected(result, errs.ErrNotFound, errors.New("recovery code not found"))
+}
+
+func checkAffected(result sql.Result, svcError error, appError error) errs.ChatError {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	if affected == 0 {
+		return errs.NewError(svcError, appError)
+	}
+	return nil
+}
+
+func NewPostgresRepository(db *sql.DB) mfa.Repository {
+	return &repository{db: db}
+}
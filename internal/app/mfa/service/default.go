@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/raffops/chat_auth/internal/app/mfa"
+	mfaModels "github.com/raffops/chat_auth/internal/app/mfa/model"
+	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	"github.com/raffops/chat_commons/pkg/encryptor"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"github.com/raffops/chat_commons/pkg/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	challengeTimeout     = 5 * time.Minute
+	maxChallengeAttempts = 5
+	recoveryCodesCount   = 10
+	recoveryCodeLength   = 10
+)
+
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+var errInvalidCode = errors.New("invalid mfa code")
+
+type service struct {
+	repo          mfa.Repository
+	challengeRepo sessionManager.ReaderWriterRepository
+	encryptor     encryptor.Encryptor
+	secret        string
+	issuer        string
+}
+
+// Enroll generates a new TOTP secret for the user. The factor is only enabled after
+// ConfirmEnrollment, so an enrollment can be restarted until then.
+func (s service) Enroll(ctx context.Context, userId, accountName string) (mfaModels.Enrollment, errs.ChatError) {
+	secret, errGenerate := generateSecret()
+	if errGenerate != nil {
+		return mfaModels.Enrollment{}, errs.NewError(errs.ErrInternal, errGenerate)
+	}
+	encryptedSecret, errEncrypt := s.encryptor.Encrypt(secret, s.secret)
+	if errEncrypt != nil {
+		return mfaModels.Enrollment{}, errs.NewError(errs.ErrInternal, errEncrypt)
+	}
+
+	err := s.repo.SaveMfa(ctx, mfaModels.Mfa{UserId: userId, EncryptedSecret: encryptedSecret})
+	if err != nil {
+		return mfaModels.Enrollment{}, err
+	}
+	return mfaModels.Enrollment{
+		Secret:          secret,
+		ProvisioningUri: provisioningUri(s.issuer, accountName, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables the factor if the code is valid and returns the recovery codes.
+// The codes are only stored hashed, so this is the only time they are available.
+func (s service) ConfirmEnrollment(
+	ctx context.Context,
+	userId, code string,
+) (mfaModels.RecoveryCodes, errs.ChatError) {
+	m, err := s.repo.GetMfa(ctx, userId)
+	if err != nil {
+		return mfaModels.RecoveryCodes{}, err
+	}
+	if m.Confirmed {
+		return mfaModels.RecoveryCodes{}, errs.NewError(errs.ErrConflict, errors.New("mfa already enabled"))
+	}
+	step, err := s.validateTotp(m, code)
+	if err != nil {
+		return mfaModels.RecoveryCodes{}, err
+	}
+
+	codes, codeHashes, errGenerate := generateRecoveryCodes()
+	if errGenerate != nil {
+		return mfaModels.RecoveryCodes{}, errs.NewError(errs.ErrInternal, errGenerate)
+	}
+
+	tx, errTx := s.repo.GetDB().BeginTx(ctx, nil)
+	if errTx != nil {
+		return mfaModels.RecoveryCodes{}, errs.NewError(errs.ErrInternal, errTx)
+	}
+	defer tx.Rollback()
+
+	err = s.repo.ConfirmMfa(ctx, tx, userId, step)
+	if err != nil {
+		return mfaModels.RecoveryCodes{}, err
+	}
+	err = s.repo.ReplaceRecoveryCodes(ctx, tx, userId, codeHashes)
+	if err != nil {
+		return mfaModels.RecoveryCodes{}, err
+	}
+	if errCommit := tx.Commit(); errCommit != nil {
+		return mfaModels.RecoveryCodes{}, errs.NewError(errs.ErrInternal, errCommit)
+	}
+	return mfaModels.RecoveryCodes{Codes: codes}, nil
+}
+
+func (s service) IsEnabled(ctx context.Context, userId string) (bool, errs.ChatError) {
+	m, err := s.repo.GetMfa(ctx, userId)
+	if err != nil && errors.Is(err.SvcError(), errs.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return m.Confirmed, nil
+}
+
+// CreateChallenge stores a pending login of the user, which is completed by VerifyChallenge.
+func (s service) CreateChallenge(ctx context.Context, userId string) (string, errs.ChatError) {
+	challenge := uuid.GenerateUUID()
+	expiresAt := time.Now().Add(challengeTimeout)
+
+	tx, err := s.challengeRepo.BeginTransaction(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer s.challengeRepo.RollbackTransaction(ctx, tx)
+
+	err = s.challengeRepo.HashSetEncrypted(ctx, tx, "mfa_challenge", challenge, s.secret, map[string]interface{}{
+		"user_id": userId,
+	})
+	if err != nil {
+		return "", err
+	}
+	err = s.challengeRepo.ExpireAt(ctx, tx, "mfa_challenge", challenge, expiresAt)
+	if err != nil {
+		return "", err
+	}
+	return challenge, s.challengeRepo.CommitTransaction(ctx, tx)
+}
+
+// VerifyChallenge completes a pending login with a TOTP or a recovery code and returns the
+// id of the user. The challenge is dropped once it is used or after too many invalid codes.
+//
+// Each code takes an attempt before it is verified, with an atomic increment of the
+// 'attempts' field of the challenge, so concurrent guesses cannot exceed the limit.
+func (s service) VerifyChallenge(ctx context.Context, challenge, code string) (string, errs.ChatError) {
+	stored, err := s.challengeRepo.HashGetEncrypted(ctx, "mfa_challenge", challenge, s.secret)
+	if err != nil {
+		return "", errs.NewError(errs.ErrNotAuthenticated, errors.New("invalid mfa challenge"))
+	}
+	userId, ok := stored["user_id"].(string)
+	if !ok {
+		return "", errs.NewError(errs.ErrInternal, fmt.Errorf("corrupted mfa challenge"))
+	}
+	attempts, err := s.challengeRepo.HashIncrement(ctx, "mfa_challenge", challenge, "attempts")
+	if err != nil && errors.Is(err.SvcError(), errs.ErrNotFound) {
+		return "", errs.NewError(errs.ErrNotAuthenticated, errors.New("invalid mfa challenge"))
+	}
+	if err != nil {
+		return "", err
+	}
+	if attempts > maxChallengeAttempts {
+		return "", errs.NewError(errs.ErrNotAuthenticated, errors.New("invalid mfa challenge"))
+	}
+
+	errVerify := s.verifyCode(ctx, userId, code)
+	if errVerify != nil && !errors.Is(errVerify.SvcError(), errs.ErrNotAuthenticated) {
+		return "", errVerify
+	}
+	if errVerify != nil {
+		if attempts == maxChallengeAttempts {
+			logger.Info("mfa challenge dropped after too many attempts", zap.String("user_id", userId))
+			if err := s.deleteChallenge(ctx, challenge); err != nil {
+				return "", err
+			}
+		}
+		return "", errVerify
+	}
+
+	if err := s.deleteChallenge(ctx, challenge); err != nil {
+		return "", err
+	}
+	return userId, nil
+}
+
+func (s service) verifyCode(ctx context.Context, userId, code string) errs.ChatError {
+	if len(code) != totpDigits {
+		err := s.repo.UseRecoveryCode(ctx, userId, hashRecoveryCode(code))
+		if err != nil && errors.Is(err.SvcError(), errs.ErrNotFound) {
+			return errs.NewError(errs.ErrNotAuthenticated, errInvalidCode)
+		}
+		if err == nil {
+			logger.Info("mfa recovery code used", zap.String("user_id", userId))
+		}
+		return err
+	}
+
+	m, err := s.repo.GetMfa(ctx, userId)
+	if err != nil {
+		return err
+	}
+	step, err := s.validateTotp(m, code)
+	if err != nil {
+		return err
+	}
+	err = s.repo.UseStep(ctx, userId, step)
+	if err != nil && errors.Is(err.SvcError(), errs.ErrConflict) {
+		return errs.NewError(errs.ErrNotAuthenticated, errInvalidCode)
+	}
+	return err
+}
+
+// validateTotp returns the step matched by the code. Steps already used are rejected,
+// so a code cannot be replayed inside its validity window.
+func (s service) validateTotp(m mfaModels.Mfa, code string) (int64, errs.ChatError) {
+	secret, errDecrypt := s.encryptor.Decrypt(m.EncryptedSecret, s.secret)
+	if errDecrypt != nil {
+		return 0, errs.NewError(errs.ErrInternal, errDecrypt)
+	}
+	step, ok := validateTotp(secret, code, time.Now())
+	if !ok || step <= m.LastUsedStep {
+		return 0, errs.NewError(errs.ErrNotAuthenticated, errInvalidCode)
+	}
+	return step, nil
+}
+
+func (s service) deleteChallenge(ctx context.Context, challenge string) errs.ChatError {
+	tx, err := s.challengeRepo.BeginTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.challengeRepo.RollbackTransaction(ctx, tx)
+
+	err = s.challengeRepo.Delete(ctx, tx, "mfa_challenge", challenge)
+	if err != nil {
+		return err
+	}
+	return s.challengeRepo.CommitTransaction(ctx, tx)
+}
+
+// generateRecoveryCodes returns the codes, formatted as 'xxxxx-xxxxx', and their hashes.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	codeHashes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		b := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		for j := range b {
+			b[j] = recoveryCodeAlphabet[int(b[j])%len(recoveryCodeAlphabet)]
+		}
+		code := string(b[:recoveryCodeLength/2]) + "-" + string(b[recoveryCodeLength/2:])
+		codes = append(codes, code)
+		codeHashes = append(codeHashes, hashRecoveryCode(code))
+	}
+	return codes, codeHashes, nil
+}
+
+// hashRecoveryCode normalizes the code before hashing it. The codes are random, so a
+// fast hash is enough, as for the refresh tokens.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	hash := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hash[:])
+}
+
+func sanityCheck() {
+	envVariables := []string{
+		"MFA_ENCRYPTION_SECRET",
+		"MFA_ISSUER",
+	}
+	for _, envVariable := range envVariables {
+		if _, ok := os.LookupEnv(envVariable); !ok {
+			logger.Fatal("Environment variable not set", zap.String("variable", envVariable))
+		}
+	}
+}
+
+func NewDefaultService(
+	repo mfa.Repository,
+	challengeRepo sessionManager.ReaderWriterRepository,
+	encryptor encryptor.Encryptor,
+	secret string,
+	issuer string,
+) mfa.Service {
+	sanityCheck()
+	return &service{
+		repo:          repo,
+		challengeRepo: challengeRepo,
+		encryptor:     encryptor,
+		secret:        secret,
+		issuer:        issuer,
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	mfaModels "github.com/raffops/chat_auth/internal/app/mfa/model"
+	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	sessionRepository "github.com/raffops/chat_auth/internal/app/sessionManager/repository"
+	mfaMock "github.com/raffops/chat_auth/test/mocks/mfa"
+	"github.com/raffops/chat_commons/pkg/encryptor"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestService_VerifyChallengeLimitsConcurrentAttempts(t *testing.T) {
+	const secret = "7CIuQStxETYG3x0qVO7TcZF7vUNnKlMz"
+	ctx := context.Background()
+	defaultEncryptor := encryptor.NewDefaultEncryptor()
+	encryptedSecret, errEncrypt := defaultEncryptor.Encrypt(rfcSecret, secret)
+	if errEncrypt != nil {
+		t.Fatalf("Encrypt() error = %v", errEncrypt)
+	}
+	var verified atomic.Int32
+	repo := mfaMock.NewRepository(t)
+	repo.EXPECT().GetMfa(mock.Anything, "1").RunAndReturn(
+		func(ctx context.Context, userId string) (mfaModels.Mfa, errs.ChatError) {
+			verified.Add(1)
+			return mfaModels.Mfa{UserId: userId, EncryptedSecret: encryptedSecret, Confirmed: true}, nil
+		},
+	).Maybe()
+
+	const guesses = 4 * maxChallengeAttempts
+	challengeRepo := sessionRepository.NewMemoryRepository(defaultEncryptor)
+	barrierRepo := &readBarrierRepository{ReaderWriterRepository: challengeRepo}
+	barrierRepo.reads.Add(guesses)
+	s := service{repo: repo, challengeRepo: challengeRepo, encryptor: defaultEncryptor, secret: secret}
+	challenge, err := s.CreateChallenge(ctx, "1")
+	if err != nil {
+		t.Fatalf("CreateChallenge() error = %v", err)
+	}
+
+	concurrent := s
+	concurrent.challengeRepo = barrierRepo
+	var wg sync.WaitGroup
+	for range guesses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, errVerify := concurrent.VerifyChallenge(ctx, challenge, "000000"); errVerify == nil {
+				t.Errorf("VerifyChallenge() with an invalid code got nil error")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := verified.Load(); got > maxChallengeAttempts {
+		t.Errorf("VerifyChallenge() verified %d codes, want at most %d", got, maxChallengeAttempts)
+	}
+	if _, err = s.VerifyChallenge(ctx, challenge, "000000"); err == nil {
+		t.Errorf("VerifyChallenge() after too many attempts got nil error")
+	}
+	if got := verified.Load(); got > maxChallengeAttempts {
+		t.Errorf("VerifyChallenge() verified a code after the challenge was dropped")
+	}
+}
+
+// readBarrierRepository holds the encrypted reads until every read added to reads is made,
+// so concurrent callers all read the challenge before any of them writes.
+type readBarrierRepository struct {
+	sessionManager.ReaderWriterRepository
+	reads sync.WaitGroup
+}
+
+func (r *readBarrierRepository) HashGetEncrypted(
+	ctx context.Context,
+	tableName, key, secret string,
+) (map[string]interface{}, errs.ChatError) {
+	values, err := r.ReaderWriterRepository.HashGetEncrypted(ctx, tableName, key, secret)
+	r.reads.Done()
+	r.reads.Wait()
+	return values, err
+}
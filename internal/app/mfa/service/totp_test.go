@@ -0,0 +1,91 @@
+package service
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed of the RFC 6238 test vectors, "12345678901234567890".
+var rfcSecret = secretEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTotpCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to 6 digits
+	tests := []struct {
+		name string
+		time int64
+		want string
+	}{
+		{name: "Test time 59", time: 59, want: "287082"},
+		{name: "Test time 1111111109", time: 1111111109, want: "081804"},
+		{name: "Test time 1111111111", time: 1111111111, want: "050471"},
+		{name: "Test time 1234567890", time: 1234567890, want: "005924"},
+		{name: "Test time 2000000000", time: 2000000000, want: "279037"},
+		{name: "Test time 20000000000", time: 20000000000, want: "353130"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := totpCode([]byte("12345678901234567890"), timeStep(time.Unix(tt.time, 0)))
+			if got != tt.want {
+				t.Errorf("totpCode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTotp(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	tests := []struct {
+		name     string
+		code     string
+		at       time.Time
+		wantStep int64
+		wantOk   bool
+	}{
+		{name: "Test current step", code: "050471", at: now, wantStep: timeStep(now), wantOk: true},
+		{name: "Test previous step", code: "050471", at: now.Add(totpPeriod), wantStep: timeStep(now), wantOk: true},
+		{name: "Test next step", code: "050471", at: now.Add(-totpPeriod), wantStep: timeStep(now), wantOk: true},
+		{name: "Test step out of skew", code: "050471", at: now.Add(2 * totpPeriod), wantOk: false},
+		{name: "Test wrong code", code: "123456", at: now, wantOk: false},
+		{name: "Test malformed code", code: "0504", at: now, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOk := validateTotp(rfcSecret, tt.code, tt.at)
+			if gotOk != tt.wantOk || (tt.wantOk && gotStep != tt.wantStep) {
+				t.Errorf("validateTotp() got = %v, %v, want %v, %v", gotStep, gotOk, tt.wantStep, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestProvisioningUri(t *testing.T) {
+	got, err := url.Parse(provisioningUri("chat_auth", "john", rfcSecret))
+	if err != nil {
+		t.Fatalf("provisioningUri() error = %v", err)
+	}
+	if got.Scheme != "otpauth" || got.Host != "totp" || got.Path != "/chat_auth:john" {
+		t.Errorf("provisioningUri() got = %v", got)
+	}
+	if got.Query().Get("secret") != rfcSecret || got.Query().Get("issuer") != "chat_auth" {
+		t.Errorf("provisioningUri() got query = %v", got.Query())
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	codes, codeHashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes() error = %v", err)
+	}
+	if len(codes) != recoveryCodesCount || len(codeHashes) != recoveryCodesCount {
+		t.Fatalf("generateRecoveryCodes() got %d codes, want %d", len(codes), recoveryCodesCount)
+	}
+	for i, code := range codes {
+		if len(code) == totpDigits {
+			t.Errorf("generateRecoveryCodes() got code %v with the length of a TOTP code", code)
+		}
+		if hashRecoveryCode(" "+code[:5]+code[6:]+" ") != codeHashes[i] {
+			t.Errorf("hashRecoveryCode() does not normalize %v", code)
+		}
+	}
+}
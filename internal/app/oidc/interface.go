@@ -0,0 +1,20 @@
+package oidc
+
+import (
+	"context"
+
+	oidcModels "github.com/raffops/chat_auth/internal/app/oidc/model"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+type Provider interface {
+	Name() string
+	AuthCodeUrl(request oidcModels.AuthRequest) string
+	Exchange(
+		ctx context.Context,
+		code string,
+		request oidcModels.AuthRequest,
+	) (userModels.User, userModels.Identity, errs.ChatError)
+	Verify(ctx context.Context, idToken, nonce string) (oidcModels.Claims, errs.ChatError)
+}
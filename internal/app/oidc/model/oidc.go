@@ -0,0 +1,133 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+)
+
+// Config is an OpenID Connect client of a provider, found through its discovery document.
+// Users in any of the AdminGroups sign up with the admin role.
+type Config struct {
+	DiscoveryUrl string
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
+	Scopes       []string
+	AdminGroups  []string
+}
+
+// Metadata is the part of the discovery document used by the client, as described in
+// OpenID Connect Discovery 1.0.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+// TokenResponse is the response of the token endpoint, or its error.
+type TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IdToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Audience is the 'aud' claim, either a single client id or a list of them.
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (a Audience) Contains(clientId string) bool {
+	return slices.Contains(a, clientId)
+}
+
+// Claims are the claims of an ID token. EmailVerified is nil when the provider does not send it.
+type Claims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          Audience `json:"aud"`
+	AuthorizedParty   string   `json:"azp,omitempty"`
+	ExpiresAt         int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	Nonce             string   `json:"nonce,omitempty"`
+	Email             string   `json:"email,omitempty"`
+	EmailVerified     *bool    `json:"email_verified,omitempty"`
+	PreferredUsername string   `json:"preferred_username,omitempty"`
+	Groups            []string `json:"groups,omitempty"`
+}
+
+func (c Claims) Expired(now time.Time, leeway time.Duration) bool {
+	return !now.Add(-leeway).Before(time.Unix(c.ExpiresAt, 0))
+}
+
+// User maps the claims to a user of the auth type. The role is admin when the user is in
+// any of the adminGroups.
+func (c Claims) User(authType userModels.AuthTypeId, adminGroups []string) userModels.User {
+	role := authModels.RoleUser
+	for _, group := range c.Groups {
+		if slices.Contains(adminGroups, group) {
+			role = authModels.RoleAdmin
+			break
+		}
+	}
+	return userModels.User{
+		Username: c.PreferredUsername,
+		Email:    c.Email,
+		AuthType: authType,
+		Role:     role,
+		Status:   userModels.StatusActive,
+	}
+}
+
+// Identity is the identity of the user at the provider, found by the subject of the claims.
+func (c Claims) Identity(provider string) userModels.Identity {
+	return userModels.Identity{Provider: provider, Subject: c.Subject, Email: c.Email}
+}
+
+// AuthRequest holds the values of an authorization request, kept by the client until the
+// callback. The state protects the callback, the nonce binds the ID token to the request and
+// the code verifier proves the code was requested by this client, as described in RFC 7636.
+type AuthRequest struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+func NewAuthRequest() (AuthRequest, error) {
+	values := make([]string, 3)
+	for i := range values {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return AuthRequest{}, err
+		}
+		values[i] = base64.RawURLEncoding.EncodeToString(b)
+	}
+	return AuthRequest{State: values[0], Nonce: values[1], CodeVerifier: values[2]}, nil
+}
+
+// CodeChallenge is the 'S256' code challenge of the code verifier.
+func CodeChallenge(codeVerifier string) string {
+	hash := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
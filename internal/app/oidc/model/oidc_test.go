@@ -0,0 +1,93 @@
+package oidc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+)
+
+func TestAudience_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Audience
+		wantErr bool
+	}{
+		{name: "Test single audience", data: `"chat"`, want: Audience{"chat"}},
+		{name: "Test audience list", data: `["chat", "other"]`, want: Audience{"chat", "other"}},
+		{name: "Test invalid audience", data: `1`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Audience
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalJSON() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaims_User(t *testing.T) {
+	claims := Claims{Email: "john@doe", PreferredUsername: "john", Groups: []string{"dev", "chat-admins"}}
+	tests := []struct {
+		name        string
+		adminGroups []string
+		wantRole    authModels.RoleId
+	}{
+		{name: "Test admin group", adminGroups: []string{"chat-admins"}, wantRole: authModels.RoleAdmin},
+		{name: "Test no admin group", adminGroups: []string{"ops"}, wantRole: authModels.RoleUser},
+		{name: "Test no admin groups configured", wantRole: authModels.RoleUser},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := claims.User(userModels.AuthTypeId(4), tt.adminGroups)
+			want := userModels.User{
+				Username: "john",
+				Email:    "john@doe",
+				AuthType: userModels.AuthTypeId(4),
+				Role:     tt.wantRole,
+				Status:   userModels.StatusActive,
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("User() got = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestClaims_Expired(t *testing.T) {
+	now := time.Unix(1000, 0)
+	claims := Claims{ExpiresAt: 1000}
+	if !claims.Expired(now, 0) {
+		t.Errorf("Expired() got = false, want true at the expiration")
+	}
+	if claims.Expired(now, time.Second) {
+		t.Errorf("Expired() got = true, want false within the leeway")
+	}
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// example of RFC 7636, appendix B
+	got := CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("CodeChallenge() got = %v, want %v", got, want)
+	}
+}
+
+func TestNewAuthRequest(t *testing.T) {
+	got, err := NewAuthRequest()
+	if err != nil {
+		t.Fatalf("NewAuthRequest() error = %v", err)
+	}
+	if len(got.CodeVerifier) != 43 || got.State == got.Nonce || got.Nonce == got.CodeVerifier {
+		t.Errorf("NewAuthRequest() got = %v, want 3 distinct values of 43 characters", got)
+	}
+}
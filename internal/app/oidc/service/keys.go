@@ -0,0 +1,94 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	tokenModels "github.com/raffops/chat_auth/internal/app/token/model"
+)
+
+// publicKey is a key of the provider with the algorithm it signs the ID tokens with.
+type publicKey struct {
+	algorithm string
+	key       crypto.PublicKey
+}
+
+func (k publicKey) verify(signingInput, signature []byte) bool {
+	switch key := k.key.(type) {
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(signingInput)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		// the signature is the concatenation of r and s, as described in RFC 7518
+		if len(signature) != 64 {
+			return false
+		}
+		digest := sha256.Sum256(signingInput)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(key, digest[:], r, s)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, signingInput, signature)
+	default:
+		return false
+	}
+}
+
+// parseKey parses a JWK of the provider, as described in RFC 7517. Keys without an algorithm
+// are used with the algorithm of their type, 'RS256', 'ES256' or 'EdDSA'.
+func parseKey(data []byte) (string, publicKey, error) {
+	var jwk tokenModels.JWK
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return "", publicKey{}, err
+	}
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return "", publicKey{}, fmt.Errorf("key %s is not a signing key", jwk.KeyId)
+	}
+
+	var key publicKey
+	switch jwk.KeyType {
+	case "RSA":
+		n, errN := encoding.DecodeString(jwk.N)
+		e, errE := encoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			return "", publicKey{}, fmt.Errorf("malformed rsa key %s", jwk.KeyId)
+		}
+		key = publicKey{
+			algorithm: tokenModels.AlgorithmRS256,
+			key:       &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())},
+		}
+	case "EC":
+		if jwk.Curve != "P-256" {
+			return "", publicKey{}, fmt.Errorf("unsupported curve %s of key %s", jwk.Curve, jwk.KeyId)
+		}
+		x, errX := encoding.DecodeString(jwk.X)
+		y, errY := encoding.DecodeString(jwk.Y)
+		if errX != nil || errY != nil {
+			return "", publicKey{}, fmt.Errorf("malformed ec key %s", jwk.KeyId)
+		}
+		ecKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !ecKey.Curve.IsOnCurve(ecKey.X, ecKey.Y) {
+			return "", publicKey{}, fmt.Errorf("malformed ec key %s", jwk.KeyId)
+		}
+		key = publicKey{algorithm: tokenModels.AlgorithmES256, key: ecKey}
+	case "OKP":
+		x, err := encoding.DecodeString(jwk.X)
+		if jwk.Curve != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return "", publicKey{}, fmt.Errorf("malformed okp key %s", jwk.KeyId)
+		}
+		key = publicKey{algorithm: tokenModels.AlgorithmEdDSA, key: ed25519.PublicKey(x)}
+	default:
+		return "", publicKey{}, fmt.Errorf("unsupported key type %s of key %s", jwk.KeyType, jwk.KeyId)
+	}
+	if jwk.Algorithm != "" && jwk.Algorithm != key.algorithm {
+		return "", publicKey{}, fmt.Errorf("unsupported algorithm %s of key %s", jwk.Algorithm, jwk.KeyId)
+	}
+	return jwk.KeyId, key, nil
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raffops/chat_auth/internal/app/oidc"
+	oidcModels "github.com/raffops/chat_auth/internal/app/oidc/model"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+	// clockSkew is tolerated between the provider and the service on the expiration of the ID tokens.
+	clockSkew = time.Minute
+	// keysRefreshInterval limits how often the keys are fetched again for unknown key ids.
+	keysRefreshInterval = time.Minute
+)
+
+var encoding = base64.RawURLEncoding
+
+type provider struct {
+	name     string
+	config   oidcModels.Config
+	metadata oidcModels.Metadata
+	client   *http.Client
+
+	mu   sync.RWMutex
+	keys map[string]publicKey
+	// keysMissedAt is when the keys were last fetched without finding a key id
+	keysMissedAt time.Time
+}
+
+func (p *provider) Name() string {
+	return p.name
+}
+
+// AuthCodeUrl is the authorization endpoint the user is redirected to, for the authorization
+// code flow with the 'S256' code challenge of the request.
+func (p *provider) AuthCodeUrl(request oidcModels.AuthRequest) string {
+	scopes := append([]string{"openid"}, p.config.Scopes...)
+	values := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientId},
+		"redirect_uri":          {p.config.RedirectUrl},
+		"scope":                 {strings.Join(uniqueScopes(scopes), " ")},
+		"state":                 {request.State},
+		"nonce":                 {request.Nonce},
+		"code_challenge":        {oidcModels.CodeChallenge(request.CodeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.metadata.AuthorizationEndpoint + separator + values.Encode()
+}
+
+// Exchange redeems the code for the tokens of the user, verifies the ID token and maps its
+// claims to a user and its identity at the provider. A user whose email is not verified by the
+// provider is not authenticated.
+func (p *provider) Exchange(
+	ctx context.Context,
+	code string,
+	request oidcModels.AuthRequest,
+) (userModels.User, userModels.Identity, errs.ChatError) {
+	values := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectUrl},
+		"code_verifier": {request.CodeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.TokenEndpoint,
+		strings.NewReader(values.Encode()),
+	)
+	if err != nil {
+		return userModels.User{}, userModels.Identity{}, errs.NewError(errs.ErrInternal, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientId), url.QueryEscape(p.config.ClientSecret))
+
+	var tokenResponse oidcModels.TokenResponse
+	status, errRequest := doRequest(p.client, req, &tokenResponse)
+	if errRequest != nil {
+		return userModels.User{}, userModels.Identity{}, errRequest
+	}
+	if status != http.StatusOK || tokenResponse.Error != "" {
+		return userModels.User{}, userModels.Identity{}, errs.NewError(errs.ErrNotAuthenticated,
+			fmt.Errorf("token request failed with status %d: %s %s",
+				status, tokenResponse.Error, tokenResponse.ErrorDescription,
+			),
+		)
+	}
+	if tokenResponse.IdToken == "" {
+		return userModels.User{}, userModels.Identity{}, errs.NewError(errs.ErrNotAuthenticated,
+			errors.New("id token not found"),
+		)
+	}
+
+	claims, errVerify := p.Verify(ctx, tokenResponse.IdToken, request.Nonce)
+	if errVerify != nil {
+		return userModels.User{}, userModels.Identity{}, errVerify
+	}
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		return userModels.User{}, userModels.Identity{}, errs.NewError(errs.ErrNotAuthenticated,
+			errors.New("email not verified"),
+		)
+	}
+	authType, ok := userModels.MapAuthTypeString[p.name]
+	if !ok {
+		return userModels.User{}, userModels.Identity{}, errs.NewError(errs.ErrInternal,
+			fmt.Errorf("auth type %s not found", p.name),
+		)
+	}
+	return claims.User(authType, p.config.AdminGroups), claims.Identity(p.name), nil
+}
+
+// Verify checks the signature of the ID token against the keys of the provider, its issuer,
+// audience, expiration and nonce, and returns its claims.
+// Any failure is reported as 'errs.ErrNotAuthenticated'.
+func (p *provider) Verify(ctx context.Context, idToken, nonce string) (oidcModels.Claims, errs.ChatError) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return oidcModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated, errors.New("malformed id token"))
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyId     string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return oidcModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated, err)
+	}
+	key, errKey := p.getKey(ctx, header.KeyId)
+	if errKey != nil {
+		return oidcModels.Claims{}, errKey
+	}
+	if header.Algorithm != key.algorithm {
+		return oidcModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated,
+			fmt.Errorf("unexpected signing algorithm %s", header.Algorithm),
+		)
+	}
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return oidcModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated, errors.New("malformed signature"))
+	}
+	if !key.verify([]byte(parts[0]+"."+parts[1]), signature) {
+		return oidcModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated, errors.New("invalid signature"))
+	}
+
+	var claims oidcModels.Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return oidcModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated, err)
+	}
+	if err := p.validate(claims, nonce); err != nil {
+		return oidcModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated, err)
+	}
+	return claims, nil
+}
+
+func (p *provider) validate(claims oidcModels.Claims, nonce string) error {
+	if claims.Issuer != p.metadata.Issuer {
+		return fmt.Errorf("unexpected issuer %s", claims.Issuer)
+	}
+	if claims.Subject == "" {
+		return errors.New("subject not found")
+	}
+	if !claims.Audience.Contains(p.config.ClientId) {
+		return errors.New("id token issued to another client")
+	}
+	// the authorized party is required to trust a token issued to several clients
+	if (len(claims.Audience) > 1 || claims.AuthorizedParty != "") && claims.AuthorizedParty != p.config.ClientId {
+		return errors.New("id token authorized to another client")
+	}
+	now := time.Now()
+	if claims.ExpiresAt == 0 || claims.Expired(now, clockSkew) {
+		return errors.New("id token expired")
+	}
+	if claims.IssuedAt > now.Add(clockSkew).Unix() {
+		return errors.New("id token issued in the future")
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return errors.New("invalid nonce")
+	}
+	return nil
+}
+
+// getKey returns the key of the key id, fetching the keys again when it is unknown, since the
+// provider may have rotated them. Tokens without a key id are accepted when there is a single key.
+func (p *provider) getKey(ctx context.Context, kid string) (publicKey, errs.ChatError) {
+	p.mu.RLock()
+	key, ok := findKey(p.keys, kid)
+	missedAt := p.keysMissedAt
+	p.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+	if time.Since(missedAt) < keysRefreshInterval {
+		return publicKey{}, errs.NewError(errs.ErrNotAuthenticated, fmt.Errorf("key %s not found", kid))
+	}
+
+	if err := p.fetchKeys(ctx); err != nil {
+		return publicKey{}, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key, ok = findKey(p.keys, kid)
+	if !ok {
+		p.keysMissedAt = time.Now()
+		return publicKey{}, errs.NewError(errs.ErrNotAuthenticated, fmt.Errorf("key %s not found", kid))
+	}
+	return key, nil
+}
+
+func findKey(keys map[string]publicKey, kid string) (publicKey, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+	key, ok := keys[kid]
+	return key, ok
+}
+
+// fetchKeys replaces the keys with the ones published by the provider. Keys that cannot
+// verify signatures, like encryption keys or unsupported ones, are skipped.
+func (p *provider) fetchKeys(ctx context.Context) errs.ChatError {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.metadata.JwksUri, nil)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	var jwks struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	status, errRequest := doRequest(p.client, req, &jwks)
+	if errRequest != nil {
+		return errRequest
+	}
+	if status != http.StatusOK {
+		return errs.NewError(errs.ErrInternal, fmt.Errorf("keys request failed with status %d", status))
+	}
+
+	keys := make(map[string]publicKey, len(jwks.Keys))
+	for _, rawKey := range jwks.Keys {
+		kid, key, err := parseKey(rawKey)
+		if err != nil {
+			logger.Debug("skipping provider key", zap.String("provider", p.name), zap.Error(err))
+			continue
+		}
+		keys[kid] = key
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = keys
+	return nil
+}
+
+// doRequest sends the request and decodes the JSON response, of any status, into v.
+func doRequest(client *http.Client, req *http.Request, v interface{}) (int, errs.ChatError) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, errs.NewError(errs.ErrInternal, err)
+	}
+	defer func(body io.ReadCloser) {
+		err := body.Close()
+		if err != nil {
+			logger.Debug("error closing response body", zap.Error(err))
+		}
+	}(resp.Body)
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		if resp.StatusCode >= http.StatusBadRequest {
+			return resp.StatusCode, errs.NewError(errs.ErrInternal,
+				fmt.Errorf("request to %s failed with status %d", req.URL.Path, resp.StatusCode),
+			)
+		}
+		return resp.StatusCode, errs.NewError(errs.ErrInternal,
+			fmt.Errorf("cannot decode response of %s: %w", req.URL.Path, err),
+		)
+	}
+	return resp.StatusCode, nil
+}
+
+func discover(ctx context.Context, client *http.Client, discoveryUrl string) (oidcModels.Metadata, errs.ChatError) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryUrl, nil)
+	if err != nil {
+		return oidcModels.Metadata{}, errs.NewError(errs.ErrInternal, err)
+	}
+	var metadata oidcModels.Metadata
+	status, errRequest := doRequest(client, req, &metadata)
+	if errRequest != nil {
+		return oidcModels.Metadata{}, errRequest
+	}
+	if status != http.StatusOK {
+		return oidcModels.Metadata{}, errs.NewError(errs.ErrInternal,
+			fmt.Errorf("discovery request failed with status %d", status),
+		)
+	}
+	// the issuer must be the one the discovery document was requested from
+	if strings.TrimSuffix(metadata.Issuer, "/")+discoveryPath != discoveryUrl {
+		return oidcModels.Metadata{}, errs.NewError(errs.ErrInternal,
+			fmt.Errorf("issuer %s does not match the discovery url %s", metadata.Issuer, discoveryUrl),
+		)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JwksUri == "" {
+		return oidcModels.Metadata{}, errs.NewError(errs.ErrInternal,
+			fmt.Errorf("endpoints not found in the discovery document of %s", metadata.Issuer),
+		)
+	}
+	return metadata, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	decoded, err := encoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("malformed id token segment")
+	}
+	if err := json.Unmarshal(decoded, v); err != nil {
+		return errors.New("malformed id token segment")
+	}
+	return nil
+}
+
+func uniqueScopes(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	output := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			output = append(output, scope)
+		}
+	}
+	return output
+}
+
+// NewProvider creates the client of an OpenID Connect provider. It fetches the discovery
+// document and the keys of the provider, so the provider must be reachable.
+func NewProvider(
+	ctx context.Context,
+	name string,
+	config oidcModels.Config,
+	client *http.Client,
+) (oidc.Provider, errs.ChatError) {
+	metadata, err := discover(ctx, client, config.DiscoveryUrl)
+	if err != nil {
+		return nil, err
+	}
+	p := &provider{
+		name:     name,
+		config:   config,
+		metadata: metadata,
+		client:   client,
+	}
+	if err := p.fetchKeys(ctx); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,18 @@
+package purge
+
+import (
+	"context"
+	"time"
+
+	purge "github.com/raffops/chat_auth/internal/app/purge/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+type Repository interface {
+	PurgeUsers(ctx context.Context, mode purge.Mode, deletedBefore time.Time, limit int) ([]purge.Entry, errs.ChatError)
+}
+
+type Service interface {
+	Purge(ctx context.Context) (int, errs.ChatError)
+	StartPurging(ctx context.Context, interval time.Duration)
+}
@@ -0,0 +1,24 @@
+package purge
+
+import "time"
+
+// Mode is how the deleted users are purged. 'delete' removes the rows, while
+// 'anonymize' keeps them with the personal data scrubbed.
+type Mode string
+
+const (
+	ModeDelete    Mode = "delete"
+	ModeAnonymize Mode = "anonymize"
+)
+
+var MapModeString = map[string]Mode{
+	"delete":    ModeDelete,
+	"anonymize": ModeAnonymize,
+}
+
+// Entry is the audit record of a purged user.
+type Entry struct {
+	UserId    string
+	Mode      Mode
+	DeletedAt time.Time
+}
@@ -0,0 +1,192 @@
+package purge
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/raffops/chat_auth/internal/app/purge"
+	purgeModel "github.com/raffops/chat_auth/internal/app/purge/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// purgeLockId is the key of the Postgres advisory lock held while purging, so a
+// single replica purges at a time.
+const purgeLockId int64 = 7_301_220_912
+
+type query struct {
+	queryString string
+	args        []interface{}
+}
+
+func newQuery(queryString string, args []interface{}) query {
+	return query{queryString: queryString, args: args}
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+// PurgeUsers purges up to limit users deleted before deletedBefore, and records an
+// audit entry for each of them, in a single transaction.
+//
+// If another replica holds the purge lock, nothing is purged and the svcError is
+// 'errs.ErrConflict'.
+func (p repository) PurgeUsers(
+	ctx context.Context,
+	mode purgeModel.Mode,
+	deletedBefore time.Time,
+	limit int,
+) ([]purgeModel.Entry, errs.ChatError) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	err = tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", purgeLockId).Scan(&locked)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	if !locked {
+		return nil, errs.NewError(errs.ErrConflict, errors.New("another purge is running"))
+	}
+
+	entries, chatErr := p.getCandidates(ctx, tx, mode, deletedBefore, limit)
+	if chatErr != nil || len(entries) == 0 {
+		return nil, chatErr
+	}
+
+	for _, q := range purgeQueries(mode, entries) {
+		if _, err = tx.ExecContext(ctx, q.queryString, q.args...); err != nil {
+			return nil, errs.NewError(errs.ErrInternal, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	return entries, nil
+}
+
+// purgeQueries returns the queries that purge the entries, followed by their audit. The
+// anonymize mode keeps the user row, so the rows linked to it that identify the user, like
+// its logins, MFA factors and provider identities, are deleted.
+func purgeQueries(mode purgeModel.Mode, entries []purgeModel.Entry) []query {
+	queries := []query{
+		newQuery(BuildDeleteUserRowsQuery("public.user_login", entries)),
+		newQuery(BuildDeleteUserRowsQuery("public.user_recovery_code", entries)),
+		newQuery(BuildDeleteUserRowsQuery("public.user_mfa", entries)),
+		newQuery(BuildDeleteUserRowsQuery("public.user_identity", entries)),
+		newQuery(BuildAnonymizeQuery(entries)),
+	}
+	if mode == purgeModel.ModeDelete {
+		queries = []query{newQuery(BuildHardDeleteQuery(entries))}
+	}
+	return append(queries, newQuery(BuildAuditQuery(entries)))
+}
+
+func (p repository) getCandidates(
+	ctx context.Context,
+	tx *sql.Tx,
+	mode purgeModel.Mode,
+	deletedBefore time.Time,
+	limit int,
+) ([]purgeModel.Entry, errs.ChatError) {
+	queryString, args := BuildCandidatesQuery(deletedBefore, limit)
+	rows, err := tx.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.Debug("error closing rows", zap.Error(err))
+		}
+	}(rows)
+
+	entries := make([]purgeModel.Entry, 0)
+	for rows.Next() {
+		entry := purgeModel.Entry{Mode: mode}
+		if err := rows.Scan(&entry.UserId, &entry.DeletedAt); err != nil {
+			return nil, errs.NewError(errs.ErrInternal, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	return entries, nil
+}
+
+// BuildCandidatesQuery selects the oldest deleted users not purged yet. Anonymized
+// users keep their rows, so they are skipped by 'purged_at'.
+func BuildCandidatesQuery(deletedBefore time.Time, limit int) (string, []interface{}) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("id", "deleted_at").
+		From("public.user").
+		Where(sb.LessThan("deleted_at", deletedBefore), sb.IsNull("purged_at")).
+		OrderBy("deleted_at").Asc().
+		Limit(limit)
+	return sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+// BuildAnonymizeQuery scrubs the personal data of the users. The email is replaced by
+// its SHA-256 hash, so it stays unique, and the username by one derived from the id.
+func BuildAnonymizeQuery(entries []purgeModel.Entry) (string, []interface{}) {
+	ub := sqlbuilder.NewUpdateBuilder()
+	ub.Update("public.user").
+		Set(
+			ub.Assign("username", sqlbuilder.Raw("'deleted-' || id")),
+			ub.Assign("email", sqlbuilder.Raw("encode(sha256(convert_to(email, 'UTF8')), 'hex')")),
+			ub.Assign("password_hash", nil),
+			ub.Assign("purged_at", sqlbuilder.Raw("NOW()")),
+		).
+		Where(ub.In("id", userIds(entries)...))
+	return ub.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+// BuildDeleteUserRowsQuery removes the rows of the users from a table referencing them,
+// like their logins or MFA factors, which anonymized users would keep otherwise.
+func BuildDeleteUserRowsQuery(table string, entries []purgeModel.Entry) (string, []interface{}) {
+	db := sqlbuilder.NewDeleteBuilder()
+	db.DeleteFrom(table).
+		Where(db.In("user_id", userIds(entries)...))
+	return db.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+// BuildHardDeleteQuery removes the users. Their logins, MFA factors and recovery codes
+// are removed in cascade.
+func BuildHardDeleteQuery(entries []purgeModel.Entry) (string, []interface{}) {
+	db := sqlbuilder.NewDeleteBuilder()
+	db.DeleteFrom("public.user").
+		Where(db.In("id", userIds(entries)...))
+	return db.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+func BuildAuditQuery(entries []purgeModel.Entry) (string, []interface{}) {
+	ib := sqlbuilder.NewInsertBuilder()
+	ib.InsertInto("public.user_purge_audit").
+		Cols("user_id", "mode", "deleted_at")
+	for _, entry := range entries {
+		ib.Values(entry.UserId, entry.Mode, entry.DeletedAt)
+	}
+	return ib.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+func userIds(entries []purgeModel.Entry) []interface{} {
+	ids := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.UserId)
+	}
+	return ids
+}
+
+func NewPostgresRepository(db *sql.DB) purge.Repository {
+	return &repository{db: db}
+}
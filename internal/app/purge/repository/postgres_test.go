@@ -0,0 +1,129 @@
+package purge
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	purgeModel "github.com/raffops/chat_auth/internal/app/purge/model"
+)
+
+var (
+	deletedAt = time.Date(2024, time.May, 7, 12, 27, 58, 0, time.UTC)
+	entries   = []purgeModel.Entry{
+		{UserId: "ac554921-1b75-43bd-9e1d-e17dfb38f6c3", Mode: purgeModel.ModeAnonymize, DeletedAt: deletedAt},
+		{UserId: "b0a860ee-35ac-478a-8961-069fe2b8dfc1", Mode: purgeModel.ModeAnonymize, DeletedAt: deletedAt},
+	}
+)
+
+func TestBuildQueries(t *testing.T) {
+	userIds := []interface{}{entries[0].UserId, entries[1].UserId}
+	candidatesQuery, candidatesArgs := BuildCandidatesQuery(deletedAt, 100)
+	anonymizeQuery, anonymizeArgs := BuildAnonymizeQuery(entries)
+	factorsQuery, factorsArgs := BuildDeleteUserRowsQuery("public.user_mfa", entries)
+	deleteQuery, deleteArgs := BuildHardDeleteQuery(entries)
+	auditQuery, auditArgs := BuildAuditQuery(entries)
+	tests := []struct {
+		name     string
+		got      string
+		gotArgs  []interface{}
+		want     string
+		wantArgs []interface{}
+	}{
+		{
+			name:    "Test candidates query",
+			got:     candidatesQuery,
+			gotArgs: candidatesArgs,
+			want: "SELECT id, deleted_at FROM public.user WHERE deleted_at < $1 AND purged_at IS NULL " +
+				"ORDER BY deleted_at ASC LIMIT 100",
+			wantArgs: []interface{}{deletedAt},
+		},
+		{
+			name:    "Test anonymize query",
+			got:     anonymizeQuery,
+			gotArgs: anonymizeArgs,
+			want: "UPDATE public.user SET username = 'deleted-' || id, " +
+				"email = encode(sha256(convert_to(email, 'UTF8')), 'hex'), " +
+				"password_hash = $1, purged_at = NOW() WHERE id IN ($2, $3)",
+			wantArgs: append([]interface{}{nil}, userIds...),
+		},
+		{
+			name:     "Test delete user rows query",
+			got:      factorsQuery,
+			gotArgs:  factorsArgs,
+			want:     "DELETE FROM public.user_mfa WHERE user_id IN ($1, $2)",
+			wantArgs: userIds,
+		},
+		{
+			name:     "Test hard delete query",
+			got:      deleteQuery,
+			gotArgs:  deleteArgs,
+			want:     "DELETE FROM public.user WHERE id IN ($1, $2)",
+			wantArgs: userIds,
+		},
+		{
+			name:    "Test audit query",
+			got:     auditQuery,
+			gotArgs: auditArgs,
+			want: "INSERT INTO public.user_purge_audit (user_id, mode, deleted_at) " +
+				"VALUES ($1, $2, $3), ($4, $5, $6)",
+			wantArgs: []interface{}{
+				entries[0].UserId, purgeModel.ModeAnonymize, deletedAt,
+				entries[1].UserId, purgeModel.ModeAnonymize, deletedAt,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("query \n\tgot = %v\n\twant = %v", tt.got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.gotArgs, tt.wantArgs) {
+				t.Errorf("args got = %v, want %v", tt.gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestPurgeQueries(t *testing.T) {
+	tests := []struct {
+		name string
+		mode purgeModel.Mode
+		want []string
+	}{
+		{
+			name: "Test anonymize mode",
+			mode: purgeModel.ModeAnonymize,
+			want: []string{
+				"DELETE FROM public.user_login WHERE user_id IN ($1, $2)",
+				"DELETE FROM public.user_recovery_code WHERE user_id IN ($1, $2)",
+				"DELETE FROM public.user_mfa WHERE user_id IN ($1, $2)",
+				"DELETE FROM public.user_identity WHERE user_id IN ($1, $2)",
+				"UPDATE public.user SET username = 'deleted-' || id, " +
+					"email = encode(sha256(convert_to(email, 'UTF8')), 'hex'), " +
+					"password_hash = $1, purged_at = NOW() WHERE id IN ($2, $3)",
+				"INSERT INTO public.user_purge_audit (user_id, mode, deleted_at) VALUES ($1, $2, $3), ($4, $5, $6)",
+			},
+		},
+		{
+			name: "Test delete mode",
+			mode: purgeModel.ModeDelete,
+			want: []string{
+				"DELETE FROM public.user WHERE id IN ($1, $2)",
+				"INSERT INTO public.user_purge_audit (user_id, mode, deleted_at) VALUES ($1, $2, $3), ($4, $5, $6)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := purgeQueries(tt.mode, entries)
+			got := make([]string, 0, len(queries))
+			for _, q := range queries {
+				got = append(got, q.queryString)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("queries \n\tgot = %v\n\twant = %v", got, tt.want)
+			}
+		})
+	}
+}
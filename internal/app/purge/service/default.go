@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/raffops/chat_auth/internal/app/purge"
+	purgeModels "github.com/raffops/chat_auth/internal/app/purge/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// batchSize is the number of users purged in each transaction.
+const batchSize = 100
+
+// defaultService purges the users deleted for longer than the retention window.
+//
+// Every replica can run it, since the repository lets a single one purge at a time.
+type defaultService struct {
+	repo      purge.Repository
+	mode      purgeModels.Mode
+	retention time.Duration
+}
+
+// Purge purges the users in batches, until no user is left to purge, and returns how
+// many were purged. If another replica is purging, the svcError is 'errs.ErrConflict'.
+func (s *defaultService) Purge(ctx context.Context) (int, errs.ChatError) {
+	deletedBefore := time.Now().Add(-s.retention)
+	purged := 0
+	for {
+		entries, err := s.repo.PurgeUsers(ctx, s.mode, deletedBefore, batchSize)
+		if err != nil {
+			return purged, err
+		}
+		purged += len(entries)
+		if len(entries) < batchSize {
+			return purged, nil
+		}
+	}
+}
+
+// StartPurging purges the deleted users at every interval until the context is done.
+func (s *defaultService) StartPurging(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				purged, err := s.Purge(ctx)
+				switch {
+				case err != nil && errors.Is(err.SvcError(), errs.ErrConflict):
+					logger.Debug("deleted users are being purged by another instance")
+				case err != nil:
+					logger.Error("cannot purge deleted users", zap.Error(err))
+				case purged > 0:
+					logger.Info("deleted users purged", zap.Int("purged", purged), zap.String("mode", string(s.mode)))
+				}
+			}
+		}
+	}()
+}
+
+func sanityCheck() {
+	envVariables := []string{
+		"PURGE_MODE",
+		"PURGE_RETENTION",
+	}
+	for _, envVariable := range envVariables {
+		if _, ok := os.LookupEnv(envVariable); !ok {
+			logger.Fatal("Environment variable not set", zap.String("variable", envVariable))
+		}
+	}
+}
+
+func NewDefaultService(
+	repo purge.Repository,
+	mode purgeModels.Mode,
+	retention time.Duration,
+) (purge.Service, errs.ChatError) {
+	sanityCheck()
+	if _, ok := purgeModels.MapModeString[string(mode)]; !ok {
+		return nil, errs.NewError(errs.ErrBadRequest, errors.New("purge mode must be delete or anonymize"))
+	}
+	if retention <= 0 {
+		return nil, errs.NewError(errs.ErrBadRequest, errors.New("purge retention must be positive"))
+	}
+	return &defaultService{repo: repo, mode: mode, retention: retention}, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	purgeModels "github.com/raffops/chat_auth/internal/app/purge/model"
+	purgeMock "github.com/raffops/chat_auth/test/mocks/purge"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/stretchr/testify/mock"
+)
+
+func entries(n int) []purgeModels.Entry {
+	result := make([]purgeModels.Entry, n)
+	for i := range result {
+		result[i] = purgeModels.Entry{UserId: "id", Mode: purgeModels.ModeAnonymize}
+	}
+	return result
+}
+
+func TestDefaultService_Purge(t *testing.T) {
+	os.Setenv("PURGE_MODE", "anonymize")
+	os.Setenv("PURGE_RETENTION", "720h")
+	retention := 720 * time.Hour
+	deletedBefore := mock.MatchedBy(func(deletedBefore time.Time) bool {
+		return time.Since(deletedBefore.Add(retention)) < time.Minute
+	})
+	tests := []struct {
+		name    string
+		batches [][]purgeModels.Entry
+		err     errs.ChatError
+		want    int
+		wantErr error
+	}{
+		{
+			name:    "Test nothing to purge",
+			batches: [][]purgeModels.Entry{entries(0)},
+			want:    0,
+		},
+		{
+			name:    "Test purge until the last batch",
+			batches: [][]purgeModels.Entry{entries(batchSize), entries(batchSize), entries(3)},
+			want:    2*batchSize + 3,
+		},
+		{
+			name:    "Test purge locked by another instance",
+			err:     errs.NewError(errs.ErrConflict, errors.New("another purge is running")),
+			wantErr: errs.ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := purgeMock.NewRepository(t)
+			for _, batch := range tt.batches {
+				repo.EXPECT().PurgeUsers(mock.Anything, purgeModels.ModeAnonymize, deletedBefore, batchSize).
+					Return(batch, nil).Once()
+			}
+			if tt.err != nil {
+				repo.EXPECT().PurgeUsers(mock.Anything, purgeModels.ModeAnonymize, deletedBefore, batchSize).
+					Return(nil, tt.err).Once()
+			}
+			srv, err := NewDefaultService(repo, purgeModels.ModeAnonymize, retention)
+			if err != nil {
+				t.Fatalf("NewDefaultService() error = %v", err)
+			}
+
+			got, err := srv.Purge(context.Background())
+			if got != tt.want {
+				t.Errorf("Purge() got = %v, want %v", got, tt.want)
+			}
+			if (err == nil) != (tt.wantErr == nil) || err != nil && !errors.Is(err.SvcError(), tt.wantErr) {
+				t.Errorf("Purge() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewDefaultService_Invalid(t *testing.T) {
+	os.Setenv("PURGE_MODE", "anonymize")
+	os.Setenv("PURGE_RETENTION", "720h")
+	tests := []struct {
+		name      string
+		mode      purgeModels.Mode
+		retention time.Duration
+	}{
+		{name: "Test unknown mode", mode: "archive", retention: time.Hour},
+		{name: "Test no retention", mode: purgeModels.ModeDelete, retention: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDefaultService(purgeMock.NewRepository(t), tt.mode, tt.retention)
+			if err == nil {
+				t.Fatalf("NewDefaultService() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package sessionManager
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	"github.com/raffops/chat_auth/internal/app/user"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+type controller struct {
+	userRepo       user.ReaderRepository
+	sessionService sessionManager.Service
+}
+
+// ListSessions lists the active sessions of the caller, newest first. The session of the
+// request is marked as current.
+func (c *controller) ListSessions(w http.ResponseWriter, r *http.Request) {
+	principal, err := sessionModels.PrincipalFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	c.writeSessions(w, r, principal.UserId, sessionModels.SessionHandle(principal.SessionId))
+}
+
+// RevokeSession revokes one of the sessions of the caller, which may be the current one. The
+// session is given by the handle it is listed with.
+func (c *controller) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	principal, err := sessionModels.PrincipalFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	c.revokeSession(w, r, principal.UserId, mux.Vars(r)["id"])
+}
+
+// ListUserSessions lists the active sessions of any user, newest first.
+func (c *controller) ListUserSessions(w http.ResponseWriter, r *http.Request) {
+	u, err := c.userRepo.GetUser(r.Context(), "id", mux.Vars(r)["id"], true)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	c.writeSessions(w, r, u.Id, "")
+}
+
+// RevokeUserSession revokes one of the sessions of any user, given by the handle it is listed with.
+func (c *controller) RevokeUserSession(w http.ResponseWriter, r *http.Request) {
+	u, err := c.userRepo.GetUser(r.Context(), "id", mux.Vars(r)["id"], true)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	c.revokeSession(w, r, u.Id, mux.Vars(r)["sessionId"])
+}
+
+func (c *controller) writeSessions(w http.ResponseWriter, r *http.Request, userId, currentHandle string) {
+	sessions, err := c.sessionService.ListUserSessions(r.Context(), userId)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].Id == currentHandle
+	}
+	responseString, _ := json.Marshal(sessions)
+	_, _ = w.Write(responseString)
+}
+
+func (c *controller) revokeSession(w http.ResponseWriter, r *http.Request, userId, handle string) {
+	err := c.sessionService.FinishUserSession(r.Context(), userId, handle)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func NewController(userRepo user.ReaderRepository, sessionService sessionManager.Service) sessionManager.Controller {
+	return &controller{userRepo: userRepo, sessionService: sessionService}
+}
@@ -0,0 +1,99 @@
+package sessionManager
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	sessionMock "github.com/raffops/chat_auth/test/mocks/sessionManager"
+	userMock "github.com/raffops/chat_auth/test/mocks/user"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestController_ListSessions(t *testing.T) {
+	sessionSrv := sessionMock.NewService(t)
+	sessions := []sessionModels.Session{
+		{Id: sessionModels.SessionHandle("2"), UserId: "1"},
+		{Id: sessionModels.SessionHandle("1"), UserId: "1"},
+	}
+	sessionSrv.EXPECT().ListUserSessions(mock.Anything, "1").Return(sessions, nil).Once()
+	c := NewController(userMock.NewReaderRepository(t), sessionSrv)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/sessions", nil)
+	principal := sessionModels.Principal{UserId: "1", Role: authModels.RoleUser, SessionId: "1"}
+	c.ListSessions(w, r.WithContext(sessionModels.ContextWithPrincipal(r.Context(), principal)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("ListSessions() got = %v, want %v", w.Code, http.StatusOK)
+	}
+	var got []sessionModels.Session
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if len(got) != 2 || got[0].Current || !got[1].Current {
+		t.Errorf("ListSessions() got = %v, want the second session as current", got)
+	}
+}
+
+func TestController_ListSessionsWithoutPrincipal(t *testing.T) {
+	c := NewController(userMock.NewReaderRepository(t), sessionMock.NewService(t))
+
+	w := httptest.NewRecorder()
+	c.ListSessions(w, httptest.NewRequest("GET", "/sessions", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("ListSessions() got = %v, want %v", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestController_RevokeSession(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      errs.ChatError
+		wantCode int
+	}{
+		{name: "Test revoke session", wantCode: http.StatusNoContent},
+		{
+			name:     "Test revoke session of another user",
+			err:      errs.NewError(errs.ErrNotFound, errors.New("session not found")),
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionSrv := sessionMock.NewService(t)
+			sessionSrv.EXPECT().FinishUserSession(mock.Anything, "1", "2").Return(tt.err).Once()
+			c := NewController(userMock.NewReaderRepository(t), sessionSrv)
+
+			w := httptest.NewRecorder()
+			r := mux.SetURLVars(httptest.NewRequest("DELETE", "/sessions/2", nil), map[string]string{"id": "2"})
+			principal := sessionModels.Principal{UserId: "1", Role: authModels.RoleUser, SessionId: "1"}
+			c.RevokeSession(w, r.WithContext(sessionModels.ContextWithPrincipal(r.Context(), principal)))
+			if w.Code != tt.wantCode {
+				t.Errorf("RevokeSession() got = %v, want %v", w.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestController_RevokeUserSession(t *testing.T) {
+	userRepo := userMock.NewReaderRepository(t)
+	userRepo.EXPECT().GetUser(mock.Anything, "id", "1", true).Return(userModels.User{Id: "1"}, nil).Once()
+	sessionSrv := sessionMock.NewService(t)
+	sessionSrv.EXPECT().FinishUserSession(mock.Anything, "1", "2").Return(nil).Once()
+	c := NewController(userRepo, sessionSrv)
+
+	w := httptest.NewRecorder()
+	r := mux.SetURLVars(
+		httptest.NewRequest("DELETE", "/users/1/sessions/2", nil),
+		map[string]string{"id": "1", "sessionId": "2"},
+	)
+	c.RevokeUserSession(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("RevokeUserSession() got = %v, want %v", w.Code, http.StatusNoContent)
+	}
+}
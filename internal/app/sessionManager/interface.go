@@ -6,6 +6,7 @@ import (
 	"time"
 
 	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
 	"github.com/raffops/chat_commons/pkg/errs"
 	"google.golang.org/grpc"
 )
@@ -15,7 +16,7 @@ type ReaderRepository interface {
 	HashGet(ctx context.Context, tableName, key string, columns ...string) (map[string]interface{}, errs.ChatError)
 	StringGet(ctx context.Context, tableName, key string) (string, errs.ChatError)
 	GetTTL(ctx context.Context, tableName, key string) (time.Time, errs.ChatError)
-	GetKeys(ctx context.Context, pattern string) ([]string, errs.ChatError)
+	SetMembers(ctx context.Context, tableName, key string, after time.Time) (map[string]time.Time, errs.ChatError)
 }
 
 type WriterRepository interface {
@@ -23,6 +24,17 @@ type WriterRepository interface {
 	HashSet(ctx context.Context, tx interface{}, tableName, key string, values map[string]interface{}) errs.ChatError
 	StringSet(ctx context.Context, tx interface{}, tableName, key, value string) errs.ChatError
 	ExpireAt(ctx context.Context, tx interface{}, tableName string, key string, at time.Time) errs.ChatError
+	SetAdd(ctx context.Context, tx interface{}, tableName, key, member string, expiresAt time.Time) errs.ChatError
+	SetAddLimited(
+		ctx context.Context,
+		tableName, key, member string,
+		expiresAt time.Time,
+		limit int,
+		evict bool,
+	) ([]string, errs.ChatError)
+	HashIncrement(ctx context.Context, tableName, key, column string) (int64, errs.ChatError)
+	SetRemove(ctx context.Context, tx interface{}, tableName, key string, members ...string) errs.ChatError
+	SetRemoveExpired(ctx context.Context, tx interface{}, tableName, key string, before time.Time) errs.ChatError
 	Delete(ctx context.Context, tx interface{}, tableName, key string) errs.ChatError
 	BeginTransaction(ctx context.Context) (interface{}, errs.ChatError)
 	CommitTransaction(ctx context.Context, tx interface{}) errs.ChatError
@@ -35,18 +47,36 @@ type ReaderWriterRepository interface {
 }
 
 type Service interface {
-	CreateSession(ctx context.Context, userId string, payload map[string]interface{}) (string, errs.ChatError)
+	CreateSession(ctx context.Context, userId string, payload map[string]interface{}) (string, []string, errs.ChatError)
 	GetSession(ctx context.Context, sessionId string) (map[string]interface{}, errs.ChatError)
 	FinishSession(ctx context.Context, sessionId string) errs.ChatError
 	FinishUserSessions(ctx context.Context, userId string) errs.ChatError
+	ListUserSessions(ctx context.Context, userId string) ([]sessionModels.Session, errs.ChatError)
+	FinishUserSession(ctx context.Context, userId, handle string) errs.ChatError
 	RefreshSession(ctx context.Context, sessionId string) errs.ChatError
+	CreateRefreshToken(ctx context.Context, userId, sessionId string) (string, errs.ChatError)
+	RotateRefreshToken(ctx context.Context, refreshToken string) (string, string, errs.ChatError)
 	CheckRestSession(next http.HandlerFunc, roles []authModels.RoleId) http.HandlerFunc
+	CheckRestSessionWithMfa(next http.HandlerFunc, roles []authModels.RoleId) http.HandlerFunc
 	CheckGrpcSession(
 		srv any,
 		ss grpc.ServerStream,
 		info *grpc.StreamServerInfo,
 		handler grpc.StreamHandler,
 	) error
-	SetRoles(method string, roles []authModels.RoleId)
+	CheckGrpcUnarySession(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error)
+	SetRoles(ctx context.Context, method string, roles []authModels.RoleId) errs.ChatError
 	GetRoles(ctx context.Context, method string) ([]authModels.RoleId, errs.ChatError)
 }
+
+type Controller interface {
+	ListSessions(w http.ResponseWriter, r *http.Request)
+	RevokeSession(w http.ResponseWriter, r *http.Request)
+	ListUserSessions(w http.ResponseWriter, r *http.Request)
+	RevokeUserSession(w http.ResponseWriter, r *http.Request)
+}
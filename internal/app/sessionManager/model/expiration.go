@@ -0,0 +1,53 @@
+package sessionManager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+// Expiration sets when the sessions of a role expire. Idle is the sliding timeout, extended
+// on every authenticated call and refresh, and MaxLifetime is the absolute lifetime of the
+// session, which no extension exceeds. A zero MaxLifetime leaves the lifetime unbounded.
+type Expiration struct {
+	Idle        time.Duration
+	MaxLifetime time.Duration
+}
+
+// ParseExpirations parses the session expirations by role, as the idle timeout and the max
+// lifetime, like 'admin=15m:8h,user=1h:720h'. The max lifetime is optional, and roles left
+// out use the default idle timeout without max lifetime.
+func ParseExpirations(expirations string) (map[authModels.RoleId]Expiration, errs.ChatError) {
+	output := make(map[authModels.RoleId]Expiration)
+	if strings.TrimSpace(expirations) == "" {
+		return output, nil
+	}
+	for _, roleExpiration := range strings.Split(expirations, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(roleExpiration), "=")
+		role, ok := authModels.MapRoleString[name]
+		if !ok {
+			return nil, errs.NewError(errs.ErrBadRequest, fmt.Errorf("role %s not found", name))
+		}
+		idle, maxLifetime, _ := strings.Cut(value, ":")
+		var expiration Expiration
+		var err error
+		expiration.Idle, err = time.ParseDuration(idle)
+		if err != nil || expiration.Idle <= 0 {
+			return nil, errs.NewError(errs.ErrBadRequest, fmt.Errorf("idle timeout of %s must be positive", name))
+		}
+		if maxLifetime != "" {
+			expiration.MaxLifetime, err = time.ParseDuration(maxLifetime)
+			if err != nil || expiration.MaxLifetime < expiration.Idle {
+				return nil, errs.NewError(
+					errs.ErrBadRequest,
+					fmt.Errorf("max lifetime of %s must not be shorter than its idle timeout", name),
+				)
+			}
+		}
+		output[role] = expiration
+	}
+	return output, nil
+}
@@ -0,0 +1,42 @@
+package sessionManager
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+)
+
+func TestParseExpirations(t *testing.T) {
+	tests := []struct {
+		name        string
+		expirations string
+		want        map[authModels.RoleId]Expiration
+		wantErr     bool
+	}{
+		{name: "Test no expirations", expirations: "", want: map[authModels.RoleId]Expiration{}},
+		{
+			name:        "Test expirations by role",
+			expirations: "admin=15m:8h, user=1h",
+			want: map[authModels.RoleId]Expiration{
+				authModels.RoleAdmin: {Idle: 15 * time.Minute, MaxLifetime: 8 * time.Hour},
+				authModels.RoleUser:  {Idle: time.Hour},
+			},
+		},
+		{name: "Test unknown role", expirations: "owner=1h", wantErr: true},
+		{name: "Test invalid idle timeout", expirations: "user=soon", wantErr: true},
+		{name: "Test max lifetime shorter than idle timeout", expirations: "user=1h:30m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExpirations(tt.expirations)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExpirations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseExpirations() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package sessionManager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+type LimitMode string
+
+const (
+	LimitModeReject LimitMode = "reject"
+	LimitModeEvict  LimitMode = "evict"
+)
+
+var MapLimitModeString = map[string]LimitMode{
+	"reject": LimitModeReject,
+	"evict":  LimitModeEvict,
+}
+
+// Limit caps the simultaneous sessions of the users of a role. A user at the limit who
+// logs in is rejected, or has the session expiring first, the least recently used one,
+// evicted for the new one.
+type Limit struct {
+	Max  int
+	Mode LimitMode
+}
+
+// ParseLimits parses the session limits by role, like 'admin=1:reject,user=5:evict'.
+// Roles left out have no limit.
+func ParseLimits(limits string) (map[authModels.RoleId]Limit, errs.ChatError) {
+	output := make(map[authModels.RoleId]Limit)
+	if strings.TrimSpace(limits) == "" {
+		return output, nil
+	}
+	for _, roleLimit := range strings.Split(limits, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(roleLimit), "=")
+		role, ok := authModels.MapRoleString[name]
+		if !ok {
+			return nil, errs.NewError(errs.ErrBadRequest, fmt.Errorf("role %s not found", name))
+		}
+		maxSessions, modeName, _ := strings.Cut(value, ":")
+		limit := Limit{Mode: LimitModeReject}
+		var err error
+		limit.Max, err = strconv.Atoi(maxSessions)
+		if err != nil || limit.Max < 1 {
+			return nil, errs.NewError(errs.ErrBadRequest, fmt.Errorf("session limit of %s must be positive", name))
+		}
+		if modeName != "" {
+			limit.Mode, ok = MapLimitModeString[modeName]
+			if !ok {
+				return nil, errs.NewError(errs.ErrBadRequest, fmt.Errorf("session limit mode %s not found", modeName))
+			}
+		}
+		output[role] = limit
+	}
+	return output, nil
+}
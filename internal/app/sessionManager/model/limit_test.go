@@ -0,0 +1,46 @@
+package sessionManager
+
+import (
+	"reflect"
+	"testing"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+)
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  string
+		want    map[authModels.RoleId]Limit
+		wantErr bool
+	}{
+		{name: "Test no limits", limits: "", want: map[authModels.RoleId]Limit{}},
+		{
+			name:   "Test limits by role",
+			limits: "admin=1:reject, user=5:evict",
+			want: map[authModels.RoleId]Limit{
+				authModels.RoleAdmin: {Max: 1, Mode: LimitModeReject},
+				authModels.RoleUser:  {Max: 5, Mode: LimitModeEvict},
+			},
+		},
+		{
+			name:   "Test default mode",
+			limits: "user=3",
+			want:   map[authModels.RoleId]Limit{authModels.RoleUser: {Max: 3, Mode: LimitModeReject}},
+		},
+		{name: "Test unknown role", limits: "owner=1", wantErr: true},
+		{name: "Test zero limit", limits: "user=0", wantErr: true},
+		{name: "Test unknown mode", limits: "user=1:drop", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLimits(tt.limits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLimits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLimits() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package sessionManager
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+// Principal is the caller authenticated by the session middlewares. It is injected in
+// the request context, for both REST and gRPC calls, and read with PrincipalFromContext.
+type Principal struct {
+	UserId    string
+	Role      authModels.RoleId
+	SessionId string
+	Mfa       bool
+}
+
+type principalKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal injected by the session middlewares. It
+// returns false for calls that did not go through them.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// PrincipalFromRequest returns the principal of a REST request that went through the session
+// middlewares, or an 'errs.ErrNotAuthenticated' error for one that did not.
+func PrincipalFromRequest(r *http.Request) (Principal, errs.ChatError) {
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		return Principal{}, errs.NewError(errs.ErrNotAuthenticated, errors.New("session not found"))
+	}
+	return principal, nil
+}
@@ -0,0 +1,30 @@
+package sessionManager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Session is an active session of a user, as listed to the user and to admins. Id is the
+// handle of the session, since the session id is the credential of the session. Current
+// marks the session the listing was requested with.
+//
+// Sessions created before the index carried metadata have no creation time nor client.
+type Session struct {
+	Id        string     `json:"id"`
+	UserId    string     `json:"user_id"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	Ip        string     `json:"ip,omitempty"`
+	UserAgent string     `json:"user_agent,omitempty"`
+	Provider  string     `json:"provider,omitempty"`
+	Current   bool       `json:"current"`
+}
+
+// SessionHandle returns the handle a session is listed and revoked with. It is the SHA-256 of
+// the session id, so it identifies the session without granting access to it.
+func SessionHandle(sessionId string) string {
+	sum := sha256.Sum256([]byte(sessionId))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,317 +1,702 @@
 package sessionManager
 
-//
-//import (
-//	"context"
-//	"encoding/json"
-//	"fmt"
-//	"github.com/aws/aws-sdk-go/aws"
-//	"github.com/aws/aws-sdk-go/aws/awserr"
-//	"github.com/aws/aws-sdk-go/service/dynamodb"
-//	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
-//	"github.com/raffops/auth/internal/app/sessionManager"
-//	"github.com/raffops/chat_commons/pkg/encryptor"
-//	"github.com/raffops/chat_commons/pkg/errs"
-//	"strconv"
-//	"time"
-//)
-//
-//type dynamoRepository struct {
-//	conn      *dynamodb.DynamoDB
-//	encryptor encryptor.Encryptor
-//}
-//
-//func (d dynamoRepository) StringSet(ctx context.Context, tx interface{}, tableName, key, value string) errs.ChatError {
-//	//TODO implement me
-//	panic("implement me")
-//}
-//
-//func (d dynamoRepository) StringGet(ctx context.Context, tableName, key string) (string, errs.ChatError) {
-//	//TODO implement me
-//	panic("implement me")
-//}
-//
-//func (d dynamoRepository) Delete(ctx context.Context, tableName, key string) errs.ChatError {
-//	//TODO implement me
-//	panic("implement me")
-//}
-//
-//func (d dynamoRepository) HashSet(
-//	ctx context.Context,
-//	tableName, key string,
-//	values map[string]interface{},
-//) errs.ChatError {
-//	values["id"] = key
-//	marshaledTableItem, err := dynamodbattribute.MarshalMap(values)
-//	if err != nil {
-//		return errs.NewError(
-//			errs.ErrInternal,
-//			fmt.Errorf("error marshaling value: %v", err),
-//		)
-//	}
-//
-//	input := &dynamodb.PutItemInput{Item: marshaledTableItem, TableName: aws.String(tableName)}
-//
-//	_, err = d.conn.PutItemWithContext(ctx, input)
-//	if err != nil {
-//		return errs.NewError(
-//			errs.ErrInternal,
-//			fmt.Errorf("error creating session: %v", err),
-//		)
-//	}
-//
-//	return nil
-//}
-//
-//func (d dynamoRepository) HashSetEncrypted(ctx context.Context, tableName, key, secret string, values map[string]interface{}) errs.ChatError {
-//	valueByte, err := json.Marshal(values)
-//	if err != nil {
-//		return errs.NewError(
-//			errs.ErrInternal,
-//			fmt.Errorf("error marshaling value: %v", err),
-//		)
-//	}
-//
-//	encryptedValue, err := d.encryptor.Encrypt(string(valueByte), secret)
-//	if err != nil {
-//		return errs.NewError(
-//			errs.ErrInternal,
-//			fmt.Errorf("error encrypting value: %v", err),
-//		)
-//	}
-//
-//	tableItem := map[string]interface{}{
-//		"encrypted_value": encryptedValue,
-//	}
-//
-//	return d.HashSet(ctx, tableName, key, tableItem)
-//}
-//
-//func (d dynamoRepository) HashGet(ctx context.Context, tableName, key string, columns ...string) (map[string]interface{}, errs.ChatError) {
-//	var attributesToGet []*string
-//	for _, column := range columns {
-//		attributesToGet = append(attributesToGet, aws.String(column))
-//	}
-//	input := &dynamodb.GetItemInput{
-//		Key: map[string]*dynamodb.AttributeValue{
-//			"id": {
-//				S: aws.String(key),
-//			},
-//		},
-//		AttributesToGet: attributesToGet,
-//		TableName:       aws.String(tableName),
-//	}
-//
-//	result, err := d.conn.GetItemWithContext(ctx, input)
-//	if err != nil {
-//		return nil, errs.NewError(
-//			errs.ErrInternal,
-//			fmt.Errorf("error getting session: %v", err),
-//		)
-//	}
-//
-//	if len(result.Item) == 0 {
-//		return nil, errs.NewError(errs.ErrNotFound, nil)
-//	}
-//
-//	var values map[string]interface{}
-//	err = dynamodbattribute.UnmarshalMap(result.Item, &values)
-//	if err != nil {
-//		return nil, errs.NewError(
-//			errs.ErrInternal,
-//			fmt.Errorf("error unmarshalling session: %v", err),
-//		)
-//	}
-//	return values, nil
-//}
-//
-//func (d dynamoRepository) GetTTL(ctx context.Context, tableName, key string) (time.Time, errs.ChatError) {
-//	result, err := d.HashGet(ctx, tableName, key, "ttl")
-//	ttl := result["ttl"].(float64)
-//	if err != nil {
-//		return time.Time{}, err
-//	}
-//
-//	return time.Unix(int64(ttl), 0), nil
-//}
-//
-//func (d dynamoRepository) HashGetEncrypted(
-//	ctx context.Context,
-//	tableName, key, secret string,
-//) (map[string]interface{}, errs.ChatError) {
-//	result, err := d.HashGet(ctx, tableName, key, "encrypted_value")
-//	if err != nil {
-//		return nil, errs.NewError(
-//			errs.ErrInternal,
-//			fmt.Errorf("error getting session: %v", err),
-//		)
-//	}
-//	decryptedValue, errDecrypt := d.encryptor.Decrypt(result["encrypted_value"].(string), secret)
-//	if errDecrypt != nil {
-//		return nil, errs.NewError(
-//			errs.ErrInternal,
-//			fmt.Errorf("error decrypting value: %v", err),
-//		)
-//	}
-//
-//	var value map[string]interface{}
-//	errUnmarshall := json.Unmarshal([]byte(decryptedValue), &value)
-//	if errUnmarshall != nil {
-//		return nil, errs.NewError(
-//			errs.ErrInternal,
-//			fmt.Errorf("corrupted data"),
-//		)
-//	}
-//
-//	return value, nil
-//}
-//
-//func (d dynamoRepository) ExpireAt(ctx context.Context, tableName string, key string, at time.Time) errs.ChatError {
-//	input := &dynamodb.UpdateItemInput{
-//		UpdateExpression: aws.String("SET #ttl = :ttl"),
-//		ExpressionAttributeNames: map[string]*string{
-//			"#ttl": aws.String("ttl"),
-//		},
-//		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-//			":ttl": {
-//				N: aws.String(strconv.FormatInt(at.Unix(), 10)),
-//			},
-//		},
-//		Key: map[string]*dynamodb.AttributeValue{
-//			"id": {
-//				S: aws.String(key),
-//			},
-//		},
-//		TableName: aws.String(tableName),
-//	}
-//	_, err := d.conn.UpdateItemWithContext(ctx, input)
-//	if err != nil {
-//		return errs.NewError(
-//			errs.ErrInternal,
-//			fmt.Errorf("error setting ttl: %v", err),
-//		)
-//	}
-//	return nil
-//}
-//
-//func NewDynamodbRepository(conn *dynamodb.DynamoDB, encryptor encryptor.Encryptor) (sessionManager.Repository, error) {
-//	err := createTableSession(conn)
-//	if err != nil {
-//		return nil, err
-//	}
-//	err = enableTTL(conn, "session")
-//	if err != nil {
-//		return nil, err
-//	}
-//	err = createTableUserSession(conn)
-//	if err != nil {
-//		return nil, err
-//	}
-//	err = enableTTL(conn, "user_session")
-//	if err != nil {
-//		return nil, err
-//	}
-//
-//	return &dynamoRepository{conn: conn, encryptor: encryptor}, nil
-//}
-//
-//func createTableSession(conn *dynamodb.DynamoDB) error {
-//	tableName := "session"
-//
-//	input := &dynamodb.CreateTableInput{
-//		AttributeDefinitions: []*dynamodb.AttributeDefinition{
-//			{
-//				AttributeName: aws.String("id"),
-//				AttributeType: aws.String("S"),
-//			},
-//		},
-//		KeySchema: []*dynamodb.KeySchemaElement{
-//			{
-//				AttributeName: aws.String("id"),
-//				KeyType:       aws.String("HASH"),
-//			},
-//		},
-//		TableName:   aws.String(tableName),
-//		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
-//	}
-//
-//	_, err := conn.CreateTable(input)
-//	if err != nil && err.Error() != "ResourceInUseException: Table already exists: session" {
-//		return err
-//	}
-//
-//	return waitTableCreate(conn, tableName)
-//}
-//
-//func createTableUserSession(conn *dynamodb.DynamoDB) error {
-//	tableName := "user_session"
-//
-//	input := &dynamodb.CreateTableInput{
-//		AttributeDefinitions: []*dynamodb.AttributeDefinition{
-//			{
-//				AttributeName: aws.String("id"),
-//				AttributeType: aws.String("S"),
-//			},
-//			{
-//				AttributeName: aws.String("session_id"),
-//				AttributeType: aws.String("S"),
-//			},
-//		},
-//		KeySchema: []*dynamodb.KeySchemaElement{
-//			{
-//				AttributeName: aws.String("id"),
-//				KeyType:       aws.String("HASH"),
-//			},
-//			{
-//				AttributeName: aws.String("session_id"),
-//				KeyType:       aws.String("RANGE"),
-//			},
-//		},
-//		TableName:   aws.String(tableName),
-//		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
-//	}
-//
-//	_, err := conn.CreateTable(input)
-//	if err != nil && err.Error() != "ResourceInUseException: Table already exists: user_session" {
-//		return err
-//	}
-//
-//	return waitTableCreate(conn, tableName)
-//}
-//
-//func waitTableCreate(conn *dynamodb.DynamoDB, tableName string) error {
-//	var status string
-//	for {
-//		time.Sleep(1 * time.Second)
-//		currentTable, err := conn.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
-//		if err != nil {
-//			return err
-//		}
-//		status = *currentTable.Table.TableStatus
-//		if status != dynamodb.TableStatusCreating {
-//			break
-//		}
-//	}
-//
-//	if status != dynamodb.TableStatusActive {
-//		return fmt.Errorf("table %s is not active", tableName)
-//	}
-//
-//	return nil
-//}
-//
-//func enableTTL(conn *dynamodb.DynamoDB, tableName string) error {
-//	input := &dynamodb.UpdateTimeToLiveInput{
-//		TableName: aws.String(tableName),
-//		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
-//			AttributeName: aws.String("ttl"),
-//			Enabled:       aws.Bool(true),
-//		},
-//	}
-//
-//	_, err := conn.UpdateTimeToLive(input)
-//	if err != nil && err.(awserr.Error).Message() != "TimeToLive is already enabled" {
-//		return err
-//	}
-//	return nil
-//}
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	"github.com/raffops/chat_commons/pkg/encryptor"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+const (
+	// dynamoItemTable holds the strings and hashes, by '<tableName>:<key>' on 'id'.
+	dynamoItemTable = "session_item"
+	// dynamoSetTable holds the members of the sets, by '<tableName>:<key>' on 'set_id' and
+	// the member on 'member', with the expiration of the member on 'ttl'.
+	dynamoSetTable = "session_set"
+	// dynamoSetTTLIndex is the GSI of the members of a set by expiration, which lists the
+	// active sessions of a user without scanning the table.
+	dynamoSetTTLIndex = "set_id_ttl"
+	// dynamoTransactionLimit is the most writes DynamoDB takes in a transaction.
+	dynamoTransactionLimit = 100
+	// dynamoLimitedAttempts is how many times SetAddLimited retries when a concurrent add
+	// changes the set.
+	dynamoLimitedAttempts = 5
+)
+
+// dynamoReservedAttributes cannot be hash fields, since the items store them.
+var dynamoReservedAttributes = []string{"id", "ttl", "value", "version"}
+
+// dynamoWrite is the write of an item buffered in a transaction. DynamoDB rejects
+// transactions writing an item twice, so the writes of an item are merged into one, which
+// deletes the item, replaces it with item, or updates the attributes in set.
+type dynamoWrite struct {
+	table   string
+	key     map[string]*dynamodb.AttributeValue
+	remove  bool
+	replace bool
+	item    map[string]*dynamodb.AttributeValue
+	set     map[string]*dynamodb.AttributeValue
+}
+
+func (w *dynamoWrite) setAttributes(values map[string]*dynamodb.AttributeValue) {
+	if w.remove {
+		w.remove, w.replace, w.item = false, true, make(map[string]*dynamodb.AttributeValue)
+	}
+	attributes := w.set
+	if w.replace {
+		attributes = w.item
+	}
+	for name, value := range values {
+		attributes[name] = value
+	}
+}
+
+func (w *dynamoWrite) transactItem() *dynamodb.TransactWriteItem {
+	switch {
+	case w.remove:
+		return &dynamodb.TransactWriteItem{Delete: &dynamodb.Delete{TableName: aws.String(w.table), Key: w.key}}
+	case w.replace:
+		item := make(map[string]*dynamodb.AttributeValue, len(w.key)+len(w.item))
+		for name, value := range w.item {
+			item[name] = value
+		}
+		for name, value := range w.key {
+			item[name] = value
+		}
+		return &dynamodb.TransactWriteItem{Put: &dynamodb.Put{TableName: aws.String(w.table), Item: item}}
+	}
+	update := &dynamodb.Update{
+		TableName:                 aws.String(w.table),
+		Key:                       w.key,
+		ExpressionAttributeNames:  make(map[string]*string),
+		ExpressionAttributeValues: make(map[string]*dynamodb.AttributeValue),
+	}
+	expression := ""
+	for name, value := range w.set {
+		i := len(update.ExpressionAttributeNames)
+		update.ExpressionAttributeNames[fmt.Sprintf("#a%d", i)] = aws.String(name)
+		update.ExpressionAttributeValues[fmt.Sprintf(":v%d", i)] = value
+		if expression != "" {
+			expression += ", "
+		}
+		expression += fmt.Sprintf("#a%d = :v%d", i, i)
+	}
+	update.UpdateExpression = aws.String("SET " + expression)
+	return &dynamodb.TransactWriteItem{Update: update}
+}
+
+// dynamoTransaction buffers the writes until it is committed with TransactWriteItems.
+type dynamoTransaction struct {
+	writes map[string]*dynamoWrite
+	order  []string
+}
+
+func (t *dynamoTransaction) write(table string, key map[string]*dynamodb.AttributeValue) *dynamoWrite {
+	id := table
+	for _, name := range []string{"id", "set_id", "member"} {
+		if value, ok := key[name]; ok {
+			id += ":" + aws.StringValue(value.S)
+		}
+	}
+	if w, ok := t.writes[id]; ok {
+		return w
+	}
+	w := &dynamoWrite{table: table, key: key, set: make(map[string]*dynamodb.AttributeValue)}
+	t.writes[id] = w
+	t.order = append(t.order, id)
+	return w
+}
+
+func (t *dynamoTransaction) item(id string) *dynamoWrite {
+	return t.write(dynamoItemTable, itemKey(id))
+}
+
+func (t *dynamoTransaction) member(setId, member string) *dynamoWrite {
+	return t.write(dynamoSetTable, memberKey(setId, member))
+}
+
+// dynamoRepository keeps the sessions in DynamoDB. Every key is an item of
+// dynamoItemTable, and every member of a set an item of dynamoSetTable, expired by the
+// 'ttl' attribute. DynamoDB deletes expired items lazily, within days, so reads leave
+// them out too.
+type dynamoRepository struct {
+	conn      *dynamodb.DynamoDB
+	encryptor encryptor.Encryptor
+}
+
+func itemKey(id string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}}
+}
+
+func memberKey(setId, member string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{"set_id": {S: aws.String(setId)}, "member": {S: aws.String(member)}}
+}
+
+func unixAttribute(at time.Time) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(at.Unix(), 10))}
+}
+
+func numberValue(value *dynamodb.AttributeValue) int64 {
+	if value == nil || value.N == nil {
+		return 0
+	}
+	number, _ := strconv.ParseInt(*value.N, 10, 64)
+	return number
+}
+
+func expiredItem(item map[string]*dynamodb.AttributeValue, now time.Time) bool {
+	ttl, ok := item["ttl"]
+	return ok && numberValue(ttl) <= now.Unix()
+}
+
+func (d dynamoRepository) BeginTransaction(ctx context.Context) (interface{}, errs.ChatError) {
+	return &dynamoTransaction{writes: make(map[string]*dynamoWrite)}, nil
+}
+
+// CommitTransaction writes the transaction with TransactWriteItems. Transactions over the
+// DynamoDB limit of writes are committed in chunks, each one atomic.
+func (d dynamoRepository) CommitTransaction(ctx context.Context, tx interface{}) errs.ChatError {
+	transaction := tx.(*dynamoTransaction)
+	items := make([]*dynamodb.TransactWriteItem, 0, len(transaction.order))
+	for _, id := range transaction.order {
+		items = append(items, transaction.writes[id].transactItem())
+	}
+	for chunk := range slices.Chunk(items, dynamoTransactionLimit) {
+		_, err := d.conn.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: chunk})
+		if err != nil {
+			return errs.NewError(errs.ErrInternal, err)
+		}
+	}
+	return nil
+}
+
+func (d dynamoRepository) RollbackTransaction(ctx context.Context, tx interface{}) errs.ChatError {
+	transaction := tx.(*dynamoTransaction)
+	transaction.writes, transaction.order = make(map[string]*dynamoWrite), nil
+	return nil
+}
+
+// getItem reads the unexpired item of a key, or nil if it is missing or expired.
+func (d dynamoRepository) getItem(
+	ctx context.Context,
+	id string,
+	attributes ...string,
+) (map[string]*dynamodb.AttributeValue, errs.ChatError) {
+	input := &dynamodb.GetItemInput{
+		TableName:      aws.String(dynamoItemTable),
+		Key:            itemKey(id),
+		ConsistentRead: aws.Bool(true),
+	}
+	if len(attributes) > 0 {
+		input.ExpressionAttributeNames = map[string]*string{"#ttl": aws.String("ttl")}
+		projection := "#ttl"
+		for i, attribute := range attributes {
+			input.ExpressionAttributeNames[fmt.Sprintf("#a%d", i)] = aws.String(attribute)
+			projection += fmt.Sprintf(", #a%d", i)
+		}
+		input.ProjectionExpression = aws.String(projection)
+	}
+	result, err := d.conn.GetItemWithContext(ctx, input)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	if result.Item == nil || expiredItem(result.Item, time.Now()) {
+		return nil, nil
+	}
+	return result.Item, nil
+}
+
+func (d dynamoRepository) StringGet(ctx context.Context, tableName, key string) (string, errs.ChatError) {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	item, err := d.getItem(ctx, id, "value")
+	if err != nil {
+		return "", err
+	}
+	if item == nil || item["value"] == nil || item["value"].S == nil {
+		return "", errs.NewError(errs.ErrInternal, fmt.Errorf("%s not found", id))
+	}
+	return *item["value"].S, nil
+}
+
+// StringSet replaces the item of the key, and so its expiration, like SET in Redis.
+func (d dynamoRepository) StringSet(ctx context.Context, tx interface{}, tableName, key, value string) errs.ChatError {
+	w := tx.(*dynamoTransaction).item(fmt.Sprintf("%s:%s", tableName, key))
+	w.remove, w.replace, w.set = false, true, make(map[string]*dynamodb.AttributeValue)
+	w.item = map[string]*dynamodb.AttributeValue{"value": {S: aws.String(value)}}
+	return nil
+}
+
+// Delete removes a key, and the members when it is a set. The members are read when
+// Delete is called, and the ones added to the set by the same transaction are left out.
+func (d dynamoRepository) Delete(ctx context.Context, tx interface{}, tableName, key string) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	transaction := tx.(*dynamoTransaction)
+	w := transaction.item(id)
+	w.remove, w.replace, w.item, w.set = true, false, nil, make(map[string]*dynamodb.AttributeValue)
+
+	members, err := d.queryMembers(ctx, id, time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+	for member := range members {
+		transaction.member(id, member)
+	}
+	for _, w := range transaction.writes {
+		if w.table == dynamoSetTable && aws.StringValue(w.key["set_id"].S) == id {
+			w.remove, w.replace, w.item = true, false, nil
+		}
+	}
+	return nil
+}
+
+func (d dynamoRepository) HashGet(
+	ctx context.Context,
+	tableName, key string,
+	columns ...string,
+) (map[string]interface{}, errs.ChatError) {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	item, err := d.getItem(ctx, id, columns...)
+	if err != nil {
+		return nil, err
+	}
+	output := make(map[string]interface{})
+	for _, column := range columns {
+		if item == nil || item[column] == nil || item[column].S == nil {
+			return nil, errs.NewError(errs.ErrInternal, fmt.Errorf("%s not found in %s", column, id))
+		}
+		output[column] = *item[column].S
+	}
+	return output, nil
+}
+
+func (d dynamoRepository) HashGetEncrypted(
+	ctx context.Context,
+	tableName, key, secret string,
+) (map[string]interface{}, errs.ChatError) {
+	encryptedValues, err := d.HashGet(ctx, tableName, key, "encrypted_value")
+	if err != nil {
+		return nil, err
+	}
+	decryptedValue, errDecrypt := d.encryptor.Decrypt(encryptedValues["encrypted_value"].(string), secret)
+	if errDecrypt != nil {
+		return nil, errs.NewError(errs.ErrInternal, errDecrypt)
+	}
+
+	var output map[string]interface{}
+	errUnmarshal := json.Unmarshal([]byte(decryptedValue), &output)
+	if errUnmarshal != nil {
+		return nil, errs.NewError(errs.ErrInternal, errUnmarshal)
+	}
+	return output, nil
+}
+
+// HashSet sets the fields of a hash as attributes of its item, stored as strings like in
+// Redis. Fields named after the attributes of dynamoReservedAttributes are rejected.
+func (d dynamoRepository) HashSet(
+	ctx context.Context,
+	tx interface{},
+	tableName, key string,
+	values map[string]interface{},
+) errs.ChatError {
+	attributes := make(map[string]*dynamodb.AttributeValue, len(values))
+	for column, value := range values {
+		if slices.Contains(dynamoReservedAttributes, column) {
+			return errs.NewError(errs.ErrInternal, fmt.Errorf("field %s is reserved", column))
+		}
+		attributes[column] = &dynamodb.AttributeValue{S: aws.String(fmt.Sprint(value))}
+	}
+	tx.(*dynamoTransaction).item(fmt.Sprintf("%s:%s", tableName, key)).setAttributes(attributes)
+	return nil
+}
+
+func (d dynamoRepository) HashSetEncrypted(
+	ctx context.Context,
+	tx interface{},
+	tableName, key, secret string,
+	values map[string]interface{},
+) errs.ChatError {
+	valueByte, err := json.Marshal(values)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	encryptedValue, err := d.encryptor.Encrypt(string(valueByte), secret)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return d.HashSet(ctx, tx, tableName, key, map[string]interface{}{"encrypted_value": encryptedValue})
+}
+
+// GetTTL returns the expiration of a key, to the second. Missing keys and keys without
+// expiration return the Unix epoch, like the Redis repository.
+func (d dynamoRepository) GetTTL(ctx context.Context, tableName, key string) (time.Time, errs.ChatError) {
+	item, err := d.getItem(ctx, fmt.Sprintf("%s:%s", tableName, key), "id")
+	if err != nil {
+		return time.Time{}, err
+	}
+	if item == nil {
+		return time.Unix(0, 0), nil
+	}
+	return time.Unix(numberValue(item["ttl"]), 0), nil
+}
+
+// ExpireAt sets the expiration of a key. Unlike Redis, setting it on a missing key leaves
+// an empty item, which expires then.
+func (d dynamoRepository) ExpireAt(
+	ctx context.Context,
+	tx interface{},
+	tableName string,
+	key string,
+	at time.Time,
+) errs.ChatError {
+	w := tx.(*dynamoTransaction).item(fmt.Sprintf("%s:%s", tableName, key))
+	if !w.remove {
+		w.setAttributes(map[string]*dynamodb.AttributeValue{"ttl": unixAttribute(at)})
+	}
+	return nil
+}
+
+// SetAdd adds a member to a set, as an item expiring with the member. Adding an existing
+// member updates its expiration.
+func (d dynamoRepository) SetAdd(
+	ctx context.Context,
+	tx interface{},
+	tableName, key, member string,
+	expiresAt time.Time,
+) errs.ChatError {
+	w := tx.(*dynamoTransaction).member(fmt.Sprintf("%s:%s", tableName, key), member)
+	w.remove, w.replace = false, true
+	w.item = map[string]*dynamodb.AttributeValue{"ttl": unixAttribute(expiresAt)}
+	return nil
+}
+
+// HashIncrement increments an integer field of a hash, missing fields counting as 0, and
+// returns its new value. A missing hash has the svcError 'errs.ErrNotFound'. The field is
+// stored as a number, with an update conditioned on the item being unexpired, so concurrent
+// increments each get their own value.
+func (d dynamoRepository) HashIncrement(
+	ctx context.Context,
+	tableName, key, column string,
+) (int64, errs.ChatError) {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	if slices.Contains(dynamoReservedAttributes, column) {
+		return 0, errs.NewError(errs.ErrInternal, fmt.Errorf("field %s is reserved", column))
+	}
+	result, err := d.conn.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(dynamoItemTable),
+		Key:                 itemKey(id),
+		UpdateExpression:    aws.String("ADD #column :one"),
+		ConditionExpression: aws.String("attribute_exists(id) AND (attribute_not_exists(#ttl) OR #ttl > :now)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#column": aws.String(column),
+			"#ttl":    aws.String("ttl"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one": {N: aws.String("1")},
+			":now": unixAttribute(time.Now()),
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedNew),
+	})
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return 0, errs.NewError(errs.ErrNotFound, fmt.Errorf("%s not found", id))
+	}
+	if err != nil {
+		return 0, errs.NewError(errs.ErrInternal, err)
+	}
+	return numberValue(result.Attributes[column]), nil
+}
+
+// SetAddLimited works as SetAdd for a set holding at most limit unexpired members. When the
+// set is full, the svcError is 'errs.ErrConflict', unless evict is set, and then the members
+// expiring first are removed and returned. The members are counted and written in a
+// transaction conditioned on the version of the set, kept on the item of the set key, so
+// concurrent adds retry instead of exceeding the limit.
+func (d dynamoRepository) SetAddLimited(
+	ctx context.Context,
+	tableName, key, member string,
+	expiresAt time.Time,
+	limit int,
+	evict bool,
+) ([]string, errs.ChatError) {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	for attempt := 0; attempt < dynamoLimitedAttempts; attempt++ {
+		evicted, err := d.setAddLimited(ctx, id, member, expiresAt, limit, evict)
+		var canceled *dynamodb.TransactionCanceledException
+		if errors.As(err, &canceled) {
+			continue
+		}
+		if err != nil {
+			return nil, errs.NewError(errs.ErrInternal, err)
+		}
+		if evicted == nil {
+			return nil, errs.NewError(errs.ErrConflict, fmt.Errorf("%s holds %d members", id, limit))
+		}
+		return evicted, nil
+	}
+	return nil, errs.NewError(errs.ErrInternal, fmt.Errorf("%s changed on every attempt", id))
+}
+
+// setAddLimited runs an attempt of SetAddLimited, and returns nil members when the set is full.
+func (d dynamoRepository) setAddLimited(
+	ctx context.Context,
+	id, member string,
+	expiresAt time.Time,
+	limit int,
+	evict bool,
+) ([]string, error) {
+	result, err := d.conn.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(dynamoItemTable),
+		Key:            itemKey(id),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	version := numberValue(result.Item["version"])
+	ttl := max(numberValue(result.Item["ttl"]), expiresAt.Unix())
+
+	members, errMembers := d.queryMembers(ctx, id, time.Now(), time.Time{})
+	if errMembers != nil {
+		return nil, errMembers
+	}
+	evicted := make([]string, 0)
+	if len(members) >= limit {
+		if !evict {
+			return nil, nil
+		}
+		evicted = sortedMembers(members)[:len(members)-limit+1]
+	}
+
+	items := []*dynamodb.TransactWriteItem{
+		{Update: &dynamodb.Update{
+			TableName:                aws.String(dynamoItemTable),
+			Key:                      itemKey(id),
+			UpdateExpression:         aws.String("SET #version = :next, #ttl = :ttl"),
+			ConditionExpression:      aws.String("attribute_not_exists(#version) OR #version = :version"),
+			ExpressionAttributeNames: map[string]*string{"#version": aws.String("version"), "#ttl": aws.String("ttl")},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":version": {N: aws.String(strconv.FormatInt(version, 10))},
+				":next":    {N: aws.String(strconv.FormatInt(version+1, 10))},
+				":ttl":     {N: aws.String(strconv.FormatInt(ttl, 10))},
+			},
+		}},
+		{Put: &dynamodb.Put{
+			TableName: aws.String(dynamoSetTable),
+			Item:      map[string]*dynamodb.AttributeValue{"ttl": unixAttribute(expiresAt)},
+		}},
+	}
+	for name, value := range memberKey(id, member) {
+		items[1].Put.Item[name] = value
+	}
+	for _, evictedMember := range evicted {
+		items = append(items, &dynamodb.TransactWriteItem{
+			Delete: &dynamodb.Delete{TableName: aws.String(dynamoSetTable), Key: memberKey(id, evictedMember)},
+		})
+	}
+	_, err = d.conn.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if err != nil {
+		return nil, err
+	}
+	return evicted, nil
+}
+
+// queryMembers returns the members of a set expiring after the given time, if not zero, and
+// at or before the until time, if not zero, as unix seconds. It reads the table
+// consistently, while SetMembers lists through the GSI.
+func (d dynamoRepository) queryMembers(
+	ctx context.Context,
+	id string,
+	after, until time.Time,
+) (map[string]int64, errs.ChatError) {
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(dynamoSetTable),
+		KeyConditionExpression:    aws.String("set_id = :set_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":set_id": {S: aws.String(id)}},
+		ConsistentRead:            aws.Bool(true),
+	}
+	filter := ""
+	if !after.IsZero() {
+		filter = "#ttl > :after"
+		input.ExpressionAttributeValues[":after"] = unixAttribute(after)
+	}
+	if !until.IsZero() {
+		if filter != "" {
+			filter += " AND "
+		}
+		filter += "#ttl <= :until"
+		input.ExpressionAttributeValues[":until"] = unixAttribute(until)
+	}
+	if filter != "" {
+		input.FilterExpression = aws.String(filter)
+		input.ExpressionAttributeNames = map[string]*string{"#ttl": aws.String("ttl")}
+	}
+	return d.query(ctx, input)
+}
+
+func (d dynamoRepository) query(ctx context.Context, input *dynamodb.QueryInput) (map[string]int64, errs.ChatError) {
+	output := make(map[string]int64)
+	err := d.conn.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			output[aws.StringValue(item["member"].S)] = numberValue(item["ttl"])
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	return output, nil
+}
+
+// SetMembers returns the members of a set expiring after the given time, with their
+// expiration. The zero time returns every member not yet deleted by DynamoDB, expired or
+// not, reading the table consistently, since it is used to revoke sessions. Otherwise, the
+// members are listed through the GSI by expiration, which is eventually consistent.
+func (d dynamoRepository) SetMembers(
+	ctx context.Context,
+	tableName, key string,
+	after time.Time,
+) (map[string]time.Time, errs.ChatError) {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	var members map[string]int64
+	var err errs.ChatError
+	if after.IsZero() {
+		members, err = d.queryMembers(ctx, id, time.Time{}, time.Time{})
+	} else {
+		members, err = d.query(ctx, &dynamodb.QueryInput{
+			TableName:                aws.String(dynamoSetTable),
+			IndexName:                aws.String(dynamoSetTTLIndex),
+			KeyConditionExpression:   aws.String("set_id = :set_id AND #ttl > :after"),
+			ExpressionAttributeNames: map[string]*string{"#ttl": aws.String("ttl")},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":set_id": {S: aws.String(id)},
+				":after":  unixAttribute(after),
+			},
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	output := make(map[string]time.Time, len(members))
+	for member, ttl := range members {
+		output[member] = time.Unix(ttl, 0)
+	}
+	return output, nil
+}
+
+func (d dynamoRepository) SetRemove(
+	ctx context.Context,
+	tx interface{},
+	tableName, key string,
+	members ...string,
+) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	for _, member := range members {
+		w := tx.(*dynamoTransaction).member(id, member)
+		w.remove, w.replace, w.item = true, false, nil
+	}
+	return nil
+}
+
+// SetRemoveExpired removes the members of a set expired at the given time, read when it
+// is called.
+func (d dynamoRepository) SetRemoveExpired(
+	ctx context.Context,
+	tx interface{},
+	tableName, key string,
+	before time.Time,
+) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	members, err := d.queryMembers(ctx, id, time.Time{}, before)
+	if err != nil {
+		return err
+	}
+	for member := range members {
+		w := tx.(*dynamoTransaction).member(id, member)
+		w.remove, w.replace, w.item = true, false, nil
+	}
+	return nil
+}
+
+// NewDynamodbRepository returns the DynamoDB repository, creating its tables with TTL
+// enabled when missing.
+func NewDynamodbRepository(
+	ctx context.Context,
+	conn *dynamodb.DynamoDB,
+	encryptor encryptor.Encryptor,
+) (sessionManager.ReaderWriterRepository, errs.ChatError) {
+	tables := []*dynamodb.CreateTableInput{
+		{
+			TableName: aws.String(dynamoItemTable),
+			AttributeDefinitions: []*dynamodb.AttributeDefinition{
+				{AttributeName: aws.String("id"), AttributeType: aws.String("S")},
+			},
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{AttributeName: aws.String("id"), KeyType: aws.String("HASH")},
+			},
+			BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+		},
+		{
+			TableName: aws.String(dynamoSetTable),
+			AttributeDefinitions: []*dynamodb.AttributeDefinition{
+				{AttributeName: aws.String("set_id"), AttributeType: aws.String("S")},
+				{AttributeName: aws.String("member"), AttributeType: aws.String("S")},
+				{AttributeName: aws.String("ttl"), AttributeType: aws.String("N")},
+			},
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{AttributeName: aws.String("set_id"), KeyType: aws.String("HASH")},
+				{AttributeName: aws.String("member"), KeyType: aws.String("RANGE")},
+			},
+			GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{{
+				IndexName: aws.String(dynamoSetTTLIndex),
+				KeySchema: []*dynamodb.KeySchemaElement{
+					{AttributeName: aws.String("set_id"), KeyType: aws.String("HASH")},
+					{AttributeName: aws.String("ttl"), KeyType: aws.String("RANGE")},
+				},
+				Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeKeysOnly)},
+			}},
+			BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+		},
+	}
+	for _, table := range tables {
+		if err := createTable(ctx, conn, table); err != nil {
+			return nil, errs.NewError(errs.ErrInternal, err)
+		}
+	}
+	return &dynamoRepository{conn: conn, encryptor: encryptor}, nil
+}
+
+// createTable creates a table unless it exists, waits for it, and enables the expiration
+// of its items on the 'ttl' attribute.
+func createTable(ctx context.Context, conn *dynamodb.DynamoDB, input *dynamodb.CreateTableInput) error {
+	_, err := conn.CreateTableWithContext(ctx, input)
+	var awsErr awserr.Error
+	if err != nil && !(errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeResourceInUseException) {
+		return err
+	}
+	err = conn.WaitUntilTableExistsWithContext(ctx, &dynamodb.DescribeTableInput{TableName: input.TableName})
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.UpdateTimeToLiveWithContext(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: input.TableName,
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: aws.String("ttl"),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil && !(errors.As(err, &awsErr) && awsErr.Message() == "TimeToLive is already enabled") {
+		return err
+	}
+	return nil
+}
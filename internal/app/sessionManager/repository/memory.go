@@ -0,0 +1,465 @@
+package sessionManager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	"github.com/raffops/chat_commons/pkg/encryptor"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+// sweepInterval is how often the expired entries are removed from memory. Expired entries
+// are never returned in between, since every read checks the expiration.
+const sweepInterval = time.Minute
+
+var errWrongType = errors.New("operation against a key holding the wrong kind of value")
+
+// memoryEntry is a value stored in memory, which is a string, a hash or a sorted set, like
+// in Redis. A zero expiresAt never expires.
+type memoryEntry struct {
+	str       *string
+	hash      map[string]string
+	set       map[string]int64
+	expiresAt time.Time
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !e.expiresAt.After(now)
+}
+
+// memoryTransaction buffers the writes until it is committed, like a Redis transaction.
+type memoryTransaction struct {
+	writes []func(entries map[string]*memoryEntry, now time.Time) error
+}
+
+func (t *memoryTransaction) add(write func(entries map[string]*memoryEntry, now time.Time) error) {
+	t.writes = append(t.writes, write)
+}
+
+// memoryRepository keeps the sessions in memory, with the semantics of the Redis repository,
+// for tests and single node development. Sessions are lost on restart.
+type memoryRepository struct {
+	mu        sync.RWMutex
+	entries   map[string]*memoryEntry
+	encryptor encryptor.Encryptor
+	lastSweep time.Time
+}
+
+// get returns the entry of a key, or nil if it is missing or expired. The lock must be held.
+func (r *memoryRepository) get(id string, now time.Time) *memoryEntry {
+	entry, ok := r.entries[id]
+	if !ok || entry.expired(now) {
+		return nil
+	}
+	return entry
+}
+
+func (r *memoryRepository) BeginTransaction(ctx context.Context) (interface{}, errs.ChatError) {
+	return &memoryTransaction{}, nil
+}
+
+// CommitTransaction applies the writes of the transaction atomically. As in Redis, a write
+// failing does not stop the others, and the error of the first one is returned.
+func (r *memoryRepository) CommitTransaction(ctx context.Context, tx interface{}) errs.ChatError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var firstErr error
+	for _, write := range tx.(*memoryTransaction).writes {
+		if err := write(r.entries, now); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	tx.(*memoryTransaction).writes = nil
+	r.sweep(now)
+	if firstErr != nil {
+		return errs.NewError(errs.ErrInternal, firstErr)
+	}
+	return nil
+}
+
+func (r *memoryRepository) RollbackTransaction(ctx context.Context, tx interface{}) errs.ChatError {
+	tx.(*memoryTransaction).writes = nil
+	return nil
+}
+
+// sweep removes the expired entries, at most once every sweepInterval. The lock must be held.
+func (r *memoryRepository) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < sweepInterval {
+		return
+	}
+	for id, entry := range r.entries {
+		if entry.expired(now) {
+			delete(r.entries, id)
+		}
+	}
+	r.lastSweep = now
+}
+
+func (r *memoryRepository) StringGet(ctx context.Context, tableName, key string) (string, errs.ChatError) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry := r.get(fmt.Sprintf("%s:%s", tableName, key), time.Now())
+	if entry == nil {
+		return "", errs.NewError(errs.ErrInternal, fmt.Errorf("%s:%s not found", tableName, key))
+	}
+	if entry.str == nil {
+		return "", errs.NewError(errs.ErrInternal, errWrongType)
+	}
+	return *entry.str, nil
+}
+
+func (r *memoryRepository) StringSet(ctx context.Context, tx interface{}, tableName, key, value string) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	tx.(*memoryTransaction).add(func(entries map[string]*memoryEntry, now time.Time) error {
+		entries[id] = &memoryEntry{str: &value}
+		return nil
+	})
+	return nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, tx interface{}, tableName, key string) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	tx.(*memoryTransaction).add(func(entries map[string]*memoryEntry, now time.Time) error {
+		delete(entries, id)
+		return nil
+	})
+	return nil
+}
+
+func (r *memoryRepository) HashGet(
+	ctx context.Context,
+	tableName, key string,
+	columns ...string,
+) (map[string]interface{}, errs.ChatError) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry := r.get(fmt.Sprintf("%s:%s", tableName, key), time.Now())
+	if entry != nil && entry.hash == nil {
+		return nil, errs.NewError(errs.ErrInternal, errWrongType)
+	}
+	output := make(map[string]interface{})
+	for _, column := range columns {
+		if entry == nil {
+			return nil, errs.NewError(errs.ErrInternal, fmt.Errorf("%s:%s not found", tableName, key))
+		}
+		value, ok := entry.hash[column]
+		if !ok {
+			return nil, errs.NewError(errs.ErrInternal, fmt.Errorf("%s not found in %s:%s", column, tableName, key))
+		}
+		output[column] = value
+	}
+	return output, nil
+}
+
+func (r *memoryRepository) HashGetEncrypted(
+	ctx context.Context,
+	tableName, key, secret string,
+) (map[string]interface{}, errs.ChatError) {
+	encryptedValues, err := r.HashGet(ctx, tableName, key, "encrypted_value")
+	if err != nil {
+		return nil, err
+	}
+	decryptedValue, errDecrypt := r.encryptor.Decrypt(encryptedValues["encrypted_value"].(string), secret)
+	if errDecrypt != nil {
+		return nil, errs.NewError(errs.ErrInternal, errDecrypt)
+	}
+
+	var output map[string]interface{}
+	errUnmarshal := json.Unmarshal([]byte(decryptedValue), &output)
+	if errUnmarshal != nil {
+		return nil, errs.NewError(errs.ErrInternal, errUnmarshal)
+	}
+	return output, nil
+}
+
+func (r *memoryRepository) HashSet(
+	ctx context.Context,
+	tx interface{},
+	tableName, key string,
+	values map[string]interface{},
+) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	hash := make(map[string]string, len(values))
+	for column, value := range values {
+		hash[column] = fmt.Sprint(value)
+	}
+	tx.(*memoryTransaction).add(func(entries map[string]*memoryEntry, now time.Time) error {
+		entry, ok := entries[id]
+		if !ok || entry.expired(now) {
+			entries[id] = &memoryEntry{hash: hash}
+			return nil
+		}
+		if entry.hash == nil {
+			return errWrongType
+		}
+		for column, value := range hash {
+			entry.hash[column] = value
+		}
+		return nil
+	})
+	return nil
+}
+
+func (r *memoryRepository) HashSetEncrypted(
+	ctx context.Context,
+	tx interface{},
+	tableName, key, secret string,
+	values map[string]interface{},
+) errs.ChatError {
+	valueByte, err := json.Marshal(values)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	encryptedValue, err := r.encryptor.Encrypt(string(valueByte), secret)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return r.HashSet(ctx, tx, tableName, key, map[string]interface{}{"encrypted_value": encryptedValue})
+}
+
+// GetTTL returns the expiration of a key, to the second. Missing keys and keys without
+// expiration return the Unix epoch, like the Redis repository.
+func (r *memoryRepository) GetTTL(ctx context.Context, tableName, key string) (time.Time, errs.ChatError) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry := r.get(fmt.Sprintf("%s:%s", tableName, key), time.Now())
+	if entry == nil || entry.expiresAt.IsZero() {
+		return time.Unix(0, 0), nil
+	}
+	return time.Unix(entry.expiresAt.Unix(), 0), nil
+}
+
+func (r *memoryRepository) ExpireAt(
+	ctx context.Context,
+	tx interface{},
+	tableName string,
+	key string,
+	at time.Time,
+) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	tx.(*memoryTransaction).add(func(entries map[string]*memoryEntry, now time.Time) error {
+		if entry, ok := entries[id]; ok && !entry.expired(now) {
+			entry.expiresAt = at
+		}
+		return nil
+	})
+	return nil
+}
+
+// SetAdd adds a member to a set, scored by the expiration of the member. The set expires
+// with its last member.
+func (r *memoryRepository) SetAdd(
+	ctx context.Context,
+	tx interface{},
+	tableName, key, member string,
+	expiresAt time.Time,
+) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	tx.(*memoryTransaction).add(func(entries map[string]*memoryEntry, now time.Time) error {
+		entry, err := setEntry(entries, id, now)
+		if err != nil {
+			return err
+		}
+		addToMemorySet(entry, member, expiresAt)
+		return nil
+	})
+	return nil
+}
+
+// SetAddLimited works as SetAdd for a set holding at most limit unexpired members. When the
+// set is full, the svcError is 'errs.ErrConflict', unless evict is set, and then the members
+// expiring first are removed and returned.
+func (r *memoryRepository) SetAddLimited(
+	ctx context.Context,
+	tableName, key, member string,
+	expiresAt time.Time,
+	limit int,
+	evict bool,
+) ([]string, errs.ChatError) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	now := time.Now()
+	entry, err := setEntry(r.entries, id, now)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	removeExpiredMembers(entry, now.Unix())
+
+	evicted := make([]string, 0)
+	if len(entry.set) >= limit {
+		if !evict {
+			return nil, errs.NewError(errs.ErrConflict, fmt.Errorf("%s holds %d members", id, limit))
+		}
+		evicted = sortedMembers(entry.set)[:len(entry.set)-limit+1]
+		for _, evictedMember := range evicted {
+			delete(entry.set, evictedMember)
+		}
+	}
+	addToMemorySet(entry, member, expiresAt)
+	return evicted, nil
+}
+
+// HashIncrement increments an integer field of a hash, missing fields counting as 0, and
+// returns its new value. A missing hash has the svcError 'errs.ErrNotFound'.
+func (r *memoryRepository) HashIncrement(
+	ctx context.Context,
+	tableName, key, column string,
+) (int64, errs.ChatError) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	entry := r.get(id, time.Now())
+	if entry == nil {
+		return 0, errs.NewError(errs.ErrNotFound, fmt.Errorf("%s not found", id))
+	}
+	if entry.hash == nil {
+		return 0, errs.NewError(errs.ErrInternal, errWrongType)
+	}
+	value := int64(0)
+	if current, ok := entry.hash[column]; ok {
+		parsed, err := strconv.ParseInt(current, 10, 64)
+		if err != nil {
+			return 0, errs.NewError(errs.ErrInternal, fmt.Errorf("%s of %s is not an integer", column, id))
+		}
+		value = parsed
+	}
+	value++
+	entry.hash[column] = strconv.FormatInt(value, 10)
+	return value, nil
+}
+
+// SetMembers returns the members of a set expiring after the given time, with their
+// expiration. The zero time returns every member, expired or not.
+func (r *memoryRepository) SetMembers(
+	ctx context.Context,
+	tableName, key string,
+	after time.Time,
+) (map[string]time.Time, errs.ChatError) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	output := make(map[string]time.Time)
+	entry := r.get(fmt.Sprintf("%s:%s", tableName, key), time.Now())
+	if entry == nil {
+		return output, nil
+	}
+	if entry.set == nil {
+		return nil, errs.NewError(errs.ErrInternal, errWrongType)
+	}
+	for member, score := range entry.set {
+		if after.IsZero() || score > after.Unix() {
+			output[member] = time.Unix(score, 0)
+		}
+	}
+	return output, nil
+}
+
+func (r *memoryRepository) SetRemove(
+	ctx context.Context,
+	tx interface{},
+	tableName, key string,
+	members ...string,
+) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	tx.(*memoryTransaction).add(func(entries map[string]*memoryEntry, now time.Time) error {
+		entry, ok := entries[id]
+		if !ok || entry.expired(now) {
+			return nil
+		}
+		if entry.set == nil {
+			return errWrongType
+		}
+		for _, member := range members {
+			delete(entry.set, member)
+		}
+		return nil
+	})
+	return nil
+}
+
+// SetRemoveExpired removes the members of a set expired at the given time.
+func (r *memoryRepository) SetRemoveExpired(
+	ctx context.Context,
+	tx interface{},
+	tableName, key string,
+	before time.Time,
+) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	tx.(*memoryTransaction).add(func(entries map[string]*memoryEntry, now time.Time) error {
+		entry, ok := entries[id]
+		if !ok || entry.expired(now) {
+			return nil
+		}
+		if entry.set == nil {
+			return errWrongType
+		}
+		removeExpiredMembers(entry, before.Unix())
+		return nil
+	})
+	return nil
+}
+
+// setEntry returns the set stored on a key, created if missing. The lock must be held.
+func setEntry(entries map[string]*memoryEntry, id string, now time.Time) (*memoryEntry, error) {
+	entry, ok := entries[id]
+	if !ok || entry.expired(now) {
+		entry = &memoryEntry{set: make(map[string]int64)}
+		entries[id] = entry
+	}
+	if entry.set == nil {
+		return nil, errWrongType
+	}
+	return entry, nil
+}
+
+func addToMemorySet(entry *memoryEntry, member string, expiresAt time.Time) {
+	entry.set[member] = expiresAt.Unix()
+	if expiresAt.After(entry.expiresAt) {
+		entry.expiresAt = expiresAt
+	}
+}
+
+func removeExpiredMembers(entry *memoryEntry, before int64) {
+	for member, score := range entry.set {
+		if score <= before {
+			delete(entry.set, member)
+		}
+	}
+}
+
+// sortedMembers returns the members of a set by score, then by member, like ZRANGE.
+func sortedMembers(set map[string]int64) []string {
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	slices.SortFunc(members, func(a, b string) int {
+		if set[a] != set[b] {
+			return int(set[a] - set[b])
+		}
+		if a < b {
+			return -1
+		}
+		return 1
+	})
+	return members
+}
+
+func NewMemoryRepository(encryptor encryptor.Encryptor) sessionManager.ReaderWriterRepository {
+	return &memoryRepository{entries: make(map[string]*memoryEntry), encryptor: encryptor}
+}
@@ -0,0 +1,578 @@
+package sessionManager
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/lib/pq"
+	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	"github.com/raffops/chat_commons/pkg/encryptor"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RevocationChannel is the channel notified with the handle of every session deleted from
+// Postgres, when the transaction deleting it commits.
+const RevocationChannel = "session_revoked"
+
+// unexpired is the condition of the rows not expired yet, since the reaper deletes them
+// only every interval.
+const unexpired = "(expires_at IS NULL OR expires_at > NOW())"
+
+// postgresRepository keeps the sessions in Postgres, for deployments without Redis. Keys
+// are rows of 'public.session', with the hash fields in 'fields', and the members of the
+// sets are rows of 'public.session_set_member'. Writes run in the SQL transaction begun
+// by BeginTransaction.
+type postgresRepository struct {
+	db        *sql.DB
+	encryptor encryptor.Encryptor
+}
+
+func (p postgresRepository) BeginTransaction(ctx context.Context) (interface{}, errs.ChatError) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	return tx, nil
+}
+
+func (p postgresRepository) CommitTransaction(ctx context.Context, tx interface{}) errs.ChatError {
+	if err := tx.(*sql.Tx).Commit(); err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return nil
+}
+
+func (p postgresRepository) RollbackTransaction(ctx context.Context, tx interface{}) errs.ChatError {
+	if err := tx.(*sql.Tx).Rollback(); err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return nil
+}
+
+func (p postgresRepository) exec(
+	ctx context.Context,
+	tx interface{},
+	queryString string,
+	args []interface{},
+) errs.ChatError {
+	_, err := tx.(*sql.Tx).ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return nil
+}
+
+func (p postgresRepository) StringGet(ctx context.Context, tableName, key string) (string, errs.ChatError) {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	queryString, args := BuildGetSessionQuery(id, "value")
+	var value sql.NullString
+	err := p.db.QueryRowContext(ctx, queryString, args...).Scan(&value)
+	if err != nil {
+		return "", errs.NewError(errs.ErrInternal, err)
+	}
+	if !value.Valid {
+		return "", errs.NewError(errs.ErrInternal, fmt.Errorf("%s is not a string", id))
+	}
+	return value.String, nil
+}
+
+// StringSet replaces the value of the key, and so its expiration, like SET in Redis.
+func (p postgresRepository) StringSet(
+	ctx context.Context,
+	tx interface{},
+	tableName, key, value string,
+) errs.ChatError {
+	queryString, args := BuildStringSetQuery(fmt.Sprintf("%s:%s", tableName, key), value)
+	return p.exec(ctx, tx, queryString, args)
+}
+
+func BuildStringSetQuery(id, value string) (string, []interface{}) {
+	ib := sqlbuilder.NewInsertBuilder()
+	ib.InsertInto("public.session").
+		Cols("id", "value").
+		Values(id, value)
+	queryString, args := ib.BuildWithFlavor(sqlbuilder.PostgreSQL)
+	queryString += " ON CONFLICT (id) DO UPDATE SET value = EXCLUDED.value, fields = NULL, expires_at = NULL"
+	return queryString, args
+}
+
+// Delete removes a key, and the members when it is a set. Deleting a session notifies
+// RevocationChannel with the handle of the session, since any role of the database can
+// listen to it.
+func (p postgresRepository) Delete(ctx context.Context, tx interface{}, tableName, key string) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	queryString, args := BuildDeleteQuery("public.session", "id", id)
+	if err := p.exec(ctx, tx, queryString, args); err != nil {
+		return err
+	}
+	queryString, args = BuildDeleteQuery("public.session_set_member", "set_key", id)
+	if err := p.exec(ctx, tx, queryString, args); err != nil {
+		return err
+	}
+	if tableName != "session" {
+		return nil
+	}
+	notification := []interface{}{RevocationChannel, sessionModels.SessionHandle(key)}
+	return p.exec(ctx, tx, "SELECT pg_notify($1, $2)", notification)
+}
+
+func BuildDeleteQuery(table, column, id string) (string, []interface{}) {
+	db := sqlbuilder.NewDeleteBuilder()
+	db.DeleteFrom(table).
+		Where(db.Equal(column, id))
+	return db.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+func (p postgresRepository) HashGet(
+	ctx context.Context,
+	tableName, key string,
+	columns ...string,
+) (map[string]interface{}, errs.ChatError) {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	output := make(map[string]interface{})
+	if len(columns) == 0 {
+		return output, nil
+	}
+	queryString, args := BuildGetSessionQuery(id, "fields")
+	var fieldsJson []byte
+	err := p.db.QueryRowContext(ctx, queryString, args...).Scan(&fieldsJson)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	var fields map[string]string
+	if err = json.Unmarshal(fieldsJson, &fields); err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	for _, column := range columns {
+		value, ok := fields[column]
+		if !ok {
+			return nil, errs.NewError(errs.ErrInternal, fmt.Errorf("%s not found in %s", column, id))
+		}
+		output[column] = value
+	}
+	return output, nil
+}
+
+// BuildGetSessionQuery selects a column of the unexpired row of a key.
+func BuildGetSessionQuery(id, column string) (string, []interface{}) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select(column).
+		From("public.session").
+		Where(sb.Equal("id", id), unexpired)
+	return sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+func (p postgresRepository) HashGetEncrypted(
+	ctx context.Context,
+	tableName, key, secret string,
+) (map[string]interface{}, errs.ChatError) {
+	encryptedValues, err := p.HashGet(ctx, tableName, key, "encrypted_value")
+	if err != nil {
+		return nil, err
+	}
+	decryptedValue, errDecrypt := p.encryptor.Decrypt(encryptedValues["encrypted_value"].(string), secret)
+	if errDecrypt != nil {
+		return nil, errs.NewError(errs.ErrInternal, errDecrypt)
+	}
+
+	var output map[string]interface{}
+	errUnmarshal := json.Unmarshal([]byte(decryptedValue), &output)
+	if errUnmarshal != nil {
+		return nil, errs.NewError(errs.ErrInternal, errUnmarshal)
+	}
+	return output, nil
+}
+
+// HashSet sets the fields of a hash, stored as strings like in Redis. Setting them on an
+// expired key starts a new hash, without expiration.
+func (p postgresRepository) HashSet(
+	ctx context.Context,
+	tx interface{},
+	tableName, key string,
+	values map[string]interface{},
+) errs.ChatError {
+	fields := make(map[string]string, len(values))
+	for column, value := range values {
+		fields[column] = fmt.Sprint(value)
+	}
+	fieldsJson, err := json.Marshal(fields)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	queryString, args := BuildHashSetQuery(fmt.Sprintf("%s:%s", tableName, key), string(fieldsJson))
+	return p.exec(ctx, tx, queryString, args)
+}
+
+func BuildHashSetQuery(id, fieldsJson string) (string, []interface{}) {
+	ib := sqlbuilder.NewInsertBuilder()
+	ib.InsertInto("public.session AS s").
+		Cols("id", "fields").
+		Values(id, fieldsJson)
+	queryString, args := ib.BuildWithFlavor(sqlbuilder.PostgreSQL)
+	queryString += " ON CONFLICT (id) DO UPDATE SET " +
+		"fields = CASE WHEN s.expires_at <= NOW() THEN EXCLUDED.fields " +
+		"ELSE COALESCE(s.fields, '{}') || EXCLUDED.fields END, " +
+		"expires_at = CASE WHEN s.expires_at <= NOW() THEN NULL ELSE s.expires_at END"
+	return queryString, args
+}
+
+func (p postgresRepository) HashSetEncrypted(
+	ctx context.Context,
+	tx interface{},
+	tableName, key, secret string,
+	values map[string]interface{},
+) errs.ChatError {
+	valueByte, err := json.Marshal(values)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	encryptedValue, err := p.encryptor.Encrypt(string(valueByte), secret)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return p.HashSet(ctx, tx, tableName, key, map[string]interface{}{"encrypted_value": encryptedValue})
+}
+
+// GetTTL returns the expiration of a key, to the second. Missing keys and keys without
+// expiration return the Unix epoch, like the Redis repository.
+func (p postgresRepository) GetTTL(ctx context.Context, tableName, key string) (time.Time, errs.ChatError) {
+	queryString, args := BuildGetSessionQuery(fmt.Sprintf("%s:%s", tableName, key), "expires_at")
+	var expiresAt sql.NullTime
+	err := p.db.QueryRowContext(ctx, queryString, args...).Scan(&expiresAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, errs.NewError(errs.ErrInternal, err)
+	}
+	if !expiresAt.Valid {
+		return time.Unix(0, 0), nil
+	}
+	return time.Unix(expiresAt.Time.Unix(), 0), nil
+}
+
+// ExpireAt sets the expiration of a key. Missing or expired keys are left out.
+func (p postgresRepository) ExpireAt(
+	ctx context.Context,
+	tx interface{},
+	tableName string,
+	key string,
+	at time.Time,
+) errs.ChatError {
+	queryString, args := BuildExpireAtQuery(fmt.Sprintf("%s:%s", tableName, key), at)
+	return p.exec(ctx, tx, queryString, args)
+}
+
+func BuildExpireAtQuery(id string, at time.Time) (string, []interface{}) {
+	ub := sqlbuilder.NewUpdateBuilder()
+	ub.Update("public.session").
+		Set(ub.Assign("expires_at", at)).
+		Where(ub.Equal("id", id), unexpired)
+	return ub.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+// SetAdd adds a member to a set, as a row expiring with the member. Adding an existing
+// member updates its expiration.
+func (p postgresRepository) SetAdd(
+	ctx context.Context,
+	tx interface{},
+	tableName, key, member string,
+	expiresAt time.Time,
+) errs.ChatError {
+	queryString, args := BuildSetAddQuery(fmt.Sprintf("%s:%s", tableName, key), member, expiresAt)
+	return p.exec(ctx, tx, queryString, args)
+}
+
+// BuildSetAddQuery upserts a member, with its expiration truncated to the second like the
+// scores of the Redis sets.
+func BuildSetAddQuery(id, member string, expiresAt time.Time) (string, []interface{}) {
+	ib := sqlbuilder.NewInsertBuilder()
+	ib.InsertInto("public.session_set_member").
+		Cols("set_key", "member", "expires_at").
+		Values(id, member, time.Unix(expiresAt.Unix(), 0))
+	queryString, args := ib.BuildWithFlavor(sqlbuilder.PostgreSQL)
+	queryString += " ON CONFLICT (set_key, member) DO UPDATE SET expires_at = EXCLUDED.expires_at"
+	return queryString, args
+}
+
+// SetAddLimited works as SetAdd for a set holding at most limit unexpired members. When the
+// set is full, the svcError is 'errs.ErrConflict', unless evict is set, and then the members
+// expiring first are removed and returned. It runs in its own transaction, holding an
+// advisory lock on the set, so concurrent adds cannot exceed the limit.
+func (p postgresRepository) SetAddLimited(
+	ctx context.Context,
+	tableName, key, member string,
+	expiresAt time.Time,
+	limit int,
+	evict bool,
+) ([]string, errs.ChatError) {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtextextended($1, 0))", id)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	queryString, args := BuildSetRemoveExpiredQuery(id, time.Now())
+	if chatErr := p.exec(ctx, tx, queryString, args); chatErr != nil {
+		return nil, chatErr
+	}
+	members, chatErr := p.queryMembers(ctx, tx, id, time.Time{})
+	if chatErr != nil {
+		return nil, chatErr
+	}
+
+	evicted := make([]string, 0)
+	if len(members) >= limit {
+		if !evict {
+			return nil, errs.NewError(errs.ErrConflict, fmt.Errorf("%s holds %d members", id, limit))
+		}
+		scores := make(map[string]int64, len(members))
+		for evictedMember, expiration := range members {
+			scores[evictedMember] = expiration.Unix()
+		}
+		evicted = sortedMembers(scores)[:len(members)-limit+1]
+		queryString, args = BuildSetRemoveQuery(id, evicted)
+		if chatErr = p.exec(ctx, tx, queryString, args); chatErr != nil {
+			return nil, chatErr
+		}
+	}
+	queryString, args = BuildSetAddQuery(id, member, expiresAt)
+	if chatErr = p.exec(ctx, tx, queryString, args); chatErr != nil {
+		return nil, chatErr
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	return evicted, nil
+}
+
+// HashIncrement increments an integer field of a hash, missing fields counting as 0, and
+// returns its new value. A missing hash has the svcError 'errs.ErrNotFound'. The update
+// locks the row, so concurrent increments each get their own value.
+func (p postgresRepository) HashIncrement(
+	ctx context.Context,
+	tableName, key, column string,
+) (int64, errs.ChatError) {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	queryString, args := BuildHashIncrementQuery(id, column)
+	var fieldsJson []byte
+	err := p.db.QueryRowContext(ctx, queryString, args...).Scan(&fieldsJson)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, errs.NewError(errs.ErrNotFound, fmt.Errorf("%s not found", id))
+	}
+	if err != nil {
+		return 0, errs.NewError(errs.ErrInternal, err)
+	}
+	var fields map[string]string
+	if err = json.Unmarshal(fieldsJson, &fields); err != nil {
+		return 0, errs.NewError(errs.ErrInternal, err)
+	}
+	value, err := strconv.ParseInt(fields[column], 10, 64)
+	if err != nil {
+		return 0, errs.NewError(errs.ErrInternal, err)
+	}
+	return value, nil
+}
+
+func BuildHashIncrementQuery(id, column string) (string, []interface{}) {
+	ub := sqlbuilder.NewUpdateBuilder()
+	ub.Update("public.session").
+		Set(fmt.Sprintf(
+			"fields = COALESCE(fields, '{}') || "+
+				"jsonb_build_object(%s::text, (COALESCE((fields->>%s)::bigint, 0) + 1)::text)",
+			ub.Var(column),
+			ub.Var(column),
+		)).
+		Where(ub.Equal("id", id), unexpired)
+	queryString, args := ub.BuildWithFlavor(sqlbuilder.PostgreSQL)
+	return queryString + " RETURNING fields", args
+}
+
+// SetMembers returns the members of a set expiring after the given time, with their
+// expiration. The zero time returns every member not reaped yet, expired or not.
+func (p postgresRepository) SetMembers(
+	ctx context.Context,
+	tableName, key string,
+	after time.Time,
+) (map[string]time.Time, errs.ChatError) {
+	return p.queryMembers(ctx, p.db, fmt.Sprintf("%s:%s", tableName, key), after)
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func (p postgresRepository) queryMembers(
+	ctx context.Context,
+	db queryer,
+	id string,
+	after time.Time,
+) (map[string]time.Time, errs.ChatError) {
+	queryString, args := BuildSetMembersQuery(id, after)
+	rows, err := db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.Debug("error closing rows", zap.Error(err))
+		}
+	}(rows)
+
+	output := make(map[string]time.Time)
+	for rows.Next() {
+		var member string
+		var expiresAt time.Time
+		if err := rows.Scan(&member, &expiresAt); err != nil {
+			return nil, errs.NewError(errs.ErrInternal, err)
+		}
+		output[member] = time.Unix(expiresAt.Unix(), 0)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	return output, nil
+}
+
+func BuildSetMembersQuery(id string, after time.Time) (string, []interface{}) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("member", "expires_at").
+		From("public.session_set_member").
+		Where(sb.Equal("set_key", id))
+	if !after.IsZero() {
+		sb.Where(sb.GreaterThan("expires_at", time.Unix(after.Unix(), 0)))
+	}
+	return sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+func (p postgresRepository) SetRemove(
+	ctx context.Context,
+	tx interface{},
+	tableName, key string,
+	members ...string,
+) errs.ChatError {
+	if len(members) == 0 {
+		return nil
+	}
+	queryString, args := BuildSetRemoveQuery(fmt.Sprintf("%s:%s", tableName, key), members)
+	return p.exec(ctx, tx, queryString, args)
+}
+
+func BuildSetRemoveQuery(id string, members []string) (string, []interface{}) {
+	values := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		values = append(values, member)
+	}
+	db := sqlbuilder.NewDeleteBuilder()
+	db.DeleteFrom("public.session_set_member").
+		Where(db.Equal("set_key", id), db.In("member", values...))
+	return db.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+// SetRemoveExpired removes the members of a set expired at the given time.
+func (p postgresRepository) SetRemoveExpired(
+	ctx context.Context,
+	tx interface{},
+	tableName, key string,
+	before time.Time,
+) errs.ChatError {
+	queryString, args := BuildSetRemoveExpiredQuery(fmt.Sprintf("%s:%s", tableName, key), before)
+	return p.exec(ctx, tx, queryString, args)
+}
+
+func BuildSetRemoveExpiredQuery(id string, before time.Time) (string, []interface{}) {
+	db := sqlbuilder.NewDeleteBuilder()
+	db.DeleteFrom("public.session_set_member").
+		Where(db.Equal("set_key", id), db.LessEqualThan("expires_at", before))
+	return db.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+// ReapExpiredSessions deletes the expired keys and set members, which reads already leave
+// out, and returns how many rows were deleted.
+func ReapExpiredSessions(ctx context.Context, db *sql.DB) (int64, errs.ChatError) {
+	reaped := int64(0)
+	for _, table := range []string{"public.session", "public.session_set_member"} {
+		result, err := db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE expires_at <= NOW()", table))
+		if err != nil {
+			return reaped, errs.NewError(errs.ErrInternal, err)
+		}
+		rows, _ := result.RowsAffected()
+		reaped += rows
+	}
+	return reaped, nil
+}
+
+// StartReaping reaps the expired sessions at every interval until the context is done.
+func StartReaping(ctx context.Context, db *sql.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reaped, err := ReapExpiredSessions(ctx, db)
+				switch {
+				case err != nil:
+					logger.Error("cannot reap expired sessions", zap.Error(err))
+				case reaped > 0:
+					logger.Debug("expired sessions reaped", zap.Int64("rows", reaped))
+				}
+			}
+		}
+	}()
+}
+
+// ListenRevocations calls onRevoke with the handle of every session deleted from Postgres, by
+// any instance, until the context is done. Notifications sent while the connection is
+// lost are missed.
+func ListenRevocations(ctx context.Context, dsn string, onRevoke func(handle string)) errs.ChatError {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Error("session revocation listener failed", zap.Error(err))
+		}
+	})
+	if err := listener.Listen(RevocationChannel); err != nil {
+		_ = listener.Close()
+		return errs.NewError(errs.ErrInternal, err)
+	}
+
+	go func() {
+		ping := time.NewTicker(time.Minute)
+		defer ping.Stop()
+		defer listener.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case notification := <-listener.Notify:
+				// A nil notification means the connection was reestablished.
+				if notification != nil {
+					onRevoke(notification.Extra)
+				}
+			case <-ping.C:
+				if err := listener.Ping(); err != nil {
+					logger.Debug("session revocation listener ping failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func NewPostgresRepository(db *sql.DB, encryptor encryptor.Encryptor) sessionManager.ReaderWriterRepository {
+	return &postgresRepository{db: db, encryptor: encryptor}
+}
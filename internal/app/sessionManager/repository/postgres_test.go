@@ -0,0 +1,93 @@
+package sessionManager
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildQueries(t *testing.T) {
+	expiresAt := time.Date(2024, time.September, 25, 10, 0, 0, 0, time.UTC)
+	getQuery, getArgs := BuildGetSessionQuery("session:1", "fields")
+	hashSetQuery, hashSetArgs := BuildHashSetQuery("session:1", `{"encrypted_value":"value"}`)
+	hashIncrementQuery, hashIncrementArgs := BuildHashIncrementQuery("refresh_token:1", "rotations")
+	expireAtQuery, expireAtArgs := BuildExpireAtQuery("session:1", expiresAt)
+	setAddQuery, setAddArgs := BuildSetAddQuery("user_sessions:1", "1", expiresAt.Add(time.Millisecond))
+	setMembersQuery, setMembersArgs := BuildSetMembersQuery("user_sessions:1", expiresAt)
+	setRemoveQuery, setRemoveArgs := BuildSetRemoveQuery("user_sessions:1", []string{"1", "2"})
+	tests := []struct {
+		name     string
+		got      string
+		gotArgs  []interface{}
+		want     string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "Test get session query",
+			got:      getQuery,
+			gotArgs:  getArgs,
+			want:     "SELECT fields FROM public.session WHERE id = $1 AND (expires_at IS NULL OR expires_at > NOW())",
+			wantArgs: []interface{}{"session:1"},
+		},
+		{
+			name:    "Test hash set query",
+			got:     hashSetQuery,
+			gotArgs: hashSetArgs,
+			want: "INSERT INTO public.session AS s (id, fields) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET " +
+				"fields = CASE WHEN s.expires_at <= NOW() THEN EXCLUDED.fields " +
+				"ELSE COALESCE(s.fields, '{}') || EXCLUDED.fields END, " +
+				"expires_at = CASE WHEN s.expires_at <= NOW() THEN NULL ELSE s.expires_at END",
+			wantArgs: []interface{}{"session:1", `{"encrypted_value":"value"}`},
+		},
+		{
+			name:    "Test hash increment query",
+			got:     hashIncrementQuery,
+			gotArgs: hashIncrementArgs,
+			want: "UPDATE public.session SET fields = COALESCE(fields, '{}') || " +
+				"jsonb_build_object($1::text, (COALESCE((fields->>$2)::bigint, 0) + 1)::text) " +
+				"WHERE id = $3 AND (expires_at IS NULL OR expires_at > NOW()) RETURNING fields",
+			wantArgs: []interface{}{"rotations", "rotations", "refresh_token:1"},
+		},
+		{
+			name:    "Test expire at query",
+			got:     expireAtQuery,
+			gotArgs: expireAtArgs,
+			want: "UPDATE public.session SET expires_at = $1 " +
+				"WHERE id = $2 AND (expires_at IS NULL OR expires_at > NOW())",
+			wantArgs: []interface{}{expiresAt, "session:1"},
+		},
+		{
+			name:    "Test set add query",
+			got:     setAddQuery,
+			gotArgs: setAddArgs,
+			want: "INSERT INTO public.session_set_member (set_key, member, expires_at) VALUES ($1, $2, $3) " +
+				"ON CONFLICT (set_key, member) DO UPDATE SET expires_at = EXCLUDED.expires_at",
+			wantArgs: []interface{}{"user_sessions:1", "1", time.Unix(expiresAt.Unix(), 0)},
+		},
+		{
+			name:    "Test set members query",
+			got:     setMembersQuery,
+			gotArgs: setMembersArgs,
+			want: "SELECT member, expires_at FROM public.session_set_member " +
+				"WHERE set_key = $1 AND expires_at > $2",
+			wantArgs: []interface{}{"user_sessions:1", time.Unix(expiresAt.Unix(), 0)},
+		},
+		{
+			name:     "Test set remove query",
+			got:      setRemoveQuery,
+			gotArgs:  setRemoveArgs,
+			want:     "DELETE FROM public.session_set_member WHERE set_key = $1 AND member IN ($2, $3)",
+			wantArgs: []interface{}{"user_sessions:1", "1", "2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("query \n\tgot = %v\n\twant = %v", tt.got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.gotArgs, tt.wantArgs) {
+				t.Errorf("args got = %v, want %v", tt.gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -13,19 +13,46 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// setAddLimitedScript adds a member to a sorted set scored by expiration, unless the set
+// holds as many unexpired members as the limit. Then, it returns nil, or evicts the members
+// expiring first to make room when ARGV[5] is '1', and returns them.
+//
+//	KEYS[1] the set, ARGV[1] now, ARGV[2] member, ARGV[3] expiresAt, ARGV[4] limit
+var setAddLimitedScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+local count = redis.call('ZCARD', KEYS[1])
+local limit = tonumber(ARGV[4])
+local evicted = {}
+if count >= limit then
+	if ARGV[5] ~= '1' then
+		return false
+	end
+	evicted = redis.call('ZRANGE', KEYS[1], 0, count - limit)
+	redis.call('ZREM', KEYS[1], unpack(evicted))
+end
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[2])
+if redis.call('EXPIRETIME', KEYS[1]) < tonumber(ARGV[3]) then
+	redis.call('EXPIREAT', KEYS[1], ARGV[3])
+end
+return evicted
+`)
+
+// hashIncrementScript increments a field of a hash, unless the hash is missing, since
+// HINCRBY alone would create it without expiration.
+//
+//	KEYS[1] the hash, ARGV[1] field
+var hashIncrementScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+return redis.call('HINCRBY', KEYS[1], ARGV[1], 1)
+`)
+
 type redisRepository struct {
 	db        *redis.Client
 	encryptor encryptor.Encryptor
 }
 
-func (r redisRepository) GetKeys(ctx context.Context, pattern string) ([]string, errs.ChatError) {
-	keys, err := r.db.Keys(ctx, pattern).Result()
-	if err != nil {
-		return nil, errs.NewError(errs.ErrInternal, err)
-	}
-	return keys, nil
-}
-
 func (r redisRepository) BeginTransaction(ctx context.Context) (interface{}, errs.ChatError) {
 	defer func() (interface{}, errs.ChatError) {
 		if r := recover(); r != nil {
@@ -177,6 +204,136 @@ func (r redisRepository) ExpireAt(
 	return nil
 }
 
+// SetAdd adds a member to a set, stored as a sorted set scored by the expiration of the
+// member. Adding an existing member updates its expiration. The set expires with its last
+// member.
+func (r redisRepository) SetAdd(
+	ctx context.Context,
+	tx interface{},
+	tableName, key, member string,
+	expiresAt time.Time,
+) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	err := addToSet(ctx, tx.(redis.Pipeliner), id, member, expiresAt)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return nil
+}
+
+func addToSet(ctx context.Context, db redis.Pipeliner, id, member string, expiresAt time.Time) error {
+	err := db.ZAdd(ctx, id, redis.Z{Score: float64(expiresAt.Unix()), Member: member}).Err()
+	if err != nil {
+		return err
+	}
+	// EXPIRE GT treats a set without expiration as never expiring, so NX sets it first.
+	err = db.ExpireNX(ctx, id, time.Until(expiresAt)).Err()
+	if err != nil {
+		return err
+	}
+	return db.ExpireGT(ctx, id, time.Until(expiresAt)).Err()
+}
+
+// SetAddLimited works as SetAdd for a set holding at most limit unexpired members, checked
+// atomically. When the set is full, the svcError is 'errs.ErrConflict', unless evict is set,
+// and then the members expiring first are removed and returned. It runs out of any
+// transaction, since the members must be counted when it runs.
+func (r redisRepository) SetAddLimited(
+	ctx context.Context,
+	tableName, key, member string,
+	expiresAt time.Time,
+	limit int,
+	evict bool,
+) ([]string, errs.ChatError) {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	evictFlag := "0"
+	if evict {
+		evictFlag = "1"
+	}
+	args := []interface{}{time.Now().Unix(), member, expiresAt.Unix(), limit, evictFlag}
+	evicted, err := setAddLimitedScript.Run(ctx, r.db, []string{id}, args...).StringSlice()
+	if errors.Is(err, redis.Nil) {
+		return nil, errs.NewError(errs.ErrConflict, fmt.Errorf("%s holds %d members", id, limit))
+	}
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	return evicted, nil
+}
+
+// HashIncrement increments an integer field of a hash, missing fields counting as 0, and
+// returns its new value. A missing hash has the svcError 'errs.ErrNotFound'. It runs out of
+// any transaction, so concurrent increments each get their own value.
+func (r redisRepository) HashIncrement(ctx context.Context, tableName, key, column string) (int64, errs.ChatError) {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	value, err := hashIncrementScript.Run(ctx, r.db, []string{id}, column).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, errs.NewError(errs.ErrNotFound, fmt.Errorf("%s not found", id))
+	}
+	if err != nil {
+		return 0, errs.NewError(errs.ErrInternal, err)
+	}
+	return value, nil
+}
+
+// SetMembers returns the members of a set expiring after the given time, with their
+// expiration. The zero time returns every member, expired or not.
+func (r redisRepository) SetMembers(
+	ctx context.Context,
+	tableName, key string,
+	after time.Time,
+) (map[string]time.Time, errs.ChatError) {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	from := "-inf"
+	if !after.IsZero() {
+		from = fmt.Sprintf("(%d", after.Unix())
+	}
+	members, err := r.db.ZRangeByScoreWithScores(ctx, id, &redis.ZRangeBy{Min: from, Max: "+inf"}).Result()
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	output := make(map[string]time.Time, len(members))
+	for _, member := range members {
+		output[member.Member.(string)] = time.Unix(int64(member.Score), 0)
+	}
+	return output, nil
+}
+
+func (r redisRepository) SetRemove(
+	ctx context.Context,
+	tx interface{},
+	tableName, key string,
+	members ...string,
+) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	db := tx.(redis.Pipeliner)
+	values := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		values = append(values, member)
+	}
+	err := db.ZRem(ctx, id, values...).Err()
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return nil
+}
+
+// SetRemoveExpired removes the members of a set expired at the given time.
+func (r redisRepository) SetRemoveExpired(
+	ctx context.Context,
+	tx interface{},
+	tableName, key string,
+	before time.Time,
+) errs.ChatError {
+	id := fmt.Sprintf("%s:%s", tableName, key)
+	db := tx.(redis.Pipeliner)
+	err := db.ZRemRangeByScore(ctx, id, "-inf", fmt.Sprintf("%d", before.Unix())).Err()
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return nil
+}
+
 func NewRedisRepository(db *redis.Client, encryptor encryptor.Encryptor) sessionManager.ReaderWriterRepository {
 	return &redisRepository{db: db, encryptor: encryptor}
 }
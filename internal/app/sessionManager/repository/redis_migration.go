@@ -0,0 +1,70 @@
+package sessionManager
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/redis/go-redis/v9"
+)
+
+// scanCount is the number of keys walked by each SCAN of the migration.
+const scanCount = 1000
+
+// MigrateSessionIndex moves the index of sessions created before the sets of sessions by
+// user and of refresh tokens by session:
+//
+//	user_session:<userId>:<sessionId>    added to user_sessions:<userId>, and kept for its metadata
+//	refresh_family:<sessionId>:<hash>    added to refresh_family:<sessionId>, and deleted
+//
+// Keys are walked with SCAN, so Redis is not blocked, and entries keep their expiration.
+// Migrating again is harmless. It returns how many entries were indexed.
+func MigrateSessionIndex(ctx context.Context, db *redis.Client) (int, errs.ChatError) {
+	sessions, err := migrateIndex(ctx, db, "user_session", "user_sessions", false)
+	if err != nil {
+		return sessions, err
+	}
+	tokens, err := migrateIndex(ctx, db, "refresh_family", "refresh_family", true)
+	return sessions + tokens, err
+}
+
+// migrateIndex adds every '<fromTable>:<key>:<member>' key to the '<toTable>:<key>' set,
+// scored by the expiration of the key. Keys without expiration are left out, since the
+// entries of the former index always had one.
+func migrateIndex(
+	ctx context.Context,
+	db *redis.Client,
+	fromTable, toTable string,
+	deleteMigrated bool,
+) (int, errs.ChatError) {
+	migrated := 0
+	iterator := db.Scan(ctx, 0, fromTable+":*", scanCount).Iterator()
+	for iterator.Next(ctx) {
+		parts := strings.Split(iterator.Val(), ":")
+		if len(parts) != 3 {
+			continue
+		}
+		expireTime := db.ExpireTime(ctx, iterator.Val()).Val()
+		if expireTime <= 0 {
+			continue
+		}
+
+		pipe := db.TxPipeline()
+		err := addToSet(ctx, pipe, toTable+":"+parts[1], parts[2], time.Unix(int64(expireTime.Seconds()), 0))
+		if err != nil {
+			return migrated, errs.NewError(errs.ErrInternal, err)
+		}
+		if deleteMigrated {
+			pipe.Del(ctx, iterator.Val())
+		}
+		if _, err = pipe.Exec(ctx); err != nil {
+			return migrated, errs.NewError(errs.ErrInternal, err)
+		}
+		migrated++
+	}
+	if err := iterator.Err(); err != nil {
+		return migrated, errs.NewError(errs.ErrInternal, err)
+	}
+	return migrated, nil
+}
@@ -4,11 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strings"
 	"time"
 
 	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/authz"
 	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
 	"github.com/raffops/chat_commons/pkg/errs"
 	"github.com/raffops/chat_commons/pkg/logger"
 	"github.com/raffops/chat_commons/pkg/uuid"
@@ -16,15 +17,19 @@ import (
 )
 
 type service struct {
-	repo              sessionManager.ReaderWriterRepository
-	timeout           time.Duration
-	secret            string
-	mapMethodsToRoles map[string][]authModels.RoleId
+	repo           sessionManager.ReaderWriterRepository
+	timeout        time.Duration
+	refreshTimeout time.Duration
+	secret         string
+	policySrv      authz.PolicyService
+	limits         map[authModels.RoleId]sessionModels.Limit
+	expirations    map[authModels.RoleId]sessionModels.Expiration
 }
 
+// FinishUserSessions revokes every session of a user, with their refresh tokens, in a
+// single transaction.
 func (s service) FinishUserSessions(ctx context.Context, userId string) errs.ChatError {
-	pattern := fmt.Sprintf("user_session:%s:*", userId)
-	userSessions, err := s.repo.GetKeys(ctx, pattern)
+	userSessions, err := s.repo.SetMembers(ctx, "user_sessions", userId, time.Time{})
 	if err != nil {
 		return err
 	}
@@ -35,9 +40,7 @@ func (s service) FinishUserSessions(ctx context.Context, userId string) errs.Cha
 	}
 	defer s.repo.RollbackTransaction(ctx, sessionRepoTx)
 
-	prefix := "user_session:" + userId + ":"
-	for _, userSession := range userSessions {
-		session, _ := strings.CutPrefix(userSession, prefix)
+	for session := range userSessions {
 		err = s.repo.Delete(ctx, sessionRepoTx, "session", session)
 		if err != nil {
 			return err
@@ -46,6 +49,14 @@ func (s service) FinishUserSessions(ctx context.Context, userId string) errs.Cha
 		if err != nil {
 			return err
 		}
+		err = s.revokeRefreshFamily(ctx, sessionRepoTx, session)
+		if err != nil {
+			return err
+		}
+	}
+	err = s.repo.Delete(ctx, sessionRepoTx, "user_sessions", userId)
+	if err != nil {
+		return err
 	}
 	return s.repo.CommitTransaction(ctx, sessionRepoTx)
 }
@@ -54,21 +65,21 @@ func (s service) GetSession(ctx context.Context, sessionId string) (map[string]i
 	return s.repo.HashGetEncrypted(ctx, "session", sessionId, s.secret)
 }
 
-func (s service) SetRoles(method string, roles []authModels.RoleId) {
-	s.mapMethodsToRoles[method] = roles
+// SetRoles replaces the roles allowed to call a gRPC method. The policy is persisted,
+// so it is shared by every instance and survives restarts.
+func (s service) SetRoles(ctx context.Context, method string, roles []authModels.RoleId) errs.ChatError {
+	return s.policySrv.SetRoles(ctx, method, roles)
 }
 
 func (s service) GetRoles(ctx context.Context, method string) ([]authModels.RoleId, errs.ChatError) {
-	if roles, ok := s.mapMethodsToRoles[method]; ok {
-		return roles, nil
-	}
-	return nil, errs.NewError(errs.ErrNotFound, fmt.Errorf("method not found"))
+	return s.policySrv.GetRoles(ctx, method)
 }
 
 func sanityCheck() {
 	envVariables := []string{
 		"SESSION_TIMEOUT",
 		"SESSION_MANAGER_SECRET",
+		"REFRESH_TOKEN_TIMEOUT",
 	}
 	for _, envVariable := range envVariables {
 		if _, ok := os.LookupEnv(envVariable); !ok {
@@ -89,13 +100,12 @@ func (s service) RefreshSession(ctx context.Context, sessionId string) errs.Chat
 		return err
 	}
 
-	_, ok := sessionValues["user_id"].(string)
+	userId, ok := sessionValues["user_id"].(string)
 	if !ok {
 		return errs.NewError(errs.ErrInternal, fmt.Errorf("user_id not found"))
 	}
 
-	timeoutAt := time.Now().Add(s.timeout)
-	err = s.repo.ExpireAt(ctx, tx, "session", sessionId, timeoutAt)
+	err = s.extendSession(ctx, tx, userId, sessionId, s.nextExpiration(sessionValues, time.Now()))
 	if err != nil {
 		return err
 	}
@@ -103,46 +113,97 @@ func (s service) RefreshSession(ctx context.Context, sessionId string) errs.Chat
 	return s.repo.CommitTransaction(ctx, tx)
 }
 
+// CreateSession creates a session for the user and returns its id. If the role of the
+// user has a session limit, the session is indexed within the limit before it is written,
+// so a rejected session is never usable, and the ids of the sessions evicted to respect
+// the limit are returned.
 func (s service) CreateSession(
 	ctx context.Context,
 	userId string,
 	payload map[string]interface{},
-) (string, errs.ChatError) {
+) (string, []string, errs.ChatError) {
 	payload["user_id"] = userId
 	sessionId := generateRandomSessionId()
+	now := time.Now()
+	s.setAbsoluteExpiration(payload, now)
+	timeoutAt := s.nextExpiration(payload, now)
+	limit, limited := s.limits[payloadRole(payload)]
+
+	var evicted []string
+	if limited {
+		var err errs.ChatError
+		evicted, err = s.indexLimitedSession(ctx, userId, sessionId, timeoutAt, limit)
+		if err != nil {
+			return sessionId, evicted, err
+		}
+	}
 
+	err := s.writeSession(ctx, userId, sessionId, payload, now, timeoutAt, !limited)
+	if err != nil && limited {
+		if errRevoke := s.revokeSession(ctx, userId, sessionId); errRevoke != nil {
+			logger.Error("cannot unindex session", zap.String("user_id", userId), zap.Error(errRevoke))
+		}
+	}
+	return sessionId, evicted, err
+}
+
+// writeSession stores a new session and its metadata until timeoutAt. If index is set, the
+// session is also added to the index of the user, which is otherwise done by
+// 'indexLimitedSession'.
+func (s service) writeSession(
+	ctx context.Context,
+	userId, sessionId string,
+	payload map[string]interface{},
+	now, timeoutAt time.Time,
+	index bool,
+) errs.ChatError {
 	tx, err := s.repo.BeginTransaction(ctx)
 	if tx == nil {
-		return sessionId, errs.NewError(errs.ErrInternal, fmt.Errorf("transaction is nil"))
+		return errs.NewError(errs.ErrInternal, fmt.Errorf("transaction is nil"))
 	}
 
 	if err != nil {
-		return sessionId, err
+		return err
 	}
 
 	defer s.repo.RollbackTransaction(ctx, tx)
 
 	err = s.repo.HashSetEncrypted(ctx, tx, "session", sessionId, s.secret, payload)
 	if err != nil {
-		return sessionId, err
+		return err
 	}
-	err = s.repo.StringSet(ctx, tx, fmt.Sprintf("user_session:%s", userId), sessionId, "")
+	err = s.repo.HashSetEncrypted(
+		ctx,
+		tx,
+		fmt.Sprintf("user_session:%s", userId),
+		sessionId,
+		s.secret,
+		newSessionMetadata(ctx, payload),
+	)
 	if err != nil {
-		return sessionId, err
+		return err
 	}
 	// TODO maybe async?
-	timeoutAt := time.Now().Add(s.timeout)
 	err = s.repo.ExpireAt(ctx, tx, "session", sessionId, timeoutAt)
 	if err != nil {
-		return sessionId, err
+		return err
 	}
 	err = s.repo.ExpireAt(ctx, tx, fmt.Sprintf("user_session:%s", userId), sessionId, timeoutAt)
 	if err != nil {
-		return sessionId, err
+		return err
+	}
+	if index {
+		err = s.repo.SetRemoveExpired(ctx, tx, "user_sessions", userId, now)
+		if err != nil {
+			return err
+		}
+		err = s.repo.SetAdd(ctx, tx, "user_sessions", userId, sessionId, timeoutAt)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = s.repo.CommitTransaction(ctx, tx)
-	return sessionId, err
+	return s.repo.CommitTransaction(ctx, tx)
 }
 
 func (s service) FinishSession(ctx context.Context, sessionId string) errs.ChatError {
@@ -153,31 +214,27 @@ func (s service) FinishSession(ctx context.Context, sessionId string) errs.ChatE
 	}
 	userId := session["user_id"].(string)
 
-	tx, err := s.repo.BeginTransaction(ctx)
-	if err != nil {
-		return err
-	}
-	defer s.repo.RollbackTransaction(ctx, tx)
-
-	err = s.repo.Delete(ctx, tx, "session", sessionId)
-	if err != nil {
-		return err
-	}
-	err = s.repo.Delete(ctx, tx, fmt.Sprintf("user_session:%s", userId), sessionId)
-	if err != nil {
-		return err
-	}
-
-	return s.repo.CommitTransaction(ctx, tx)
+	return s.revokeSession(ctx, userId, sessionId)
 }
 
-func NewDefaultService(repo sessionManager.ReaderWriterRepository, timeout time.Duration, secret string) sessionManager.Service {
+func NewDefaultService(
+	repo sessionManager.ReaderWriterRepository,
+	timeout time.Duration,
+	refreshTimeout time.Duration,
+	secret string,
+	policySrv authz.PolicyService,
+	limits map[authModels.RoleId]sessionModels.Limit,
+	expirations map[authModels.RoleId]sessionModels.Expiration,
+) sessionManager.Service {
 	sanityCheck()
 	return &service{
-		repo:              repo,
-		timeout:           timeout,
-		secret:            secret,
-		mapMethodsToRoles: map[string][]authModels.RoleId{},
+		repo:           repo,
+		timeout:        timeout,
+		refreshTimeout: refreshTimeout,
+		secret:         secret,
+		policySrv:      policySrv,
+		limits:         limits,
+		expirations:    expirations,
 	}
 }
 
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// A session expires after the idle timeout of its role, extended by every authenticated
+// call and refresh, and never after its absolute expiration, stored in the payload as
+// 'expires_at' when the role has a max lifetime:
+//
+//	session:<sessionId>    encrypted {user_id, role, ..., expires_at}
+
+// expiration returns the expiration of the role of a session, or the default idle timeout
+// without max lifetime for roles without one.
+func (s service) expiration(session map[string]interface{}) sessionModels.Expiration {
+	expiration, ok := s.expirations[payloadRole(session)]
+	if !ok {
+		return sessionModels.Expiration{Idle: s.timeout}
+	}
+	return expiration
+}
+
+// setAbsoluteExpiration stores in the payload of a new session its absolute expiration, if
+// its role has a max lifetime.
+func (s service) setAbsoluteExpiration(payload map[string]interface{}, now time.Time) {
+	if maxLifetime := s.expiration(payload).MaxLifetime; maxLifetime > 0 {
+		payload["expires_at"] = now.Add(maxLifetime).Unix()
+	}
+}
+
+// nextExpiration returns when a session expires if extended now.
+func (s service) nextExpiration(session map[string]interface{}, now time.Time) time.Time {
+	timeoutAt := now.Add(s.expiration(session).Idle)
+	var absolute time.Time
+	switch expiresAt := session["expires_at"].(type) {
+	case int64:
+		absolute = time.Unix(expiresAt, 0)
+	case float64:
+		absolute = time.Unix(int64(expiresAt), 0)
+	default:
+		return timeoutAt
+	}
+	if absolute.Before(timeoutAt) {
+		return absolute
+	}
+	return timeoutAt
+}
+
+// extendSession adds to the transaction the new expiration of the session and of its index
+// entries.
+func (s service) extendSession(
+	ctx context.Context,
+	tx interface{},
+	userId, sessionId string,
+	timeoutAt time.Time,
+) errs.ChatError {
+	err := s.repo.ExpireAt(ctx, tx, "session", sessionId, timeoutAt)
+	if err != nil {
+		return err
+	}
+	err = s.repo.ExpireAt(ctx, tx, fmt.Sprintf("user_session:%s", userId), sessionId, timeoutAt)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetAdd(ctx, tx, "user_sessions", userId, sessionId, timeoutAt)
+}
+
+// touchSession slides the idle timeout of a session on an authenticated call. Failing to
+// extend it does not fail the call, since the session is still valid. Failures are logged
+// with the handle of the session, never its id.
+func (s service) touchSession(
+	ctx context.Context,
+	principal sessionModels.Principal,
+	session map[string]interface{},
+) {
+	handle := zap.String("session", sessionModels.SessionHandle(principal.SessionId))
+	tx, err := s.repo.BeginTransaction(ctx)
+	if err != nil {
+		logger.Error("cannot extend session", handle, zap.Error(err))
+		return
+	}
+	defer s.repo.RollbackTransaction(ctx, tx)
+
+	err = s.extendSession(ctx, tx, principal.UserId, principal.SessionId, s.nextExpiration(session, time.Now()))
+	if err == nil {
+		err = s.repo.CommitTransaction(ctx, tx)
+	}
+	if err != nil {
+		logger.Error("cannot extend session", handle, zap.Error(err))
+	}
+}
@@ -1,11 +1,13 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"slices"
 	"time"
 
 	auth "github.com/raffops/chat_auth/internal/app/auth/model"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	"github.com/raffops/chat_commons/pkg/errs"
 	"github.com/raffops/chat_commons/pkg/logger"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -15,9 +17,14 @@ import (
 )
 
 // wrappedStream wraps around the embedded grpc.ServerStream, and intercepts the RecvMsg and
-// SendMsg method call.
+// SendMsg method call. Its context carries the principal of the caller.
 type wrappedStream struct {
 	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
 }
 
 func (w *wrappedStream) RecvMsg(m any) error {
@@ -36,8 +43,8 @@ func (w *wrappedStream) SendMsg(m any) error {
 	return w.ServerStream.SendMsg(m)
 }
 
-func newWrappedStream(s grpc.ServerStream) grpc.ServerStream {
-	return &wrappedStream{s}
+func newWrappedStream(s grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	return &wrappedStream{ServerStream: s, ctx: ctx}
 }
 
 func (s service) CheckGrpcSession(
@@ -46,26 +53,71 @@ func (s service) CheckGrpcSession(
 	info *grpc.StreamServerInfo,
 	handler grpc.StreamHandler,
 ) error {
+	principal, err := s.grpcPrincipal(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	ctx := sessionModels.ContextWithPrincipal(ss.Context(), principal)
+	return handler(srv, newWrappedStream(ss, ctx))
+}
+
+// CheckGrpcUnarySession is the unary counterpart of CheckGrpcSession.
+func (s service) CheckGrpcUnarySession(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	principal, err := s.grpcPrincipal(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(sessionModels.ContextWithPrincipal(ctx, principal), req)
+}
+
+// grpcPrincipal authenticates the session sent in the 'authorization' metadata, and checks
+// that its role is allowed to call the method. The idle timeout of the session is extended.
+func (s service) grpcPrincipal(ctx context.Context, method string) (sessionModels.Principal, error) {
 	// authentication (token verification)
-	md, ok := metadata.FromIncomingContext(ss.Context())
+	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return status.Errorf(codes.PermissionDenied, "missing metadata")
+		return sessionModels.Principal{}, status.Errorf(codes.PermissionDenied, "missing metadata")
 	}
 	token := md["authorization"]
 	if len(token) == 0 {
-		return status.Errorf(codes.PermissionDenied, "missing token")
+		return sessionModels.Principal{}, status.Errorf(codes.PermissionDenied, "missing token")
+	}
+	result, err := s.repo.HashGetEncrypted(ctx, "session", token[0], s.secret)
+	if err != nil {
+		return sessionModels.Principal{}, status.Errorf(codes.PermissionDenied, "invalid token")
 	}
-	result, err := s.repo.HashGetEncrypted(ss.Context(), "session", token[0], s.secret)
+	principal, err := newPrincipal(token[0], result)
 	if err != nil {
-		return status.Errorf(codes.PermissionDenied, "invalid token")
+		return sessionModels.Principal{}, status.Errorf(codes.PermissionDenied, "invalid token")
 	}
 
-	if slices.Contains(
-		s.mapMethodsToRoles[info.FullMethod],
-		auth.RoleId(int(result["role"].(float64))),
-	) {
-		err := handler(srv, newWrappedStream(ss))
-		return err
+	if !s.policySrv.IsAllowed(ctx, method, principal.Role) {
+		return sessionModels.Principal{}, status.Errorf(codes.PermissionDenied, "invalid role")
+	}
+	s.touchSession(ctx, principal, result)
+	return principal, nil
+}
+
+// newPrincipal reads the principal from the values of a session.
+func newPrincipal(sessionId string, session map[string]interface{}) (sessionModels.Principal, errs.ChatError) {
+	userId, ok := session["user_id"].(string)
+	if !ok {
+		return sessionModels.Principal{}, errs.NewError(errs.ErrInternal, fmt.Errorf("user_id not found"))
+	}
+	role, ok := session["role"].(float64)
+	if !ok {
+		return sessionModels.Principal{}, errs.NewError(errs.ErrInternal, fmt.Errorf("role not found"))
 	}
-	return status.Errorf(codes.PermissionDenied, "invalid role")
+	mfa, _ := session["mfa"].(bool)
+	return sessionModels.Principal{
+		UserId:    userId,
+		Role:      auth.RoleId(role),
+		SessionId: sessionId,
+		Mfa:       mfa,
+	}, nil
 }
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+// indexLimitedSession adds a new session to the index of the user, within the session
+// limit of its role. The limit is checked atomically by the repository, so concurrent
+// logins cannot exceed it.
+//
+// It returns the sessions evicted for the new one, which are revoked. If the limit
+// rejects the session, the svcError is 'errs.ErrConflict'. It runs before the session is
+// written, so a rejected session has nothing to revoke.
+func (s service) indexLimitedSession(
+	ctx context.Context,
+	userId, sessionId string,
+	timeoutAt time.Time,
+	limit sessionModels.Limit,
+) ([]string, errs.ChatError) {
+	evicted, err := s.repo.SetAddLimited(
+		ctx,
+		"user_sessions",
+		userId,
+		sessionId,
+		timeoutAt,
+		limit.Max,
+		limit.Mode == sessionModels.LimitModeEvict,
+	)
+	if err != nil {
+		if errors.Is(err.SvcError(), errs.ErrConflict) {
+			return nil, errs.NewError(errs.ErrConflict, fmt.Errorf("session limit of %d reached", limit.Max))
+		}
+		return nil, err
+	}
+
+	for _, evictedId := range evicted {
+		err = s.revokeSession(ctx, userId, evictedId)
+		if err != nil {
+			return evicted, err
+		}
+	}
+	return evicted, nil
+}
+
+// payloadRole returns the role of a session payload, either built by the caller or read
+// back from JSON.
+func payloadRole(payload map[string]interface{}) authModels.RoleId {
+	switch role := payload["role"].(type) {
+	case authModels.RoleId:
+		return role
+	case int:
+		return authModels.RoleId(role)
+	case float64:
+		return authModels.RoleId(role)
+	}
+	return 0
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// A refresh token family is the chain of refresh tokens issued for one session. The family
+// shares the id of the session, and each token is stored by its hash:
+//
+//	refresh_token:<hash>          encrypted {user_id, session_id}, and rotations
+//	refresh_family:<sessionId>    set of the hashes of the family, scored by expiration
+//
+// Rotated tokens are kept until they expire, so a reuse can be detected. 'rotations' counts
+// the exchanges of the token, and is incremented atomically, so only one of concurrent
+// exchanges of a token gets 1, and the others are reuses.
+
+// CreateRefreshToken starts the refresh token family of a session and returns its first token.
+func (s service) CreateRefreshToken(ctx context.Context, userId, sessionId string) (string, errs.ChatError) {
+	tx, err := s.repo.BeginTransaction(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer s.repo.RollbackTransaction(ctx, tx)
+
+	refreshToken, err := s.addRefreshToken(ctx, tx, userId, sessionId)
+	if err != nil {
+		return "", err
+	}
+
+	return refreshToken, s.repo.CommitTransaction(ctx, tx)
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one of the same family and extends
+// the session, up to its absolute expiration. It returns the session id and the new refresh token.
+//
+// The presented token is invalidated. If an already rotated token is presented, even
+// concurrently, the whole family and its session are revoked, since either the legitimate
+// client or an attacker holds a stolen token.
+func (s service) RotateRefreshToken(ctx context.Context, refreshToken string) (string, string, errs.ChatError) {
+	tokenHash := hashRefreshToken(refreshToken)
+	stored, err := s.repo.HashGetEncrypted(ctx, "refresh_token", tokenHash, s.secret)
+	if err != nil {
+		return "", "", errs.NewError(errs.ErrNotAuthenticated, errors.New("invalid refresh token"))
+	}
+	userId, okUser := stored["user_id"].(string)
+	sessionId, okSession := stored["session_id"].(string)
+	if !okUser || !okSession {
+		return "", "", errs.NewError(errs.ErrInternal, fmt.Errorf("corrupted refresh token"))
+	}
+
+	rotations, err := s.repo.HashIncrement(ctx, "refresh_token", tokenHash, "rotations")
+	if err != nil && errors.Is(err.SvcError(), errs.ErrNotFound) {
+		return "", "", errs.NewError(errs.ErrNotAuthenticated, errors.New("invalid refresh token"))
+	}
+	if err != nil {
+		return "", "", err
+	}
+	// Tokens rotated before 'rotations' existed are flagged as rotated in the encrypted values.
+	if rotated, _ := stored["rotated"].(bool); rotated || rotations > 1 {
+		logger.Info(
+			"refresh token reuse detected, revoking family",
+			zap.String("user_id", userId),
+			zap.String("session", sessionModels.SessionHandle(sessionId)),
+		)
+		if errRevoke := s.revokeSession(ctx, userId, sessionId); errRevoke != nil {
+			return "", "", errRevoke
+		}
+		return "", "", errs.NewError(errs.ErrNotAuthorized, errors.New("refresh token reuse detected"))
+	}
+
+	session, errSession := s.GetSession(ctx, sessionId)
+	if errSession != nil {
+		if errRevoke := s.revokeSession(ctx, userId, sessionId); errRevoke != nil {
+			return "", "", errRevoke
+		}
+		return "", "", errs.NewError(errs.ErrNotAuthenticated, errors.New("session expired"))
+	}
+
+	tx, err := s.repo.BeginTransaction(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	defer s.repo.RollbackTransaction(ctx, tx)
+
+	newRefreshToken, err := s.addRefreshToken(ctx, tx, userId, sessionId)
+	if err != nil {
+		return "", "", err
+	}
+	err = s.extendSession(ctx, tx, userId, sessionId, s.nextExpiration(session, time.Now()))
+	if err != nil {
+		return "", "", err
+	}
+
+	return sessionId, newRefreshToken, s.repo.CommitTransaction(ctx, tx)
+}
+
+func (s service) addRefreshToken(
+	ctx context.Context,
+	tx interface{},
+	userId, sessionId string,
+) (string, errs.ChatError) {
+	refreshToken, errGenerate := generateRefreshToken()
+	if errGenerate != nil {
+		return "", errs.NewError(errs.ErrInternal, errGenerate)
+	}
+	tokenHash := hashRefreshToken(refreshToken)
+	expiresAt := time.Now().Add(s.refreshTimeout)
+
+	err := s.repo.HashSetEncrypted(ctx, tx, "refresh_token", tokenHash, s.secret, map[string]interface{}{
+		"user_id":    userId,
+		"session_id": sessionId,
+	})
+	if err != nil {
+		return "", err
+	}
+	err = s.repo.ExpireAt(ctx, tx, "refresh_token", tokenHash, expiresAt)
+	if err != nil {
+		return "", err
+	}
+	err = s.repo.SetAdd(ctx, tx, "refresh_family", sessionId, tokenHash, expiresAt)
+	if err != nil {
+		return "", err
+	}
+	return refreshToken, nil
+}
+
+// revokeRefreshFamily adds to the transaction the deletion of every token of the family.
+func (s service) revokeRefreshFamily(ctx context.Context, tx interface{}, sessionId string) errs.ChatError {
+	familyTokens, err := s.repo.SetMembers(ctx, "refresh_family", sessionId, time.Time{})
+	if err != nil {
+		return err
+	}
+	for tokenHash := range familyTokens {
+		err = s.repo.Delete(ctx, tx, "refresh_token", tokenHash)
+		if err != nil {
+			return err
+		}
+	}
+	return s.repo.Delete(ctx, tx, "refresh_family", sessionId)
+}
+
+// revokeSession deletes the session, its index entries and its refresh token family.
+func (s service) revokeSession(ctx context.Context, userId, sessionId string) errs.ChatError {
+	tx, err := s.repo.BeginTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.repo.RollbackTransaction(ctx, tx)
+
+	err = s.repo.Delete(ctx, tx, "session", sessionId)
+	if err != nil {
+		return err
+	}
+	err = s.repo.Delete(ctx, tx, fmt.Sprintf("user_session:%s", userId), sessionId)
+	if err != nil {
+		return err
+	}
+	err = s.repo.SetRemove(ctx, tx, "user_sessions", userId, sessionId)
+	if err != nil {
+		return err
+	}
+	err = s.revokeRefreshFamily(ctx, tx, sessionId)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.CommitTransaction(ctx, tx)
+}
+
+func generateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashRefreshToken(refreshToken string) string {
+	hash := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(hash[:])
+}
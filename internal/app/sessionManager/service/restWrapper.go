@@ -2,12 +2,24 @@ package service
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 
 	auth "github.com/raffops/chat_auth/internal/app/auth/model"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
 )
 
 func (s service) CheckRestSession(next http.HandlerFunc, roles []auth.RoleId) http.HandlerFunc {
+	return s.checkRestSession(next, roles, false)
+}
+
+// CheckRestSessionWithMfa works as CheckRestSession, but also requires a session created
+// with a second factor.
+func (s service) CheckRestSessionWithMfa(next http.HandlerFunc, roles []auth.RoleId) http.HandlerFunc {
+	return s.checkRestSession(next, roles, true)
+}
+
+func (s service) checkRestSession(next http.HandlerFunc, roles []auth.RoleId, requireMfa bool) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		token := r.Header.Get("Authorization")
 		token, ok := strings.CutPrefix(token, "Bearer ")
@@ -16,11 +28,23 @@ func (s service) CheckRestSession(next http.HandlerFunc, roles []auth.RoleId) ht
 			return
 		}
 		result, err := s.repo.HashGetEncrypted(r.Context(), "session", token, s.secret)
-		for _, role := range roles {
-			if err == nil && float64(role) == result["role"].(float64) {
-				next(w, r)
-				return
-			}
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		principal, err := newPrincipal(token, result)
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if requireMfa && !principal.Mfa {
+			http.Error(w, "Multi-factor authentication required", http.StatusForbidden)
+			return
+		}
+		if slices.Contains(roles, principal.Role) {
+			s.touchSession(r.Context(), principal, result)
+			next(w, r.WithContext(sessionModels.ContextWithPrincipal(r.Context(), principal)))
+			return
 		}
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 	})
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Each session is indexed by user, with the metadata listed to the user:
+//
+//	user_sessions:<userId>               set of the session ids, scored by expiration
+//	user_session:<userId>:<sessionId>    encrypted {created_at, ip, user_agent, provider}
+//
+// The index entries expire with their sessions. Expired ids are removed from the set when
+// the user creates a session, and the set expires with the last session.
+
+// newSessionMetadata returns the metadata indexed for a new session. The client is the one
+// injected in the context by the auth controller, and the provider is read from the payload.
+func newSessionMetadata(ctx context.Context, payload map[string]interface{}) map[string]interface{} {
+	client := authModels.ClientFromContext(ctx)
+	provider, _ := payload["provider"].(string)
+	return map[string]interface{}{
+		"created_at": time.Now().UTC().Format(time.RFC3339),
+		"ip":         client.Ip,
+		"user_agent": client.UserAgent,
+		"provider":   provider,
+	}
+}
+
+// ListUserSessions lists the active sessions of a user, newest first. The sessions are listed
+// by handle, never by id.
+func (s service) ListUserSessions(ctx context.Context, userId string) ([]sessionModels.Session, errs.ChatError) {
+	userSessions, err := s.repo.SetMembers(ctx, "user_sessions", userId, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]sessionModels.Session, 0, len(userSessions))
+	for sessionId, expiresAt := range userSessions {
+		session := sessionModels.Session{
+			Id:        sessionModels.SessionHandle(sessionId),
+			UserId:    userId,
+			ExpiresAt: expiresAt.UTC(),
+		}
+		s.readSessionMetadata(ctx, &session, sessionId)
+		sessions = append(sessions, session)
+	}
+
+	slices.SortFunc(sessions, func(a, b sessionModels.Session) int {
+		switch {
+		case a.CreatedAt == nil && b.CreatedAt == nil:
+			return strings.Compare(a.Id, b.Id)
+		case a.CreatedAt == nil:
+			return 1
+		case b.CreatedAt == nil:
+			return -1
+		}
+		return b.CreatedAt.Compare(*a.CreatedAt)
+	})
+	return sessions, nil
+}
+
+// readSessionMetadata fills the session with its indexed metadata. Sessions indexed before
+// the metadata was stored are listed without it.
+func (s service) readSessionMetadata(ctx context.Context, session *sessionModels.Session, sessionId string) {
+	indexTable := fmt.Sprintf("user_session:%s", session.UserId)
+	metadata, err := s.repo.HashGetEncrypted(ctx, indexTable, sessionId, s.secret)
+	if err != nil {
+		logger.Debug("session without metadata", zap.String("session", session.Id), zap.Error(err))
+		return
+	}
+	if createdAt, errParse := time.Parse(time.RFC3339, fmt.Sprint(metadata["created_at"])); errParse == nil {
+		session.CreatedAt = &createdAt
+	}
+	session.Ip, _ = metadata["ip"].(string)
+	session.UserAgent, _ = metadata["user_agent"].(string)
+	session.Provider, _ = metadata["provider"].(string)
+}
+
+// FinishUserSession revokes a single session of a user, with its refresh tokens. The session
+// is given by the handle it is listed with. If the handle is not the one of an active session
+// of the user, the svcError is 'errs.ErrNotFound'.
+func (s service) FinishUserSession(ctx context.Context, userId, handle string) errs.ChatError {
+	sessionId, err := s.resolveSessionHandle(ctx, userId, handle)
+	if err != nil {
+		return err
+	}
+	session, err := s.GetSession(ctx, sessionId)
+	if err != nil || session["user_id"] != userId {
+		return errs.NewError(errs.ErrNotFound, errors.New("session not found"))
+	}
+	return s.revokeSession(ctx, userId, sessionId)
+}
+
+// resolveSessionHandle returns the id of the active session of the user listed with the handle.
+func (s service) resolveSessionHandle(ctx context.Context, userId, handle string) (string, errs.ChatError) {
+	userSessions, err := s.repo.SetMembers(ctx, "user_sessions", userId, time.Now())
+	if err != nil {
+		return "", err
+	}
+	for sessionId := range userSessions {
+		if sessionModels.SessionHandle(sessionId) == handle {
+			return sessionId, nil
+		}
+	}
+	return "", errs.NewError(errs.ErrNotFound, errors.New("session not found"))
+}
@@ -0,0 +1,34 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/raffops/chat_auth/internal/app/token"
+	tokenModels "github.com/raffops/chat_auth/internal/app/token/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+type controller struct {
+	tokenService token.Service
+}
+
+// JWKS publishes the public keys used to verify the access tokens.
+func (c *controller) JWKS(w http.ResponseWriter, r *http.Request) {
+	responseString, err := json.Marshal(c.tokenService.JWKS(r.Context()))
+	if err != nil {
+		http.Error(w,
+			errs.NewError(errs.ErrInternal, err).Error(),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(tokenModels.JWKSMaxAge.Seconds())))
+	_, _ = w.Write(responseString)
+}
+
+func NewController(tokenService token.Service) token.Controller {
+	return &controller{tokenService: tokenService}
+}
@@ -0,0 +1,28 @@
+package token
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	tokenModels "github.com/raffops/chat_auth/internal/app/token/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+type Controller interface {
+	JWKS(w http.ResponseWriter, r *http.Request)
+}
+
+type Service interface {
+	Issue(ctx context.Context, claims tokenModels.Claims) (string, time.Time, errs.ChatError)
+	Verify(ctx context.Context, token string) (tokenModels.Claims, errs.ChatError)
+	JWKS(ctx context.Context) tokenModels.JWKS
+}
+
+type KeyRing interface {
+	SigningKey() (tokenModels.Key, errs.ChatError)
+	GetKey(kid string) (tokenModels.Key, errs.ChatError)
+	Keys() []tokenModels.Key
+	Reload(ctx context.Context) errs.ChatError
+	StartReloading(ctx context.Context, interval time.Duration)
+}
@@ -0,0 +1,32 @@
+package token
+
+import (
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+)
+
+// Claims is the payload of an access token. The session id points back to the
+// revocable session stored by the session manager. Mfa is set when the session was
+// created with a second factor.
+type Claims struct {
+	UserId    string                `json:"user_id"`
+	Role      authModels.RoleId     `json:"role"`
+	Status    userModels.StatusId   `json:"status"`
+	AuthType  userModels.AuthTypeId `json:"auth_type"`
+	SessionId string                `json:"sid"`
+	Mfa       bool                  `json:"mfa"`
+	IssuedAt  int64                 `json:"iat"`
+	ExpiresAt int64                 `json:"exp"`
+}
+
+func (c Claims) Expired(now time.Time) bool {
+	return now.Unix() >= c.ExpiresAt
+}
+
+type Header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyId     string `json:"kid"`
+}
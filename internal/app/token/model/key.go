@@ -0,0 +1,54 @@
+package token
+
+import (
+	"crypto"
+	"time"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmES256 = "ES256"
+)
+
+// JWKSMaxAge is how long the verifiers may cache the JWKS. A new key is published at least
+// this long before it signs, so the verifiers know it when they receive its first tokens.
+const JWKSMaxAge = 5 * time.Minute
+
+// Key is a signing key of the key ring. A key with a non-zero RetireAt was removed
+// from disk and is only kept to verify tokens issued before it was removed.
+// A key is published as soon as it is loaded, but only signs from SignFrom on.
+type Key struct {
+	Id         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+	SignFrom   time.Time
+	RetireAt   time.Time
+}
+
+func (k Key) Retiring() bool {
+	return !k.RetireAt.IsZero()
+}
+
+// CanSign reports whether the key signs new tokens at the time.
+func (k Key) CanSign(now time.Time) bool {
+	return !k.Retiring() && !now.Before(k.SignFrom)
+}
+
+// JWK is the public part of a Key, as described in RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	KeyId     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
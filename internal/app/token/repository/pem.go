@@ -0,0 +1,234 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raffops/chat_auth/internal/app/token"
+	tokenModels "github.com/raffops/chat_auth/internal/app/token/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// pemKeyRing loads the signing keys from PEM files stored in a directory.
+//
+// Each file '<kid>.pem' holds a RSA or Ed25519 private key and its name is used as key id.
+// The key with the greatest id, in lexical order, signs the new tokens, so the files
+// should be named after their creation date (e.g. '2024-09-01.pem').
+// A new key is published right away, but only signs once the publish delay elapsed since
+// the modification time of its file, so the verifiers caching the JWKS know it by then.
+// When a file is removed, its key keeps verifying tokens until the retention elapses,
+// which should be at least the access token timeout.
+type pemKeyRing struct {
+	dir          string
+	retention    time.Duration
+	publishDelay time.Duration
+	mu           sync.RWMutex
+	keys         map[string]tokenModels.Key
+}
+
+// SigningKey returns the key with the greatest id among the ones that can sign. When none
+// can yet, which only happens when every key on disk is new, the greatest one signs anyway.
+func (k *pemKeyRing) SigningKey() (tokenModels.Key, errs.ChatError) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	now := time.Now()
+	signing, fallback := tokenModels.Key{}, tokenModels.Key{}
+	for _, key := range k.keys {
+		if key.Retiring() {
+			continue
+		}
+		if key.Id > fallback.Id {
+			fallback = key
+		}
+		if key.CanSign(now) && key.Id > signing.Id {
+			signing = key
+		}
+	}
+	if signing.Id == "" {
+		signing = fallback
+	}
+	if signing.Id == "" {
+		return tokenModels.Key{}, errs.NewError(errs.ErrInternal, errors.New("no signing key available"))
+	}
+	return signing, nil
+}
+
+func (k *pemKeyRing) GetKey(kid string) (tokenModels.Key, errs.ChatError) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[kid]
+	if !ok {
+		return tokenModels.Key{}, errs.NewError(errs.ErrNotFound, fmt.Errorf("key %s not found", kid))
+	}
+	return key, nil
+}
+
+// Keys returns every key that can verify tokens, sorted by id.
+func (k *pemKeyRing) Keys() []tokenModels.Key {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	keys := make([]tokenModels.Key, 0, len(k.keys))
+	for _, key := range k.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Id < keys[j].Id })
+	return keys
+}
+
+// Reload reads the directory again. New files are added to the ring, removed files
+// start their retention and the keys whose retention elapsed are dropped.
+func (k *pemKeyRing) Reload(ctx context.Context) errs.ChatError {
+	onDisk, err := readKeys(k.dir, k.publishDelay)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+	for kid, key := range k.keys {
+		if _, ok := onDisk[kid]; ok {
+			continue
+		}
+		if !key.Retiring() {
+			key.RetireAt = now.Add(k.retention)
+			k.keys[kid] = key
+			logger.Info("signing key retiring", zap.String("kid", kid), zap.Time("retire_at", key.RetireAt))
+		}
+		if !now.Before(key.RetireAt) {
+			delete(k.keys, kid)
+			logger.Info("signing key removed", zap.String("kid", kid))
+		}
+	}
+
+	for kid, key := range onDisk {
+		if _, ok := k.keys[kid]; !ok {
+			logger.Info("signing key added", zap.String("kid", kid), zap.Time("sign_from", key.SignFrom))
+		}
+		k.keys[kid] = key
+	}
+	if len(onDisk) == 0 {
+		return errs.NewError(errs.ErrInternal, fmt.Errorf("no signing key found in %s", k.dir))
+	}
+	return nil
+}
+
+// StartReloading reloads the key ring at every interval until the context is done.
+func (k *pemKeyRing) StartReloading(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := k.Reload(ctx); err != nil {
+					logger.Error("cannot reload signing keys", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// readKeys parses the PEM files of the directory. The keys sign from the modification time of
+// their file plus the publish delay, so restarting a replica does not delay them again.
+func readKeys(dir string, publishDelay time.Duration) (map[string]tokenModels.Key, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]tokenModels.Key, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		key, err := parseKey(kid, content)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %s: %w", file, err)
+		}
+		key.SignFrom = info.ModTime().Add(publishDelay)
+		keys[kid] = key
+	}
+	return keys, nil
+}
+
+func parseKey(kid string, content []byte) (tokenModels.Key, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return tokenModels.Key{}, errors.New("no PEM block found")
+	}
+
+	var privateKey crypto.PrivateKey
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return tokenModels.Key{}, fmt.Errorf("unsupported PEM block %s", block.Type)
+	}
+	if err != nil {
+		return tokenModels.Key{}, err
+	}
+
+	switch privateKey := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return tokenModels.Key{
+			Id:         kid,
+			Algorithm:  tokenModels.AlgorithmRS256,
+			PrivateKey: privateKey,
+			PublicKey:  privateKey.Public(),
+		}, nil
+	case ed25519.PrivateKey:
+		return tokenModels.Key{
+			Id:         kid,
+			Algorithm:  tokenModels.AlgorithmEdDSA,
+			PrivateKey: privateKey,
+			PublicKey:  privateKey.Public(),
+		}, nil
+	default:
+		return tokenModels.Key{}, fmt.Errorf("unsupported key type %T", privateKey)
+	}
+}
+
+func NewPemKeyRing(
+	ctx context.Context,
+	dir string,
+	retention time.Duration,
+	publishDelay time.Duration,
+) (token.KeyRing, errs.ChatError) {
+	keyRing := &pemKeyRing{
+		dir:          dir,
+		retention:    retention,
+		publishDelay: publishDelay,
+		keys:         map[string]tokenModels.Key{},
+	}
+	err := keyRing.Reload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return keyRing, nil
+}
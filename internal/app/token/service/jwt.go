@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/raffops/chat_auth/internal/app/token"
+	tokenModels "github.com/raffops/chat_auth/internal/app/token/model"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+var encoding = base64.RawURLEncoding
+
+type jwtService struct {
+	keyRing token.KeyRing
+	timeout time.Duration
+}
+
+// Issue signs the claims with the current signing key and returns the compact JWT
+// with its expiration time. IssuedAt and ExpiresAt are always overwritten.
+func (s jwtService) Issue(
+	ctx context.Context,
+	claims tokenModels.Claims,
+) (string, time.Time, errs.ChatError) {
+	key, errKey := s.keyRing.SigningKey()
+	if errKey != nil {
+		return "", time.Time{}, errKey
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.timeout)
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = expiresAt.Unix()
+
+	header, err := json.Marshal(tokenModels.Header{Algorithm: key.Algorithm, Type: "JWT", KeyId: key.Id})
+	if err != nil {
+		return "", time.Time{}, errs.NewError(errs.ErrInternal, err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, errs.NewError(errs.ErrInternal, err)
+	}
+
+	signingInput := encoding.EncodeToString(header) + "." + encoding.EncodeToString(payload)
+	signature, err := sign(key, []byte(signingInput))
+	if err != nil {
+		return "", time.Time{}, errs.NewError(errs.ErrInternal, err)
+	}
+	return signingInput + "." + encoding.EncodeToString(signature), expiresAt, nil
+}
+
+// Verify checks the signature, using the key referenced by the 'kid' header, and the
+// expiration of the token and returns its claims.
+// Any failure is reported as 'errs.ErrNotAuthenticated'.
+func (s jwtService) Verify(ctx context.Context, tokenString string) (tokenModels.Claims, errs.ChatError) {
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 3 {
+		return tokenModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated, errors.New("malformed token"))
+	}
+
+	var header tokenModels.Header
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return tokenModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated, err)
+	}
+	key, errKey := s.keyRing.GetKey(header.KeyId)
+	if errKey != nil {
+		return tokenModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated, errKey)
+	}
+	if header.Algorithm != key.Algorithm {
+		return tokenModels.Claims{}, errs.NewError(
+			errs.ErrNotAuthenticated,
+			fmt.Errorf("unexpected signing algorithm %s", header.Algorithm),
+		)
+	}
+
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return tokenModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated, errors.New("malformed signature"))
+	}
+	if !verify(key, []byte(parts[0]+"."+parts[1]), signature) {
+		return tokenModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated, errors.New("invalid signature"))
+	}
+
+	var claims tokenModels.Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return tokenModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated, err)
+	}
+	if claims.Expired(time.Now()) {
+		return tokenModels.Claims{}, errs.NewError(errs.ErrNotAuthenticated, errors.New("token expired"))
+	}
+	return claims, nil
+}
+
+// JWKS returns the public keys that verify the tokens, including the retiring ones.
+func (s jwtService) JWKS(ctx context.Context) tokenModels.JWKS {
+	keys := s.keyRing.Keys()
+	jwks := tokenModels.JWKS{Keys: make([]tokenModels.JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwk := tokenModels.JWK{Use: "sig", KeyId: key.Id, Algorithm: key.Algorithm}
+		switch publicKey := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = encoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = encoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = encoding.EncodeToString(publicKey)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func sign(key tokenModels.Key, signingInput []byte) ([]byte, error) {
+	switch key.Algorithm {
+	case tokenModels.AlgorithmRS256:
+		digest := sha256.Sum256(signingInput)
+		return key.PrivateKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case tokenModels.AlgorithmEdDSA:
+		return key.PrivateKey.Sign(rand.Reader, signingInput, crypto.Hash(0))
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %s", key.Algorithm)
+	}
+}
+
+func verify(key tokenModels.Key, signingInput, signature []byte) bool {
+	switch publicKey := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(signingInput)
+		return rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(publicKey, signingInput, signature)
+	default:
+		return false
+	}
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	decoded, err := encoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("malformed token segment")
+	}
+	if err := json.Unmarshal(decoded, v); err != nil {
+		return errors.New("malformed token segment")
+	}
+	return nil
+}
+
+func sanityCheck() {
+	envVariables := []string{
+		"ACCESS_TOKEN_TIMEOUT",
+		"TOKEN_KEYS_DIR",
+	}
+	for _, envVariable := range envVariables {
+		if _, ok := os.LookupEnv(envVariable); !ok {
+			logger.Fatal("Environment variable not set", zap.String("variable", envVariable))
+		}
+	}
+}
+
+func NewJwtService(keyRing token.KeyRing, timeout time.Duration) token.Service {
+	sanityCheck()
+	return &jwtService{
+		keyRing: keyRing,
+		timeout: timeout,
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	auth "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/token"
+	tokenModels "github.com/raffops/chat_auth/internal/app/token/model"
+	tokenRepository "github.com/raffops/chat_auth/internal/app/token/repository"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+)
+
+var johnClaims = tokenModels.Claims{
+	UserId:    "ac554921-1b75-43bd-9e1d-e17dfb38f6c3",
+	Role:      auth.RoleUser,
+	Status:    userModels.StatusActive,
+	AuthType:  userModels.AuthTypeGoogle,
+	SessionId: "session",
+}
+
+func writeKey(t *testing.T, dir, kid string, privateKey any) {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+	}
+	content := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	err = os.WriteFile(filepath.Join(dir, kid+".pem"), content, 0o600)
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func newKeyRing(t *testing.T, dir string, retention time.Duration) token.KeyRing {
+	keyRing, err := tokenRepository.NewPemKeyRing(context.Background(), dir, retention, 0)
+	if err != nil {
+		t.Fatalf("NewPemKeyRing() error = %v", err)
+	}
+	return keyRing
+}
+
+func TestJwtService_IssueAndVerify(t *testing.T) {
+	os.Setenv("ACCESS_TOKEN_TIMEOUT", "1m")
+	os.Setenv("TOKEN_KEYS_DIR", t.TempDir())
+	ctx := context.Background()
+
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	edDir, rsaDir, otherDir := t.TempDir(), t.TempDir(), t.TempDir()
+	writeKey(t, edDir, "2024-09-01", edKey)
+	writeKey(t, rsaDir, "2024-09-01", rsaKey)
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, otherDir, "2024-09-01", otherKey)
+
+	edSrv := NewJwtService(newKeyRing(t, edDir, time.Minute), time.Minute)
+	rsaSrv := NewJwtService(newKeyRing(t, rsaDir, time.Minute), time.Minute)
+
+	edToken, _, err := edSrv.Issue(ctx, johnClaims)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	rsaToken, _, err := rsaSrv.Issue(ctx, johnClaims)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	expiredToken, _, _ := NewJwtService(newKeyRing(t, edDir, time.Minute), -time.Minute).Issue(ctx, johnClaims)
+	otherKeyToken, _, _ := NewJwtService(newKeyRing(t, otherDir, time.Minute), time.Minute).Issue(ctx, johnClaims)
+	parts := strings.Split(edToken, ".")
+
+	tests := []struct {
+		name    string
+		srv     token.Service
+		token   string
+		wantErr bool
+	}{
+		{name: "Test valid EdDSA token", srv: edSrv, token: edToken, wantErr: false},
+		{name: "Test valid RS256 token", srv: rsaSrv, token: rsaToken, wantErr: false},
+		{name: "Test RS256 token with EdDSA key", srv: edSrv, token: rsaToken, wantErr: true},
+		{name: "Test expired token", srv: edSrv, token: expiredToken, wantErr: true},
+		{name: "Test token signed with another key", srv: edSrv, token: otherKeyToken, wantErr: true},
+		{name: "Test tampered payload", srv: edSrv, token: parts[0] + "." + parts[0] + "." + parts[2], wantErr: true},
+		{name: "Test malformed token", srv: edSrv, token: "invalid", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.srv.Verify(ctx, tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() \nerror = %v\nwantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.UserId != johnClaims.UserId || got.Role != johnClaims.Role || got.SessionId != johnClaims.SessionId {
+				t.Errorf("Verify() \ngot = %v\nwant %v", got, johnClaims)
+			}
+		})
+	}
+}
+
+func TestJwtService_KeyRotation(t *testing.T) {
+	os.Setenv("ACCESS_TOKEN_TIMEOUT", "1m")
+	os.Setenv("TOKEN_KEYS_DIR", t.TempDir())
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, dir, "2024-09-01", oldKey)
+	keyRing := newKeyRing(t, dir, 0)
+	srv := NewJwtService(keyRing, time.Minute)
+	oldToken, _, _ := srv.Issue(ctx, johnClaims)
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	writeKey(t, dir, "2024-10-01", rsaKey)
+	if err := keyRing.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	newToken, _, _ := srv.Issue(ctx, johnClaims)
+	if !strings.Contains(decodeHeader(t, newToken), `"kid":"2024-10-01"`) {
+		t.Fatalf("Issue() got header %s, want kid 2024-10-01", decodeHeader(t, newToken))
+	}
+	if _, err := srv.Verify(ctx, oldToken); err != nil {
+		t.Fatalf("Verify() old token after rotation error = %v", err)
+	}
+	if got := len(srv.JWKS(ctx).Keys); got != 2 {
+		t.Fatalf("JWKS() got %d keys, want 2", got)
+	}
+
+	_ = os.Remove(filepath.Join(dir, "2024-09-01.pem"))
+	if err := keyRing.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if _, err := srv.Verify(ctx, oldToken); err == nil {
+		t.Fatalf("Verify() old token after retirement got nil error")
+	}
+	if _, err := srv.Verify(ctx, newToken); err != nil {
+		t.Fatalf("Verify() new token error = %v", err)
+	}
+	if got := len(srv.JWKS(ctx).Keys); got != 1 {
+		t.Fatalf("JWKS() got %d keys, want 1", got)
+	}
+}
+
+func TestJwtService_KeyPublishedBeforeSigning(t *testing.T) {
+	os.Setenv("ACCESS_TOKEN_TIMEOUT", "1m")
+	os.Setenv("TOKEN_KEYS_DIR", t.TempDir())
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, dir, "2024-09-01", oldKey)
+	past := time.Now().Add(-time.Hour)
+	_ = os.Chtimes(filepath.Join(dir, "2024-09-01.pem"), past, past)
+	keyRing, errKeyRing := tokenRepository.NewPemKeyRing(ctx, dir, time.Minute, tokenModels.JWKSMaxAge)
+	if errKeyRing != nil {
+		t.Fatalf("NewPemKeyRing() error = %v", errKeyRing)
+	}
+	srv := NewJwtService(keyRing, time.Minute)
+
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, dir, "2024-10-01", newKey)
+	if err := keyRing.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := len(srv.JWKS(ctx).Keys); got != 2 {
+		t.Fatalf("JWKS() got %d keys, want 2", got)
+	}
+	newToken, _, _ := srv.Issue(ctx, johnClaims)
+	if !strings.Contains(decodeHeader(t, newToken), `"kid":"2024-09-01"`) {
+		t.Fatalf("Issue() got header %s, want kid 2024-09-01", decodeHeader(t, newToken))
+	}
+
+	_ = os.Chtimes(filepath.Join(dir, "2024-10-01.pem"), past, past)
+	if err := keyRing.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	newToken, _, _ = srv.Issue(ctx, johnClaims)
+	if !strings.Contains(decodeHeader(t, newToken), `"kid":"2024-10-01"`) {
+		t.Fatalf("Issue() got header %s, want kid 2024-10-01", decodeHeader(t, newToken))
+	}
+}
+
+func TestJwtService_NewKeySignsWhenAlone(t *testing.T) {
+	os.Setenv("ACCESS_TOKEN_TIMEOUT", "1m")
+	os.Setenv("TOKEN_KEYS_DIR", t.TempDir())
+	dir := t.TempDir()
+
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, dir, "2024-09-01", key)
+	keyRing, errKeyRing := tokenRepository.NewPemKeyRing(context.Background(), dir, time.Minute, tokenModels.JWKSMaxAge)
+	if errKeyRing != nil {
+		t.Fatalf("NewPemKeyRing() error = %v", errKeyRing)
+	}
+	if _, _, err := NewJwtService(keyRing, time.Minute).Issue(context.Background(), johnClaims); err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+}
+
+func decodeHeader(t *testing.T, tokenString string) string {
+	header, err := encoding.DecodeString(strings.Split(tokenString, ".")[0])
+	if err != nil {
+		t.Fatalf("DecodeString() error = %v", err)
+	}
+	return string(header)
+}
@@ -0,0 +1,287 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	"github.com/raffops/chat_auth/internal/app/user"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/raffops/chat_commons/pkg/errs"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+var listColumns = []string{"id", "username", "email", "auth_type", "role", "status", "created_at", "updated_at"}
+
+type controller struct {
+	userRepo       user.ReaderWriterRepository
+	loginRepo      user.LoginRepository
+	sessionService sessionManager.Service
+}
+
+// ListUsers lists the users page by page. The query string accepts the filters 'role',
+// 'status' and 'auth_type' by name, 'sort' as 'column:order', like 'created_at:asc',
+// 'limit' and 'offset', and 'include_deleted' to also list the deleted users.
+func (c *controller) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filters, err := parseFilters(query)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	sorts, err := parseSorts(query)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	page, err := parsePagination(query)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	includeDeleted, err := parseIncludeDeleted(query)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+
+	users, err := c.userRepo.ListUsers(r.Context(), listColumns, filters, sorts, page, includeDeleted)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	total, err := c.userRepo.CountUsers(r.Context(), filters, includeDeleted)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+
+	response := userModels.UserPage{Users: users, Total: total, Next: nextPage(r, page, len(users), total)}
+	responseString, _ := json.Marshal(response)
+	_, _ = w.Write(responseString)
+}
+
+// GetUser fetches a user by id. Deleted users are only found with 'include_deleted'.
+func (c *controller) GetUser(w http.ResponseWriter, r *http.Request) {
+	includeDeleted, err := parseIncludeDeleted(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	u, err := c.userRepo.GetUser(r.Context(), "id", mux.Vars(r)["id"], includeDeleted)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	responseString, _ := json.Marshal(u)
+	_, _ = w.Write(responseString)
+}
+
+// UpdateUser changes the role or status of a user. The sessions of the user are finished,
+// since they hold the previous role and status.
+func (c *controller) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	var update userModels.UserUpdate
+	errDecode := json.NewDecoder(r.Body).Decode(&update)
+	if errDecode != nil {
+		http.Error(w, errs.NewError(errs.ErrBadRequest, errDecode).Error(), http.StatusBadRequest)
+		return
+	}
+	if update.Role == "" && update.Status == "" {
+		err := errs.NewError(errs.ErrBadRequest, errors.New("role or status is required"))
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+
+	u, err := c.userRepo.GetUser(r.Context(), "id", mux.Vars(r)["id"], false)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	err = applyUpdate(&u, update)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+
+	tx, errTx := c.userRepo.GetDB().BeginTx(r.Context(), nil)
+	if errTx != nil {
+		err = errs.NewError(errs.ErrInternal, errTx)
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	defer tx.Rollback()
+
+	u, err = c.userRepo.UpdateUser(r.Context(), tx, u)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	errCommit := tx.Commit()
+	if errCommit != nil {
+		err = errs.NewError(errs.ErrInternal, errCommit)
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+
+	err = c.sessionService.FinishUserSessions(r.Context(), u.Id)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	responseString, _ := json.Marshal(u)
+	_, _ = w.Write(responseString)
+}
+
+// ListLogins lists the login history of a user page by page, newest first. The query
+// string accepts 'limit' and 'offset'. Deleted users keep their history until purged.
+func (c *controller) ListLogins(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePagination(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	u, err := c.userRepo.GetUser(r.Context(), "id", mux.Vars(r)["id"], true)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+
+	logins, err := c.loginRepo.ListLogins(r.Context(), u.Id, page)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+	total, err := c.loginRepo.CountLogins(r.Context(), u.Id)
+	if err != nil {
+		http.Error(w, err.Error(), errs.GetHttpStatusCode(err))
+		return
+	}
+
+	response := userModels.LoginPage{Logins: logins, Total: total, Next: nextPage(r, page, len(logins), total)}
+	responseString, _ := json.Marshal(response)
+	_, _ = w.Write(responseString)
+}
+
+// nextPage returns the link to the page following the current one, or an empty string
+// on the last page.
+func nextPage(r *http.Request, page userModels.Pagination, count, total int) string {
+	if page.Offset+count >= total {
+		return ""
+	}
+	query := r.URL.Query()
+	query.Set("offset", strconv.Itoa(page.Offset+page.Limit))
+	query.Set("limit", strconv.Itoa(page.Limit))
+	return r.URL.Path + "?" + query.Encode()
+}
+
+func applyUpdate(u *userModels.User, update userModels.UserUpdate) errs.ChatError {
+	if update.Role != "" {
+		role, ok := authModels.MapRoleString[update.Role]
+		if !ok {
+			return errs.NewError(errs.ErrBadRequest, fmt.Errorf("role %s not found", update.Role))
+		}
+		u.Role = role
+	}
+	if update.Status != "" {
+		status, ok := userModels.MapStatusString[update.Status]
+		if !ok {
+			return errs.NewError(errs.ErrBadRequest, fmt.Errorf("status %s not found", update.Status))
+		}
+		u.Status = status
+	}
+	return nil
+}
+
+func parseFilters(query url.Values) ([]userModels.Filter, errs.ChatError) {
+	filters := make([]userModels.Filter, 0)
+	for _, key := range userModels.ValidColumnsToFilter {
+		name := query.Get(key)
+		if name == "" {
+			continue
+		}
+		var value any
+		var ok bool
+		switch key {
+		case "role":
+			value, ok = authModels.MapRoleString[name]
+		case "status":
+			value, ok = userModels.MapStatusString[name]
+		case "auth_type":
+			value, ok = userModels.MapAuthTypeString[name]
+		}
+		if !ok {
+			return nil, errs.NewError(errs.ErrBadRequest, fmt.Errorf("%s %s not found", key, name))
+		}
+		filters = append(filters, userModels.Filter{Key: key, Value: value, Comparison: userModels.ComparisonEqual})
+	}
+	return filters, nil
+}
+
+func parseSorts(query url.Values) ([]userModels.Sort, errs.ChatError) {
+	sorts := make([]userModels.Sort, 0)
+	for _, sort := range query["sort"] {
+		key, order, _ := strings.Cut(sort, ":")
+		if !slices.Contains(userModels.ValidColumnsToSort, key) {
+			return nil, errs.NewError(errs.ErrBadRequest, fmt.Errorf("invalid sort key %s", key))
+		}
+		switch strings.ToUpper(order) {
+		case "", string(userModels.OrderDesc):
+			sorts = append(sorts, userModels.Sort{Key: key, Order: userModels.OrderDesc})
+		case string(userModels.OrderAsc):
+			sorts = append(sorts, userModels.Sort{Key: key, Order: userModels.OrderAsc})
+		default:
+			return nil, errs.NewError(errs.ErrBadRequest, fmt.Errorf("invalid sort order %s", order))
+		}
+	}
+	return sorts, nil
+}
+
+func parsePagination(query url.Values) (userModels.Pagination, errs.ChatError) {
+	page := userModels.Pagination{Limit: defaultLimit}
+	var err error
+	if limit := query.Get("limit"); limit != "" {
+		page.Limit, err = strconv.Atoi(limit)
+		if err != nil || page.Limit < 1 || page.Limit > maxLimit {
+			return page, errs.NewError(errs.ErrBadRequest, fmt.Errorf("limit must be between 1 and %d", maxLimit))
+		}
+	}
+	if offset := query.Get("offset"); offset != "" {
+		page.Offset, err = strconv.Atoi(offset)
+		if err != nil || page.Offset < 0 {
+			return page, errs.NewError(errs.ErrBadRequest, errors.New("offset must not be negative"))
+		}
+	}
+	return page, nil
+}
+
+func parseIncludeDeleted(query url.Values) (bool, errs.ChatError) {
+	includeDeleted := query.Get("include_deleted")
+	if includeDeleted == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(includeDeleted)
+	if err != nil {
+		return false, errs.NewError(errs.ErrBadRequest, errors.New("include_deleted must be true or false"))
+	}
+	return value, nil
+}
+
+func NewController(
+	userRepo user.ReaderWriterRepository,
+	loginRepo user.LoginRepository,
+	sessionService sessionManager.Service,
+) user.Controller {
+	return &controller{userRepo: userRepo, loginRepo: loginRepo, sessionService: sessionService}
+}
@@ -0,0 +1,136 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	sessionMock "github.com/raffops/chat_auth/test/mocks/sessionManager"
+	userMock "github.com/raffops/chat_auth/test/mocks/user"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestController_ListUsers(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		wantFilters []userModels.Filter
+		wantSorts   []userModels.Sort
+		wantPage    userModels.Pagination
+		wantDeleted bool
+		total       int
+		wantNext    string
+	}{
+		{
+			name:        "Test default pagination",
+			url:         "/users",
+			wantFilters: []userModels.Filter{},
+			wantSorts:   []userModels.Sort{},
+			wantPage:    userModels.Pagination{Limit: 20},
+			total:       2,
+		},
+		{
+			name: "Test filters, sorts and next page",
+			url:  "/users?role=admin&status=active&sort=created_at:asc&limit=2",
+			wantFilters: []userModels.Filter{
+				{Key: "role", Value: authModels.RoleAdmin, Comparison: userModels.ComparisonEqual},
+				{Key: "status", Value: userModels.StatusActive, Comparison: userModels.ComparisonEqual},
+			},
+			wantSorts: []userModels.Sort{{Key: "created_at", Order: userModels.OrderAsc}},
+			wantPage:  userModels.Pagination{Limit: 2},
+			total:     5,
+			wantNext:  "/users?limit=2&offset=2&role=admin&sort=created_at%3Aasc&status=active",
+		},
+		{
+			name:        "Test include deleted users",
+			url:         "/users?include_deleted=true",
+			wantFilters: []userModels.Filter{},
+			wantSorts:   []userModels.Sort{},
+			wantPage:    userModels.Pagination{Limit: 20},
+			wantDeleted: true,
+			total:       2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := userMock.NewReaderWriterRepository(t)
+			users := []userModels.User{{Id: "1"}, {Id: "2"}}
+			repo.EXPECT().
+				ListUsers(mock.Anything, listColumns, tt.wantFilters, tt.wantSorts, tt.wantPage, tt.wantDeleted).
+				Return(users, nil).Once()
+			repo.EXPECT().CountUsers(mock.Anything, tt.wantFilters, tt.wantDeleted).Return(tt.total, nil).Once()
+			c := NewController(repo, userMock.NewLoginRepository(t), sessionMock.NewService(t))
+
+			w := httptest.NewRecorder()
+			c.ListUsers(w, httptest.NewRequest("GET", tt.url, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("ListUsers() got = %v, want %v", w.Code, http.StatusOK)
+			}
+			var got userModels.UserPage
+			_ = json.Unmarshal(w.Body.Bytes(), &got)
+			if got.Total != tt.total || len(got.Users) != len(users) {
+				t.Errorf("ListUsers() got = %v users of %v, want %v of %v", len(got.Users), got.Total, 2, tt.total)
+			}
+			if got.Next != tt.wantNext {
+				t.Errorf("ListUsers() next got = %v, want %v", got.Next, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestController_ListUsersInvalidQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "Test unknown role", url: "/users?role=owner"},
+		{name: "Test invalid sort key", url: "/users?sort=email:asc"},
+		{name: "Test invalid sort order", url: "/users?sort=created_at:up"},
+		{name: "Test limit too large", url: "/users?limit=1000"},
+		{name: "Test negative offset", url: "/users?offset=-1"},
+		{name: "Test invalid include deleted", url: "/users?include_deleted=maybe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewController(
+				userMock.NewReaderWriterRepository(t),
+				userMock.NewLoginRepository(t),
+				sessionMock.NewService(t),
+			)
+			w := httptest.NewRecorder()
+			c.ListUsers(w, httptest.NewRequest("GET", tt.url, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("ListUsers() got = %v, want %v", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestController_ListLogins(t *testing.T) {
+	repo := userMock.NewReaderWriterRepository(t)
+	repo.EXPECT().GetUser(mock.Anything, "id", "1", true).Return(userModels.User{Id: "1"}, nil).Once()
+	loginRepo := userMock.NewLoginRepository(t)
+	logins := []userModels.Login{{Id: 3, UserId: "1"}, {Id: 2, UserId: "1"}}
+	loginRepo.EXPECT().ListLogins(mock.Anything, "1", userModels.Pagination{Limit: 2}).Return(logins, nil).Once()
+	loginRepo.EXPECT().CountLogins(mock.Anything, "1").Return(3, nil).Once()
+	c := NewController(repo, loginRepo, sessionMock.NewService(t))
+
+	w := httptest.NewRecorder()
+	r := mux.SetURLVars(httptest.NewRequest("GET", "/users/1/logins?limit=2", nil), map[string]string{"id": "1"})
+	c.ListLogins(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ListLogins() got = %v, want %v", w.Code, http.StatusOK)
+	}
+	var got userModels.LoginPage
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got.Total != 3 || len(got.Logins) != len(logins) {
+		t.Errorf("ListLogins() got = %v logins of %v, want %v of %v", len(got.Logins), got.Total, len(logins), 3)
+	}
+	if want := "/users/1/logins?limit=2&offset=2"; got.Next != want {
+		t.Errorf("ListLogins() next got = %v, want %v", got.Next, want)
+	}
+}
@@ -3,20 +3,32 @@ package user
 import (
 	"context"
 	"database/sql"
+	"net/http"
+	"time"
 
 	userModels "github.com/raffops/chat_auth/internal/app/user/models"
 	"github.com/raffops/chat_commons/pkg/errs"
 )
 
+type Controller interface {
+	ListUsers(w http.ResponseWriter, r *http.Request)
+	GetUser(w http.ResponseWriter, r *http.Request)
+	UpdateUser(w http.ResponseWriter, r *http.Request)
+	ListLogins(w http.ResponseWriter, r *http.Request)
+}
+
 type ReaderRepository interface {
-	GetUser(ctx context.Context, key string, value interface{}) (userModels.User, errs.ChatError)
+	GetUser(ctx context.Context, key string, value interface{}, includeDeleted bool) (userModels.User, errs.ChatError)
+	GetPasswordHash(ctx context.Context, userId string) (string, errs.ChatError)
 	ListUsers(
 		ctx context.Context,
 		columns []string,
 		filters []userModels.Filter,
 		sorts []userModels.Sort,
 		page userModels.Pagination,
+		includeDeleted bool,
 	) ([]userModels.User, errs.ChatError)
+	CountUsers(ctx context.Context, filters []userModels.Filter, includeDeleted bool) (int, errs.ChatError)
 }
 
 type WriterRepository interface {
@@ -30,3 +42,23 @@ type ReaderWriterRepository interface {
 	ReaderRepository
 	WriterRepository
 }
+
+type LoginRepository interface {
+	RecordLogin(ctx context.Context, login userModels.Login) (userModels.Login, errs.ChatError)
+	CloseLogins(ctx context.Context, handles []string, logoutTime time.Time) errs.ChatError
+	ListOpenLogins(ctx context.Context, afterId int64, limit int) ([]userModels.Login, errs.ChatError)
+	ListLogins(ctx context.Context, userId string, page userModels.Pagination) ([]userModels.Login, errs.ChatError)
+	CountLogins(ctx context.Context, userId string) (int, errs.ChatError)
+}
+
+type AuthTypeRepository interface {
+	ListAuthTypes(ctx context.Context) (map[string]userModels.AuthTypeId, errs.ChatError)
+	CreateAuthTypes(ctx context.Context, names []string) errs.ChatError
+}
+
+type IdentityRepository interface {
+	CreateIdentity(ctx context.Context, tx *sql.Tx, identity userModels.Identity) (userModels.Identity, errs.ChatError)
+	GetIdentity(ctx context.Context, provider, subject string) (userModels.Identity, errs.ChatError)
+	ListIdentities(ctx context.Context, userId string) ([]userModels.Identity, errs.ChatError)
+	DeleteIdentity(ctx context.Context, userId string, id int64) errs.ChatError
+}
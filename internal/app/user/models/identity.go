@@ -0,0 +1,14 @@
+package user
+
+import "time"
+
+// Identity is the account of a user at an OAuth provider, found by the subject the provider
+// identifies it with. A user links at most one identity of each provider.
+type Identity struct {
+	Id       int64     `json:"id"`
+	UserId   string    `json:"user_id"`
+	Provider string    `json:"provider"`
+	Subject  string    `json:"subject"`
+	Email    string    `json:"email,omitempty"`
+	LinkedAt time.Time `json:"linked_at"`
+}
@@ -0,0 +1,27 @@
+package user
+
+import "time"
+
+// Login is an entry of the login history of a user. SessionHandle is the handle of the
+// session, the one it is listed with, never the session id. LogoutTime is nil while the session is
+// open, and set on logout or once the session expired.
+//
+// Entries moved from the former 'login_history' column have no session, provider or client.
+type Login struct {
+	Id            int64      `json:"id"`
+	UserId        string     `json:"user_id"`
+	SessionHandle string     `json:"session_handle,omitempty"`
+	Provider      string     `json:"provider,omitempty"`
+	Ip            string     `json:"ip,omitempty"`
+	UserAgent     string     `json:"user_agent,omitempty"`
+	LoginTime     time.Time  `json:"login_time"`
+	LogoutTime    *time.Time `json:"logout_time,omitempty"`
+}
+
+// LoginPage is a page of the login history, newest first. Next is the link to the
+// following page, empty on the last one.
+type LoginPage struct {
+	Logins []Login `json:"logins"`
+	Total  int     `json:"total"`
+	Next   string  `json:"next,omitempty"`
+}
@@ -6,22 +6,17 @@ import (
 	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
 )
 
-type LoginHistory struct {
-	LoginTime  time.Time `json:"login_time"`
-	LogoutTime time.Time `json:"logout_time"`
-}
-
 type User struct {
 	Id           string            `json:"id,omitempty" validate:"required,uuid4"`
 	Username     string            `json:"name,omitempty" validate:"required,min=5,max=100"`
 	Email        string            `json:"email,omitempty"`
-	AuthType     AuthTypeId        `json:"auth_type,omitempty" validate:"required, oneof=GOOGLE GITHUB"`
+	AuthType     AuthTypeId        `json:"auth_type,omitempty" validate:"required"`
 	Role         authModels.RoleId `json:"role,omitempty" validate:"required, oneof=ADMIN USER"`
 	Status       StatusId          `json:"status,omitempty" validate:"required, oneof=ACTIVE INACTIVE"`
-	LoginHistory []LoginHistory    `json:"login_history,omitempty"`
 	CreatedAt    time.Time         `json:"created_at,omitempty"`
 	UpdatedAt    time.Time         `json:"updated_at,omitempty"`
 	DeletedAt    time.Time         `json:"deleted_at,omitempty"`
+	PasswordHash string            `json:"-"`
 }
 
 type StatusId uint
@@ -44,18 +39,28 @@ var MapStatusString = map[string]StatusId{
 type AuthTypeId uint
 
 const (
-	AuthTypeGoogle AuthTypeId = 1
-	AuthTypeGithub AuthTypeId = 2
+	AuthTypeGoogle   AuthTypeId = 1
+	AuthTypeGithub   AuthTypeId = 2
+	AuthTypePassword AuthTypeId = 3
 )
 
 var MapAuthType = map[AuthTypeId]string{
-	AuthTypeGoogle: "google",
-	AuthTypeGithub: "github",
+	AuthTypeGoogle:   "google",
+	AuthTypeGithub:   "github",
+	AuthTypePassword: "password",
 }
 
 var MapAuthTypeString = map[string]AuthTypeId{
-	"google": AuthTypeGoogle,
-	"github": AuthTypeGithub,
+	"google":   AuthTypeGoogle,
+	"github":   AuthTypeGithub,
+	"password": AuthTypePassword,
+}
+
+// RegisterAuthType adds an auth type loaded from the 'auth_type' table to MapAuthType and
+// MapAuthTypeString. It is not safe for concurrent use, and is meant to be called at startup.
+func RegisterAuthType(id AuthTypeId, name string) {
+	MapAuthType[id] = name
+	MapAuthTypeString[name] = id
 }
 
 type Filter struct {
@@ -94,7 +99,6 @@ var (
 		"auth_type",
 		"role",
 		"status",
-		"login_history",
 		"created_at",
 		"updated_at",
 		"deleted_at",
@@ -107,3 +111,17 @@ type Pagination struct {
 	Limit  int `validate:"required,min=1,max=100"`
 	Offset int `validate:"required,min=0"`
 }
+
+// UserPage is a page of users. Next is the link to the following page, empty on the last one.
+type UserPage struct {
+	Users []User `json:"users"`
+	Total int    `json:"total"`
+	Next  string `json:"next,omitempty"`
+}
+
+// UserUpdate holds the fields that admins can change, by their names. Empty fields are
+// left unchanged.
+type UserUpdate struct {
+	Role   string `json:"role,omitempty"`
+	Status string `json:"status,omitempty"`
+}
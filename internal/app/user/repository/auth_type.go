@@ -0,0 +1,84 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/raffops/chat_auth/internal/app/user"
+	userModel "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type authTypeRepository struct {
+	db *sql.DB
+}
+
+// ListAuthTypes loads the ids of the auth types by their names.
+func (a authTypeRepository) ListAuthTypes(ctx context.Context) (map[string]userModel.AuthTypeId, errs.ChatError) {
+	queryString, args := BuildListAuthTypesQuery()
+	rows, err := a.db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.Debug("error closing rows", zap.Error(err))
+		}
+	}(rows)
+
+	authTypes := map[string]userModel.AuthTypeId{}
+	for rows.Next() {
+		var id int16
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, errs.NewError(errs.ErrInternal, err)
+		}
+		authTypes[name] = userModel.AuthTypeId(id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	return authTypes, nil
+}
+
+func BuildListAuthTypesQuery() (string, []interface{}) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("id", "name").
+		From("public.auth_type").
+		OrderBy("id")
+	return sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+// CreateAuthTypes adds the auth types missing from the table. The ids of the existing ones
+// are kept, since the users reference them.
+func (a authTypeRepository) CreateAuthTypes(ctx context.Context, names []string) errs.ChatError {
+	if len(names) == 0 {
+		return nil
+	}
+	queryString, args := BuildCreateAuthTypesQuery(names)
+	_, err := a.db.ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return nil
+}
+
+func BuildCreateAuthTypesQuery(names []string) (string, []interface{}) {
+	ib := sqlbuilder.NewInsertBuilder()
+	ib.InsertInto("public.auth_type").
+		Cols("name")
+	for _, name := range names {
+		ib.Values(name)
+	}
+	queryString, args := ib.BuildWithFlavor(sqlbuilder.PostgreSQL)
+	queryString += " ON CONFLICT (name) DO NOTHING"
+	return queryString, args
+}
+
+func NewAuthTypeRepository(db *sql.DB) user.AuthTypeRepository {
+	return &authTypeRepository{db: db}
+}
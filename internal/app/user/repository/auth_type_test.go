@@ -0,0 +1,43 @@
+package user
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildAuthTypeQueries(t *testing.T) {
+	listQuery, listArgs := BuildListAuthTypesQuery()
+	createQuery, createArgs := BuildCreateAuthTypesQuery([]string{"google", "keycloak"})
+	tests := []struct {
+		name     string
+		got      string
+		gotArgs  []interface{}
+		want     string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "Test list auth types query",
+			got:      listQuery,
+			gotArgs:  listArgs,
+			want:     "SELECT id, name FROM public.auth_type ORDER BY id",
+			wantArgs: nil,
+		},
+		{
+			name:     "Test create auth types query",
+			got:      createQuery,
+			gotArgs:  createArgs,
+			want:     "INSERT INTO public.auth_type (name) VALUES ($1), ($2) ON CONFLICT (name) DO NOTHING",
+			wantArgs: []interface{}{"google", "keycloak"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("query \n\tgot = %v\n\twant = %v", tt.got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.gotArgs, tt.wantArgs) {
+				t.Errorf("args got = %v, want %v", tt.gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/lib/pq"
+	"github.com/raffops/chat_auth/internal/app/user"
+	userModel "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+var identityColumns = []string{"id", "user_id", "provider", "subject", "email", "linked_at"}
+
+type identityRepository struct {
+	db *sql.DB
+}
+
+// CreateIdentity links an identity to its user, in tx when it is not nil. An identity linked
+// to any user, or a second identity of the same provider, is a conflict.
+func (i identityRepository) CreateIdentity(
+	ctx context.Context,
+	tx *sql.Tx,
+	identity userModel.Identity,
+) (userModel.Identity, errs.ChatError) {
+	queryString, args := BuildCreateIdentityQuery(identity)
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, queryString, args...)
+	} else {
+		row = i.db.QueryRowContext(ctx, queryString, args...)
+	}
+	err := row.Scan(&identity.Id, &identity.LinkedAt)
+	if err != nil {
+		return userModel.Identity{}, getCreateIdentityError(err, identity)
+	}
+	identity.LinkedAt = identity.LinkedAt.UTC()
+	return identity, nil
+}
+
+func getCreateIdentityError(err error, identity userModel.Identity) errs.ChatError {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		switch pqErr.Constraint {
+		case "uq_user_identity_subject":
+			return errs.NewError(errs.ErrConflict,
+				fmt.Errorf("%s identity already linked to a user", identity.Provider),
+			)
+		case "uq_user_identity_user_provider":
+			return errs.NewError(errs.ErrConflict,
+				fmt.Errorf("user already has a %s identity", identity.Provider),
+			)
+		}
+	}
+	return errs.NewError(errs.ErrInternal, err)
+}
+
+func BuildCreateIdentityQuery(identity userModel.Identity) (string, []interface{}) {
+	ib := sqlbuilder.NewInsertBuilder()
+	ib.InsertInto("public.user_identity").
+		Cols("user_id", "provider", "subject", "email").
+		Values(identity.UserId, identity.Provider, identity.Subject, nullString(identity.Email))
+	queryString, args := ib.BuildWithFlavor(sqlbuilder.PostgreSQL)
+	queryString += " RETURNING id, linked_at"
+	return queryString, args
+}
+
+// GetIdentity finds the identity of a provider by its subject.
+func (i identityRepository) GetIdentity(
+	ctx context.Context,
+	provider, subject string,
+) (userModel.Identity, errs.ChatError) {
+	queryString, args := BuildGetIdentityQuery(provider, subject)
+	rows, err := i.queryIdentities(ctx, queryString, args)
+	if err != nil {
+		return userModel.Identity{}, err
+	}
+	if len(rows) == 0 {
+		return userModel.Identity{}, errs.NewError(errs.ErrNotFound,
+			fmt.Errorf("%s identity with subject=%s not found", provider, subject),
+		)
+	}
+	return rows[0], nil
+}
+
+func BuildGetIdentityQuery(provider, subject string) (string, []interface{}) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select(identityColumns...).
+		From("public.user_identity").
+		Where(sb.Equal("provider", provider), sb.Equal("subject", subject))
+	return sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+// ListIdentities lists the identities linked to a user, oldest first.
+func (i identityRepository) ListIdentities(ctx context.Context, userId string) ([]userModel.Identity, errs.ChatError) {
+	queryString, args := BuildListIdentitiesQuery(userId)
+	return i.queryIdentities(ctx, queryString, args)
+}
+
+func BuildListIdentitiesQuery(userId string) (string, []interface{}) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select(identityColumns...).
+		From("public.user_identity").
+		Where(sb.Equal("user_id", userId)).
+		OrderBy("id")
+	return sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+// DeleteIdentity unlinks an identity of the user. Identities of other users are not found.
+func (i identityRepository) DeleteIdentity(ctx context.Context, userId string, id int64) errs.ChatError {
+	queryString, args := BuildDeleteIdentityQuery(userId, id)
+	result, err := i.db.ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	if affected == 0 {
+		return errs.NewError(errs.ErrNotFound, fmt.Errorf("identity with id=%d not found", id))
+	}
+	return nil
+}
+
+func BuildDeleteIdentityQuery(userId string, id int64) (string, []interface{}) {
+	db := sqlbuilder.NewDeleteBuilder()
+	db.DeleteFrom("public.user_identity").
+		Where(db.Equal("id", id), db.Equal("user_id", userId))
+	return db.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+func (i identityRepository) queryIdentities(
+	ctx context.Context,
+	queryString string,
+	args []interface{},
+) ([]userModel.Identity, errs.ChatError) {
+	rows, err := i.db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.Debug("error closing rows", zap.Error(err))
+		}
+	}(rows)
+
+	identities := make([]userModel.Identity, 0)
+	for rows.Next() {
+		var identity userModel.Identity
+		var email sql.NullString
+		err = rows.Scan(
+			&identity.Id,
+			&identity.UserId,
+			&identity.Provider,
+			&identity.Subject,
+			&email,
+			&identity.LinkedAt,
+		)
+		if err != nil {
+			return nil, errs.NewError(errs.ErrInternal, err)
+		}
+		identity.Email = email.String
+		identity.LinkedAt = identity.LinkedAt.UTC()
+		identities = append(identities, identity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	return identities, nil
+}
+
+func NewIdentityRepository(db *sql.DB) user.IdentityRepository {
+	return &identityRepository{db: db}
+}
@@ -0,0 +1,64 @@
+package user
+
+import (
+	"reflect"
+	"testing"
+
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+)
+
+func TestBuildIdentityQueries(t *testing.T) {
+	identity := userModels.Identity{UserId: "1", Provider: "github", Subject: "583231"}
+	createQuery, createArgs := BuildCreateIdentityQuery(identity)
+	getQuery, getArgs := BuildGetIdentityQuery("github", "583231")
+	listQuery, listArgs := BuildListIdentitiesQuery("1")
+	deleteQuery, deleteArgs := BuildDeleteIdentityQuery("1", 2)
+	columns := "id, user_id, provider, subject, email, linked_at"
+	tests := []struct {
+		name     string
+		got      string
+		gotArgs  []interface{}
+		want     string
+		wantArgs []interface{}
+	}{
+		{
+			name:    "Test create identity query",
+			got:     createQuery,
+			gotArgs: createArgs,
+			want: "INSERT INTO public.user_identity (user_id, provider, subject, email) " +
+				"VALUES ($1, $2, $3, $4) RETURNING id, linked_at",
+			wantArgs: []interface{}{"1", "github", "583231", nullString("")},
+		},
+		{
+			name:     "Test get identity query",
+			got:      getQuery,
+			gotArgs:  getArgs,
+			want:     "SELECT " + columns + " FROM public.user_identity WHERE provider = $1 AND subject = $2",
+			wantArgs: []interface{}{"github", "583231"},
+		},
+		{
+			name:     "Test list identities query",
+			got:      listQuery,
+			gotArgs:  listArgs,
+			want:     "SELECT " + columns + " FROM public.user_identity WHERE user_id = $1 ORDER BY id",
+			wantArgs: []interface{}{"1"},
+		},
+		{
+			name:     "Test delete identity query",
+			got:      deleteQuery,
+			gotArgs:  deleteArgs,
+			want:     "DELETE FROM public.user_identity WHERE id = $1 AND user_id = $2",
+			wantArgs: []interface{}{int64(2), "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("query \n\tgot = %v\n\twant = %v", tt.got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.gotArgs, tt.wantArgs) {
+				t.Errorf("args got = %v, want %v", tt.gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/raffops/chat_auth/internal/app/user"
+	userModel "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/raffops/chat_commons/pkg/errs"
+	"github.com/raffops/chat_commons/pkg/logger"
+	"go.uber.org/zap"
+)
+
+var loginColumns = []string{
+	"id",
+	"user_id",
+	"session_handle",
+	"provider",
+	"ip",
+	"user_agent",
+	"login_time",
+	"logout_time",
+}
+
+type loginRepository struct {
+	db *sql.DB
+}
+
+// RecordLogin appends a login to the history of the user.
+func (p loginRepository) RecordLogin(ctx context.Context, login userModel.Login) (userModel.Login, errs.ChatError) {
+	queryString, args := BuildRecordLoginQuery(login)
+	err := p.db.QueryRowContext(ctx, queryString, args...).Scan(&login.Id)
+	if err != nil {
+		return userModel.Login{}, errs.NewError(errs.ErrInternal, err)
+	}
+	return login, nil
+}
+
+func BuildRecordLoginQuery(login userModel.Login) (string, []interface{}) {
+	ib := sqlbuilder.NewInsertBuilder()
+	ib.InsertInto("public.user_login").
+		Cols("user_id", "session_handle", "provider", "ip", "user_agent", "login_time").
+		Values(
+			login.UserId,
+			nullString(login.SessionHandle),
+			nullString(login.Provider),
+			nullString(login.Ip),
+			nullString(login.UserAgent),
+			login.LoginTime,
+		)
+	queryString, args := ib.BuildWithFlavor(sqlbuilder.PostgreSQL)
+	queryString += " RETURNING id"
+	return queryString, args
+}
+
+// CloseLogins sets the logout time of the open logins of the session handles. Logins already
+// closed keep their logout time.
+func (p loginRepository) CloseLogins(ctx context.Context, handles []string, logoutTime time.Time) errs.ChatError {
+	if len(handles) == 0 {
+		return nil
+	}
+	queryString, args := BuildCloseLoginsQuery(handles, logoutTime)
+	_, err := p.db.ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return errs.NewError(errs.ErrInternal, err)
+	}
+	return nil
+}
+
+func BuildCloseLoginsQuery(handles []string, logoutTime time.Time) (string, []interface{}) {
+	ids := make([]interface{}, 0, len(handles))
+	for _, handle := range handles {
+		ids = append(ids, handle)
+	}
+	ub := sqlbuilder.NewUpdateBuilder()
+	ub.Update("public.user_login").
+		Set(ub.Assign("logout_time", logoutTime)).
+		Where(ub.In("session_handle", ids...), ub.IsNull("logout_time"))
+	return ub.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+// ListOpenLogins lists the logins without logout time, by id, starting after afterId.
+// Logins moved from the former 'login_history' column have no session, so they are left out.
+func (p loginRepository) ListOpenLogins(
+	ctx context.Context,
+	afterId int64,
+	limit int,
+) ([]userModel.Login, errs.ChatError) {
+	queryString, args := BuildListOpenLoginsQuery(afterId, limit)
+	return p.queryLogins(ctx, queryString, args)
+}
+
+func BuildListOpenLoginsQuery(afterId int64, limit int) (string, []interface{}) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select(loginColumns...).
+		From("public.user_login").
+		Where(sb.GreaterThan("id", afterId), sb.IsNull("logout_time"), sb.IsNotNull("session_handle")).
+		OrderBy("id").Asc().
+		Limit(limit)
+	return sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+// ListLogins lists the login history of a user, newest first.
+func (p loginRepository) ListLogins(
+	ctx context.Context,
+	userId string,
+	page userModel.Pagination,
+) ([]userModel.Login, errs.ChatError) {
+	queryString, args := BuildListLoginsQuery(userId, page)
+	return p.queryLogins(ctx, queryString, args)
+}
+
+func BuildListLoginsQuery(userId string, page userModel.Pagination) (string, []interface{}) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select(loginColumns...).
+		From("public.user_login").
+		Where(sb.Equal("user_id", userId)).
+		OrderBy("login_time DESC", "id DESC").
+		Offset(page.Offset).
+		Limit(page.Limit)
+	return sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+// CountLogins counts the logins of a user, to paginate 'ListLogins'.
+func (p loginRepository) CountLogins(ctx context.Context, userId string) (int, errs.ChatError) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("COUNT(*)").
+		From("public.user_login").
+		Where(sb.Equal("user_id", userId))
+	queryString, args := sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+
+	var count int
+	err := p.db.QueryRowContext(ctx, queryString, args...).Scan(&count)
+	if err != nil {
+		return 0, errs.NewError(errs.ErrInternal, err)
+	}
+	return count, nil
+}
+
+func (p loginRepository) queryLogins(
+	ctx context.Context,
+	queryString string,
+	args []interface{},
+) ([]userModel.Login, errs.ChatError) {
+	rows, err := p.db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.Debug("error closing rows", zap.Error(err))
+		}
+	}(rows)
+
+	logins := make([]userModel.Login, 0)
+	for rows.Next() {
+		var login userModel.Login
+		var sessionHandle, provider, ip, userAgent sql.NullString
+		var logoutTime sql.NullTime
+		err = rows.Scan(
+			&login.Id,
+			&login.UserId,
+			&sessionHandle,
+			&provider,
+			&ip,
+			&userAgent,
+			&login.LoginTime,
+			&logoutTime,
+		)
+		if err != nil {
+			return nil, errs.NewError(errs.ErrInternal, err)
+		}
+		login.SessionHandle = sessionHandle.String
+		login.Provider = provider.String
+		login.Ip = ip.String
+		login.UserAgent = userAgent.String
+		login.LoginTime = login.LoginTime.UTC()
+		if logoutTime.Valid {
+			t := logoutTime.Time.UTC()
+			login.LogoutTime = &t
+		}
+		logins = append(logins, login)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errs.NewError(errs.ErrInternal, err)
+	}
+	return logins, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func NewLoginRepository(db *sql.DB) user.LoginRepository {
+	return &loginRepository{db: db}
+}
@@ -0,0 +1,69 @@
+package user
+
+import (
+	"testing"
+
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+)
+
+func TestBuildLoginQueries(t *testing.T) {
+	login := userModels.Login{UserId: "1", SessionHandle: "session", Provider: "google", LoginTime: RandomTime}
+	recordQuery, recordArgs := BuildRecordLoginQuery(login)
+	closeQuery, closeArgs := BuildCloseLoginsQuery([]string{"session-1", "session-2"}, RandomTime)
+	openQuery, openArgs := BuildListOpenLoginsQuery(10, 100)
+	listQuery, listArgs := BuildListLoginsQuery("1", userModels.Pagination{Limit: 20, Offset: 40})
+	columns := "id, user_id, session_handle, provider, ip, user_agent, login_time, logout_time"
+	tests := []struct {
+		name     string
+		got      string
+		gotArgs  []interface{}
+		want     string
+		wantArgs int
+	}{
+		{
+			name:    "Test record login query",
+			got:     recordQuery,
+			gotArgs: recordArgs,
+			want: "INSERT INTO public.user_login (user_id, session_handle, provider, ip, user_agent, login_time) " +
+				"VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+			wantArgs: 6,
+		},
+		{
+			name:    "Test close logins query",
+			got:     closeQuery,
+			gotArgs: closeArgs,
+			want: "UPDATE public.user_login SET logout_time = $1 " +
+				"WHERE session_handle IN ($2, $3) AND logout_time IS NULL",
+			wantArgs: 3,
+		},
+		{
+			name:    "Test list open logins query",
+			got:     openQuery,
+			gotArgs: openArgs,
+			want: "SELECT " + columns + " FROM public.user_login " +
+				"WHERE id > $1 AND logout_time IS NULL AND session_handle IS NOT NULL ORDER BY id ASC LIMIT 100",
+			wantArgs: 1,
+		},
+		{
+			name:    "Test list logins query",
+			got:     listQuery,
+			gotArgs: listArgs,
+			want: "SELECT " + columns + " FROM public.user_login " +
+				"WHERE user_id = $1 ORDER BY login_time DESC, id DESC LIMIT 20 OFFSET 40",
+			wantArgs: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("query \n\tgot = %v\n\twant = %v", tt.got, tt.want)
+			}
+			if len(tt.gotArgs) != tt.wantArgs {
+				t.Errorf("args got = %v, want %v args", tt.gotArgs, tt.wantArgs)
+			}
+		})
+	}
+	if recordArgs[0] != "1" || recordArgs[3] != nullString("") {
+		t.Errorf("BuildRecordLoginQuery() args = %v, want the user id and a null ip", recordArgs)
+	}
+}
@@ -0,0 +1,2 @@
+ALTER TABLE public.user
+    ADD COLUMN password_hash VARCHAR(255);
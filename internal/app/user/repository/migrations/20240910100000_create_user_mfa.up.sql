@@ -0,0 +1,23 @@
+CREATE TABLE public.user_mfa
+(
+    user_id          uuid PRIMARY KEY,
+    encrypted_secret TEXT    NOT NULL,
+    confirmed        BOOLEAN NOT NULL         DEFAULT FALSE,
+    last_used_step   BIGINT  NOT NULL         DEFAULT 0,
+    created_at       TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at       TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_mfa_user_id FOREIGN KEY (user_id) REFERENCES public.user (id) ON DELETE CASCADE
+);
+
+CREATE TABLE public.user_recovery_code
+(
+    id         uuid PRIMARY KEY         DEFAULT uuid_generate_v4(),
+    user_id    uuid        NOT NULL,
+    code_hash  VARCHAR(64) NOT NULL,
+    used_at    TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_recovery_code_user_id FOREIGN KEY (user_id) REFERENCES public.user (id) ON DELETE CASCADE,
+    CONSTRAINT uq_user_recovery_code UNIQUE (user_id, code_hash)
+);
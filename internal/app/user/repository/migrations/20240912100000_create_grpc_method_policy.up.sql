@@ -0,0 +1,20 @@
+CREATE TABLE public.grpc_method_policy
+(
+    method     VARCHAR(255) NOT NULL,
+    role_id    INT          NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT pk_grpc_method_policy PRIMARY KEY (method, role_id),
+    CONSTRAINT fk_grpc_method_policy_role_id FOREIGN KEY (role_id) REFERENCES public.role (id)
+);
+
+INSERT INTO public.grpc_method_policy (method, role_id)
+VALUES ('/auth.AuthService/ValidateSession', 1),
+       ('/auth.AuthService/ValidateSession', 2),
+       ('/auth.AuthService/GetSession', 1),
+       ('/auth.AuthService/GetSession', 2),
+       ('/auth.AuthService/RevokeSession', 1),
+       ('/auth.AuthService/RevokeSession', 2),
+       ('/auth.AuthService/RevokeUserSessions', 1),
+       ('/auth.AuthService/GetUser', 1),
+       ('/auth.AuthService/ListUsers', 1);
@@ -0,0 +1,4 @@
+DROP TABLE public.user_purge_audit;
+
+ALTER TABLE public.user
+    DROP COLUMN purged_at;
@@ -0,0 +1,13 @@
+ALTER TABLE public.user
+    ADD COLUMN purged_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE public.user_purge_audit
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    uuid        NOT NULL,
+    mode       VARCHAR(16) NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    purged_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_purge_audit_user_id ON public.user_purge_audit (user_id);
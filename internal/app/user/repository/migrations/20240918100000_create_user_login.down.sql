@@ -0,0 +1,4 @@
+ALTER TABLE public.user
+    ADD COLUMN login_history jsonb;
+
+DROP TABLE public.user_login;
@@ -0,0 +1,28 @@
+CREATE TABLE public.user_login
+(
+    id             BIGSERIAL PRIMARY KEY,
+    user_id        uuid                     NOT NULL,
+    session_handle VARCHAR(64),
+    provider       VARCHAR(32),
+    ip             VARCHAR(45),
+    user_agent     TEXT,
+    login_time     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    logout_time    TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT fk_user_login_user_id FOREIGN KEY (user_id) REFERENCES public.user (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_login_user_id ON public.user_login (user_id, login_time DESC);
+CREATE INDEX IF NOT EXISTS idx_user_login_session_handle ON public.user_login (session_handle);
+CREATE INDEX IF NOT EXISTS idx_user_login_open ON public.user_login (id) WHERE logout_time IS NULL;
+
+INSERT INTO public.user_login (user_id, login_time, logout_time)
+SELECT u.id,
+       (entry ->> 'login_time')::TIMESTAMP WITH TIME ZONE,
+       NULLIF(entry ->> 'logout_time', '0001-01-01T00:00:00Z')::TIMESTAMP WITH TIME ZONE
+FROM public.user u,
+     jsonb_array_elements(u.login_history) entry
+WHERE jsonb_typeof(u.login_history) = 'array';
+
+ALTER TABLE public.user
+    DROP COLUMN login_history;
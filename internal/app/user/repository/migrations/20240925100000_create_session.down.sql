@@ -0,0 +1,3 @@
+DROP TABLE public.session_set_member;
+
+DROP TABLE public.session;
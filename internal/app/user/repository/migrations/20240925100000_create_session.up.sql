@@ -0,0 +1,21 @@
+CREATE TABLE public.session
+(
+    id         VARCHAR(255) PRIMARY KEY,
+    value      TEXT,
+    fields     jsonb,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_expires_at ON public.session (expires_at) WHERE expires_at IS NOT NULL;
+
+CREATE TABLE public.session_set_member
+(
+    set_key    VARCHAR(255)             NOT NULL,
+    member     VARCHAR(255)             NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    PRIMARY KEY (set_key, member)
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_set_member_set_key ON public.session_set_member (set_key, expires_at);
+CREATE INDEX IF NOT EXISTS idx_session_set_member_expires_at ON public.session_set_member (expires_at);
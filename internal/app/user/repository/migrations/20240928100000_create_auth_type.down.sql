@@ -0,0 +1,4 @@
+ALTER TABLE public.user
+    DROP CONSTRAINT fk_user_auth_type;
+
+DROP TABLE public.auth_type;
@@ -0,0 +1,16 @@
+CREATE TABLE public.auth_type
+(
+    id         SMALLINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name       VARCHAR(32) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO public.auth_type (id, name)
+VALUES (1, 'google'),
+       (2, 'github'),
+       (3, 'password');
+
+SELECT setval(pg_get_serial_sequence('public.auth_type', 'id'), (SELECT MAX(id) FROM public.auth_type));
+
+ALTER TABLE public.user
+    ADD CONSTRAINT fk_user_auth_type FOREIGN KEY (auth_type) REFERENCES public.auth_type (id);
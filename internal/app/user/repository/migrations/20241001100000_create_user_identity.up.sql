@@ -0,0 +1,14 @@
+CREATE TABLE public.user_identity
+(
+    id        BIGSERIAL PRIMARY KEY,
+    user_id   uuid                     NOT NULL,
+    provider  VARCHAR(32)              NOT NULL,
+    subject   VARCHAR(255)             NOT NULL,
+    email     VARCHAR(255),
+    linked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_identity_user_id FOREIGN KEY (user_id) REFERENCES public.user (id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_identity_provider FOREIGN KEY (provider) REFERENCES public.auth_type (name),
+    CONSTRAINT uq_user_identity_subject UNIQUE (provider, subject),
+    CONSTRAINT uq_user_identity_user_provider UNIQUE (user_id, provider)
+);
@@ -3,7 +3,6 @@ package user
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -30,10 +29,10 @@ func (p repository) GetDB() *sql.DB {
 
 // fetchUser is a helper method to create a 'model.User' object
 func parseUser(
-	id, username, email, loginHistory sql.NullString,
+	id, username, email sql.NullString,
 	role, status, authType sql.NullInt16,
 	createdAt, updatedAt, deleteAt sql.NullTime,
-) (userModel.User, errs.ChatError) {
+) userModel.User {
 
 	fetchUser := userModel.User{}
 	if id.Valid {
@@ -54,15 +53,6 @@ func parseUser(
 	if authType.Valid {
 		fetchUser.AuthType = userModel.AuthTypeId(authType.Int16)
 	}
-	if loginHistory.Valid {
-		var loginHistoryObj []userModel.LoginHistory
-		err := json.Unmarshal([]byte(loginHistory.String), &loginHistoryObj)
-		if err != nil {
-			return userModel.User{}, errs.NewError(errs.ErrInternal, err)
-		}
-		fetchUser.LoginHistory = loginHistoryObj
-	}
-
 	if createdAt.Valid {
 		fetchUser.CreatedAt = createdAt.Time.UTC()
 	}
@@ -74,7 +64,7 @@ func parseUser(
 		fetchUser.DeletedAt = deleteAt.Time.UTC()
 	}
 
-	return fetchUser, nil
+	return fetchUser
 
 }
 
@@ -91,6 +81,7 @@ func (p repository) GetUser(
 	ctx context.Context,
 	key string,
 	value interface{},
+	includeDeleted bool,
 ) (userModel.User, errs.ChatError) {
 	if !slices.Contains([]string{"id", "username", "email"}, key) {
 		return userModel.User{}, errs.NewError(
@@ -101,11 +92,11 @@ func (p repository) GetUser(
 	if value == "" {
 		return userModel.User{}, errs.NewError(errs.ErrBadRequest, errors.New("invalid value"))
 	}
-	var id, username, email, loginHistory sql.NullString
+	var id, username, email sql.NullString
 	var roleId, statusId, authTypeId sql.NullInt16
 	var createdAt, updatedAt, deleteAt sql.NullTime
 
-	sb := buildSelectQuery(key, value)
+	sb := buildSelectQuery(key, value, includeDeleted)
 	queryString, args := sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
 	err := p.db.QueryRowContext(ctx, queryString, args...).
 		Scan(
@@ -115,7 +106,6 @@ func (p repository) GetUser(
 			&authTypeId,
 			&roleId,
 			&statusId,
-			&loginHistory,
 			&createdAt,
 			&updatedAt,
 			&deleteAt,
@@ -129,14 +119,33 @@ func (p repository) GetUser(
 		id,
 		username,
 		email,
-		loginHistory,
 		roleId,
 		statusId,
 		authTypeId,
 		createdAt,
 		updatedAt,
 		deleteAt,
-	)
+	), nil
+}
+
+// GetPasswordHash fetches the password hash of a user with 'userModel.AuthTypePassword'.
+// If the user does not exist or has no password, the svcError is 'errs.ErrNotFound'.
+func (p repository) GetPasswordHash(ctx context.Context, userId string) (string, errs.ChatError) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("password_hash").
+		From("public.user").
+		Where(sb.Equal("id", userId))
+	queryString, args := sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+
+	var passwordHash sql.NullString
+	err := p.db.QueryRowContext(ctx, queryString, args...).Scan(&passwordHash)
+	if err != nil {
+		return "", getSelectError(err, "id", userId)
+	}
+	if !passwordHash.Valid {
+		return "", errs.NewError(errs.ErrNotFound, fmt.Errorf("user with id=%s has no password", userId))
+	}
+	return passwordHash.String, nil
 }
 
 func getSelectError(err error, key, value string) errs.ChatError {
@@ -148,7 +157,7 @@ func getSelectError(err error, key, value string) errs.ChatError {
 	}
 }
 
-func buildSelectQuery(key string, value interface{}) *sqlbuilder.SelectBuilder {
+func buildSelectQuery(key string, value interface{}, includeDeleted bool) *sqlbuilder.SelectBuilder {
 	sb := sqlbuilder.NewSelectBuilder()
 	sb.Select("id",
 		"username",
@@ -156,30 +165,24 @@ func buildSelectQuery(key string, value interface{}) *sqlbuilder.SelectBuilder {
 		"auth_type",
 		"role",
 		"status",
-		"login_history",
 		"created_at",
 		"updated_at",
 		"deleted_at",
 	).
 		From("public.user").
 		Where(sb.Equal(key, value))
+	if !includeDeleted {
+		sb.Where(sb.IsNull("deleted_at"))
+	}
 	return sb
 }
 
 // CreateUser inserts a userModel into the database. It takes a userModel.User object as an argument
 func (p repository) CreateUser(ctx context.Context, tx *sql.Tx, u userModel.User) (userModel.User, errs.ChatError) {
-	loginHistory, err := json.Marshal(u.LoginHistory)
-	if err != nil {
-		return userModel.User{}, errs.NewError(
-			errs.ErrInternal,
-			fmt.Errorf("error marshalling login history: %w", err),
-		)
-	}
-
-	queryString, args := buildCreateQuery(u, loginHistory)
+	queryString, args := buildCreateQuery(u)
 	var id string
 	var createdAt time.Time
-	err = tx.QueryRowContext(ctx, queryString, args...).Scan(&id, &createdAt)
+	err := tx.QueryRowContext(ctx, queryString, args...).Scan(&id, &createdAt)
 	if err != nil {
 		return userModel.User{}, getCreateError(err)
 	}
@@ -206,16 +209,16 @@ func getCreateError(err error) errs.ChatError {
 	return errs.NewError(errs.ErrInternal, err)
 }
 
-func buildCreateQuery(u userModel.User, loginHistory []byte) (string, []interface{}) {
+func buildCreateQuery(u userModel.User) (string, []interface{}) {
 	sb := sqlbuilder.NewInsertBuilder()
 	sb.InsertInto("public.user").
-		Cols("username", "email", "auth_type", "role", "status", "login_history").
+		Cols("username", "email", "auth_type", "role", "status", "password_hash").
 		Values(u.Username,
 			u.Email,
 			u.AuthType,
 			u.Role,
 			u.Status,
-			loginHistory,
+			sql.NullString{String: u.PasswordHash, Valid: u.PasswordHash != ""},
 		)
 	queryString, args := sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
 	queryString += " RETURNING id, created_at"
@@ -227,22 +230,10 @@ func (p repository) UpdateUser(ctx context.Context, tx *sql.Tx, u userModel.User
 	userModel.User,
 	errs.ChatError,
 ) {
-	loginHistory, err := json.Marshal(u.LoginHistory)
-	if err != nil {
-		return userModel.User{}, errs.NewError(
-			errs.ErrInternal,
-			fmt.Errorf("error marshalling login history: %w", err),
-		)
-	}
-	loginHistoryStr := sql.NullString{
-		String: string(loginHistory),
-		Valid:  len(u.LoginHistory) > 0,
-	}
-
-	queryString, args := buildUpdateQuery(u, loginHistoryStr)
+	queryString, args := buildUpdateQuery(u)
 
 	var updatedAt sql.NullTime
-	err = tx.QueryRowContext(ctx, queryString, args...).Scan(&updatedAt)
+	err := tx.QueryRowContext(ctx, queryString, args...).Scan(&updatedAt)
 	if err != nil {
 		return userModel.User{}, getUpdateError(err, u.Id)
 	}
@@ -260,7 +251,7 @@ func getUpdateError(err error, id string) errs.ChatError {
 	return errs.NewError(errs.ErrInternal, err)
 }
 
-func buildUpdateQuery(u userModel.User, loginHistoryStr sql.NullString) (string, []interface{}) {
+func buildUpdateQuery(u userModel.User) (string, []interface{}) {
 	sb := sqlbuilder.NewUpdateBuilder()
 	sb.Update("public.user").
 		Set(
@@ -269,7 +260,6 @@ func buildUpdateQuery(u userModel.User, loginHistoryStr sql.NullString) (string,
 			sb.Assign("auth_type", u.AuthType),
 			sb.Assign("role", u.Role),
 			sb.Assign("status", u.Status),
-			sb.Assign("login_history", loginHistoryStr),
 		).
 		Where(sb.Equal("id", u.Id))
 	queryString, args := sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
@@ -295,17 +285,20 @@ func (p repository) DeleteUser(
 	if deletedAt.Valid {
 		u.DeletedAt = deletedAt.Time
 	}
+	u.Status = userModel.StatusInactive
 	return u, nil
 }
 
+// BuildDeleteQuery soft deletes a user, marking it deleted and inactive. Users already
+// deleted are not matched, so they are not found again.
 func BuildDeleteQuery(u userModel.User) (string, []interface{}) {
 	sb := sqlbuilder.NewUpdateBuilder()
 	sb.Update("public.user").
 		Set(
-			sb.Assign("deleted_at", "NOW()"),
-			sb.Assign("status", u.Status),
+			sb.Assign("deleted_at", sqlbuilder.Raw("NOW()")),
+			sb.Assign("status", userModel.StatusInactive),
 		).
-		Where(sb.Equal("id", u.Id))
+		Where(sb.Equal("id", u.Id), sb.IsNull("deleted_at"))
 	queryString, args := sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
 	queryString += " RETURNING deleted_at"
 	return queryString, args
@@ -315,17 +308,19 @@ func BuildDeleteQuery(u userModel.User) (string, []interface{}) {
 //
 // See 'userModel.ValidColumnsToFetch' for valid columns, 'userModel.ValidColumnsToFilter'
 // for valid filters, and 'userModel.ValidColumnsToSort' for valid sorts.
+// Soft deleted users are only listed with includeDeleted.
 func (p repository) ListUsers(
 	ctx context.Context,
 	columns []string,
 	filters []userModel.Filter,
 	sorts []userModel.Sort,
 	page userModel.Pagination,
+	includeDeleted bool,
 ) ([]userModel.User, errs.ChatError) {
 
-	queryString, args, err := BuildListQuery(columns, filters, sorts, page)
+	queryString, args, err := BuildListQuery(columns, filters, sorts, page, includeDeleted)
 	if err != nil {
-		return nil, errs.NewError(errs.ErrInternal, err)
+		return nil, asChatError(err)
 	}
 
 	rows, err := p.db.QueryContext(ctx, queryString, args...)
@@ -341,21 +336,20 @@ func (p repository) ListUsers(
 
 	users := make([]userModel.User, 0)
 	for rows.Next() {
-		var id, username, email, loginHistory sql.NullString
+		var id, username, email sql.NullString
 		var roleId, statusId, authTypeId sql.NullInt16
 		var createdAt, updatedAt, deleteAt sql.NullTime
 
 		mapColumns := map[string]interface{}{
-			"id":            &id,
-			"username":      &username,
-			"email":         &email,
-			"auth_type":     &authTypeId,
-			"role":          &roleId,
-			"status":        &statusId,
-			"login_history": &loginHistory,
-			"created_at":    &createdAt,
-			"updated_at":    &updatedAt,
-			"deleted_at":    &deleteAt,
+			"id":         &id,
+			"username":   &username,
+			"email":      &email,
+			"auth_type":  &authTypeId,
+			"role":       &roleId,
+			"status":     &statusId,
+			"created_at": &createdAt,
+			"updated_at": &updatedAt,
+			"deleted_at": &deleteAt,
 		}
 		columnsToScan := make([]interface{}, 0)
 		for _, column := range columns {
@@ -366,22 +360,17 @@ func (p repository) ListUsers(
 		if err != nil {
 			return nil, errs.NewError(errs.ErrInternal, err)
 		}
-		fetchUser, err := parseUser(
+		users = append(users, parseUser(
 			id,
 			username,
 			email,
-			loginHistory,
 			roleId,
 			statusId,
 			authTypeId,
 			createdAt,
 			updatedAt,
 			deleteAt,
-		)
-		if err != nil {
-			return nil, errs.NewError(errs.ErrInternal, err)
-		}
-		users = append(users, fetchUser)
+		))
 	}
 	return users, nil
 }
@@ -389,7 +378,8 @@ func (p repository) ListUsers(
 func BuildListQuery(columns []string,
 	filters []userModel.Filter,
 	sorts []userModel.Sort,
-	page userModel.Pagination) (string, []interface{}, error) {
+	page userModel.Pagination,
+	includeDeleted bool) (string, []interface{}, error) {
 
 	sb := sqlbuilder.NewSelectBuilder()
 
@@ -403,24 +393,9 @@ func BuildListQuery(columns []string,
 	}
 	sb.Select(columns...).From("public.user")
 
-	for _, filter := range filters {
-		if !slices.Contains(userModel.ValidColumnsToFilter, filter.Key) {
-			return "", nil, errs.NewError(errs.ErrBadRequest, errors.New("invalid filter key"))
-		}
-		switch filter.Comparison {
-		case userModel.ComparisonEqual:
-			sb.Where(sb.Equal(filter.Key, filter.Value))
-		case userModel.ComparisonGreaterThan:
-			sb.Where(sb.GreaterThan(filter.Key, filter.Value))
-		case userModel.ComparisonGreaterThanOrEqual:
-			sb.Where(sb.GreaterEqualThan(filter.Key, filter.Value))
-		case userModel.ComparisonLessThan:
-			sb.Where(sb.LessThan(filter.Key, filter.Value))
-		case userModel.ComparisonLessThanOrEqual:
-			sb.Where(sb.LessEqualThan(filter.Key, filter.Value))
-		default:
-			return "", nil, errs.NewError(errs.ErrBadRequest, errors.New("invalid comparison operator"))
-		}
+	err := addFilters(sb, filters, includeDeleted)
+	if err != nil {
+		return "", nil, err
 	}
 
 	if len(sorts) == 0 {
@@ -443,6 +418,73 @@ func BuildListQuery(columns []string,
 	return s, args, nil
 }
 
+// CountUsers counts the users matching the filters, to paginate 'ListUsers'.
+func (p repository) CountUsers(
+	ctx context.Context,
+	filters []userModel.Filter,
+	includeDeleted bool,
+) (int, errs.ChatError) {
+	queryString, args, err := BuildCountQuery(filters, includeDeleted)
+	if err != nil {
+		return 0, asChatError(err)
+	}
+
+	var count int
+	err = p.db.QueryRowContext(ctx, queryString, args...).Scan(&count)
+	if err != nil {
+		return 0, errs.NewError(errs.ErrInternal, err)
+	}
+	return count, nil
+}
+
+func BuildCountQuery(filters []userModel.Filter, includeDeleted bool) (string, []interface{}, error) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("COUNT(*)").From("public.user")
+
+	err := addFilters(sb, filters, includeDeleted)
+	if err != nil {
+		return "", nil, err
+	}
+
+	s, args := sb.BuildWithFlavor(sqlbuilder.PostgreSQL)
+	return s, args, nil
+}
+
+func addFilters(sb *sqlbuilder.SelectBuilder, filters []userModel.Filter, includeDeleted bool) error {
+	for _, filter := range filters {
+		if !slices.Contains(userModel.ValidColumnsToFilter, filter.Key) {
+			return errs.NewError(errs.ErrBadRequest, errors.New("invalid filter key"))
+		}
+		switch filter.Comparison {
+		case userModel.ComparisonEqual:
+			sb.Where(sb.Equal(filter.Key, filter.Value))
+		case userModel.ComparisonGreaterThan:
+			sb.Where(sb.GreaterThan(filter.Key, filter.Value))
+		case userModel.ComparisonGreaterThanOrEqual:
+			sb.Where(sb.GreaterEqualThan(filter.Key, filter.Value))
+		case userModel.ComparisonLessThan:
+			sb.Where(sb.LessThan(filter.Key, filter.Value))
+		case userModel.ComparisonLessThanOrEqual:
+			sb.Where(sb.LessEqualThan(filter.Key, filter.Value))
+		default:
+			return errs.NewError(errs.ErrBadRequest, errors.New("invalid comparison operator"))
+		}
+	}
+	if !includeDeleted {
+		sb.Where(sb.IsNull("deleted_at"))
+	}
+	return nil
+}
+
+// asChatError keeps the svcError of the errors returned by the query builders.
+func asChatError(err error) errs.ChatError {
+	var chatErr errs.ChatError
+	if errors.As(err, &chatErr) {
+		return chatErr
+	}
+	return errs.NewError(errs.ErrInternal, err)
+}
+
 func NewPostgresUserRepository(db *sql.DB) user.ReaderWriterRepository {
 	return &repository{db: db}
 }
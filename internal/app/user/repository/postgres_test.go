@@ -2,7 +2,6 @@ package user
 
 import (
 	"database/sql"
-	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -11,41 +10,31 @@ import (
 	userModels "github.com/raffops/chat_auth/internal/app/user/models"
 )
 
-var (
-	RandomTimeStr = "2024-05-07T12:27:58Z"
-	RandomTime    = time.Date(2024, time.May, 7, 12, 27, 58, 0, time.UTC)
-)
+var RandomTime = time.Date(2024, time.May, 7, 12, 27, 58, 0, time.UTC)
 
 func TestPostgresRepository_parseUser(t *testing.T) {
 	type args struct {
-		id           sql.NullString
-		username     sql.NullString
-		email        sql.NullString
-		authTye      sql.NullInt16
-		Role         sql.NullInt16
-		loginHistory sql.NullString
-		role         sql.NullInt16
-		status       sql.NullInt16
-		createdAt    sql.NullTime
-		updatedAt    sql.NullTime
-		deleteAt     sql.NullTime
+		id        sql.NullString
+		username  sql.NullString
+		email     sql.NullString
+		authTye   sql.NullInt16
+		role      sql.NullInt16
+		status    sql.NullInt16
+		createdAt sql.NullTime
+		updatedAt sql.NullTime
+		deleteAt  sql.NullTime
 	}
 	tests := []struct {
-		name    string
-		args    args
-		want    userModels.User
-		wantErr bool
+		name string
+		args args
+		want userModels.User
 	}{
 		{
 			name: "Test parseUser",
 			args: args{
-				id:       sql.NullString{String: "1", Valid: true},
-				username: sql.NullString{String: "John Doe", Valid: true},
-				email:    sql.NullString{String: "john@doe", Valid: true},
-				loginHistory: sql.NullString{String: fmt.Sprintf(`[{"login_time":"%s","logout_time":"%s"}]`,
-					RandomTimeStr, RandomTimeStr),
-					Valid: true,
-				},
+				id:        sql.NullString{String: "1", Valid: true},
+				username:  sql.NullString{String: "John Doe", Valid: true},
+				email:     sql.NullString{String: "john@doe", Valid: true},
 				authTye:   sql.NullInt16{Int16: int16(userModels.AuthTypeGoogle), Valid: true},
 				role:      sql.NullInt16{Int16: int16(auth.RoleAdmin), Valid: true},
 				status:    sql.NullInt16{Int16: int16(userModels.StatusActive), Valid: true},
@@ -54,49 +43,35 @@ func TestPostgresRepository_parseUser(t *testing.T) {
 				deleteAt:  sql.NullTime{Time: RandomTime, Valid: true},
 			},
 			want: userModels.User{
-				Id:       "1",
-				Username: "John Doe",
-				Email:    "john@doe",
-				AuthType: userModels.AuthTypeGoogle,
-				LoginHistory: []userModels.LoginHistory{
-					{
-						LoginTime:  RandomTime,
-						LogoutTime: RandomTime,
-					},
-				},
+				Id:        "1",
+				Username:  "John Doe",
+				Email:     "john@doe",
+				AuthType:  userModels.AuthTypeGoogle,
 				Role:      auth.RoleAdmin,
 				Status:    userModels.StatusActive,
 				CreatedAt: RandomTime,
 				UpdatedAt: RandomTime,
 				DeletedAt: RandomTime,
 			},
-			wantErr: false,
 		},
 		{
-			name: "Test parseUser with invalid login history",
+			name: "Test parseUser with null columns",
 			args: args{
-				id:           sql.NullString{String: "1", Valid: true},
-				username:     sql.NullString{String: "John Doe", Valid: true},
-				email:        sql.NullString{String: "john@doe", Valid: true},
-				authTye:      sql.NullInt16{Int16: int16(userModels.AuthTypeGoogle), Valid: true},
-				loginHistory: sql.NullString{String: "invalid", Valid: true},
-				role:         sql.NullInt16{Int16: 1, Valid: true},
-				status:       sql.NullInt16{Int16: 1, Valid: true},
-				createdAt:    sql.NullTime{Time: RandomTime, Valid: true},
-				updatedAt:    sql.NullTime{Time: RandomTime, Valid: true},
-				deleteAt:     sql.NullTime{Time: RandomTime, Valid: true},
+				id:       sql.NullString{String: "1", Valid: true},
+				username: sql.NullString{String: "John Doe", Valid: true},
+			},
+			want: userModels.User{
+				Id:       "1",
+				Username: "John Doe",
 			},
-			want:    userModels.User{},
-			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseUser(
+			got := parseUser(
 				tt.args.id,
 				tt.args.username,
 				tt.args.email,
-				tt.args.loginHistory,
 				tt.args.role,
 				tt.args.status,
 				tt.args.authTye,
@@ -104,13 +79,22 @@ func TestPostgresRepository_parseUser(t *testing.T) {
 				tt.args.updatedAt,
 				tt.args.deleteAt,
 			)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("parseUser() \nerror = %v\nwantErr %v", err, tt.wantErr)
-				return
-			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("parseUser() \ngot = %v\nwant %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestBuildDeleteQuery(t *testing.T) {
+	u := userModels.User{Id: "8c3c0a8e-2a55-4b52-9c34-1d0b7c1f6d7e", Status: userModels.StatusActive}
+	got, args := BuildDeleteQuery(u)
+	want := "UPDATE public.user SET deleted_at = NOW(), status = $1 WHERE id = $2 AND deleted_at IS NULL " +
+		"RETURNING deleted_at"
+	if got != want {
+		t.Errorf("BuildDeleteQuery() got = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{userModels.StatusInactive, u.Id}) {
+		t.Errorf("BuildDeleteQuery() args = %v, want %v", args, []interface{}{userModels.StatusInactive, u.Id})
+	}
+}
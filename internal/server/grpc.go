@@ -0,0 +1,55 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/raffops/chat_auth/internal/app/authz"
+	authzModel "github.com/raffops/chat_auth/internal/app/authz/model"
+	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	"github.com/raffops/chat_auth/pkg/proto"
+	"google.golang.org/grpc"
+)
+
+// grpcPermissions are the permissions required by the 'AuthService' methods on other users, on
+// top of the roles allowed by the method policy. They match the ones of the REST routes.
+var grpcPermissions = map[string]authzModel.Permission{
+	proto.AuthService_RevokeUserSessions_FullMethodName: authzModel.PermissionUpdateUser,
+	proto.AuthService_GetUser_FullMethodName:            authzModel.PermissionViewUser,
+	proto.AuthService_ListUsers_FullMethodName:          authzModel.PermissionViewUser,
+}
+
+// GrpcServer serves the 'AuthService' gRPC API on 'GRPC_PORT', alongside the HTTP server.
+type GrpcServer struct {
+	port   int
+	server *grpc.Server
+}
+
+func (s *GrpcServer) ListenAndServe() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return err
+	}
+	return s.server.Serve(listener)
+}
+
+// NewGrpcServer requires a session allowed by the gRPC method policy on every call, and the
+// permission of 'grpcPermissions' on the methods listed there.
+func NewGrpcServer(
+	authGrpcController proto.AuthServiceServer,
+	sessionMgr sessionManager.Service,
+	authzSrv authz.Service,
+) *GrpcServer {
+	port, _ := strconv.Atoi(os.Getenv("GRPC_PORT"))
+	for method, permission := range grpcPermissions {
+		authzSrv.SetPermission(method, permission)
+	}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(sessionMgr.CheckGrpcUnarySession, authzSrv.CheckGrpcUnaryPermission),
+		grpc.ChainStreamInterceptor(sessionMgr.CheckGrpcSession, authzSrv.CheckGrpcPermission),
+	)
+	proto.RegisterAuthServiceServer(server, authGrpcController)
+	return &GrpcServer{port: port, server: server}
+}
@@ -6,28 +6,118 @@ import (
 
 	"github.com/raffops/chat_auth/internal/app/auth"
 	authModel "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/authz"
+	authzModel "github.com/raffops/chat_auth/internal/app/authz/model"
+	"github.com/raffops/chat_auth/internal/app/mfa"
 	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	"github.com/raffops/chat_auth/internal/app/token"
+	"github.com/raffops/chat_auth/internal/app/user"
 	"github.com/raffops/chat_commons/pkg/logger"
 	"go.uber.org/zap"
 
 	"github.com/gorilla/mux"
 )
 
-func (s *Server) RegisterRoutes(authController auth.Controller, sessionMgr sessionManager.Service) http.Handler {
+func (s *Server) RegisterRoutes(
+	authController auth.Controller,
+	sessionMgr sessionManager.Service,
+	tokenController token.Controller,
+	mfaController mfa.Controller,
+	authzSrv authz.Service,
+	policyController authz.PolicyController,
+	userController user.Controller,
+	sessionController sessionManager.Controller,
+) http.Handler {
 	r := mux.NewRouter()
+	allRoles := []authModel.RoleId{authModel.RoleAdmin, authModel.RoleUser}
 
 	r.HandleFunc("/", s.HelloWorldHandler)
 	r.HandleFunc(
 		"/session_id",
-		sessionMgr.CheckRestSession(s.HelloWorldUser, []authModel.RoleId{authModel.RoleAdmin, authModel.RoleUser}),
+		sessionMgr.CheckRestSession(s.HelloWorldUser, allRoles),
 	)
 	r.HandleFunc("/health", s.healthHandler)
 
+	r.HandleFunc("/login/password", authController.LoginWithPassword).Methods("POST")
+	r.HandleFunc("/signUp/password", authController.SignUpWithPassword).Methods("POST")
+	r.HandleFunc("/login/mfa", authController.LoginWithMfa).Methods("POST")
 	r.HandleFunc("/login/{provider}", authController.Login)
 	r.HandleFunc("/login/{provider}/callback", authController.Callback)
-	r.HandleFunc("/signUp", authController.SignUp)
-	r.HandleFunc("/refresh", authController.Refresh)
-	r.HandleFunc("/user/{username}", authController.DeleteUser).Methods("DELETE")
+	r.HandleFunc("/signUp", authController.SignUp).Methods("POST")
+	r.HandleFunc("/refresh", authController.Refresh).Methods("POST")
+	r.HandleFunc(
+		"/user/{username}",
+		sessionMgr.CheckRestSessionWithMfa(authController.DeleteUser, allRoles),
+	).Methods("DELETE")
+
+	r.HandleFunc(
+		"/mfa/enroll",
+		authzSrv.RequirePermission(authzModel.PermissionManageSelf, mfaController.Enroll),
+	).Methods("POST")
+	r.HandleFunc(
+		"/mfa/enroll/verify",
+		authzSrv.RequirePermission(authzModel.PermissionManageSelf, mfaController.ConfirmEnrollment),
+	).Methods("POST")
+
+	r.HandleFunc(
+		"/policies",
+		authzSrv.RequirePermission(authzModel.PermissionManagePermission, policyController.ListPolicies),
+	).Methods("GET")
+	r.HandleFunc(
+		"/policies",
+		authzSrv.RequirePermission(authzModel.PermissionManagePermission, policyController.AddRule),
+	).Methods("POST")
+	r.HandleFunc(
+		"/policies",
+		authzSrv.RequirePermission(authzModel.PermissionManagePermission, policyController.RemoveRule),
+	).Methods("DELETE")
+
+	r.HandleFunc(
+		"/users",
+		authzSrv.RequirePermission(authzModel.PermissionViewUser, userController.ListUsers),
+	).Methods("GET")
+	r.HandleFunc(
+		"/users/{id}",
+		authzSrv.RequirePermission(authzModel.PermissionViewUser, userController.GetUser),
+	).Methods("GET")
+	r.HandleFunc(
+		"/users/{id}",
+		authzSrv.RequirePermission(authzModel.PermissionUpdateUser, userController.UpdateUser),
+	).Methods("PATCH")
+	r.HandleFunc(
+		"/users/{id}/logins",
+		authzSrv.RequirePermission(authzModel.PermissionViewUser, userController.ListLogins),
+	).Methods("GET")
+
+	r.HandleFunc(
+		"/users/{id}/sessions",
+		authzSrv.RequirePermission(authzModel.PermissionViewUser, sessionController.ListUserSessions),
+	).Methods("GET")
+	r.HandleFunc(
+		"/users/{id}/sessions/{sessionId}",
+		authzSrv.RequirePermission(authzModel.PermissionUpdateUser, sessionController.RevokeUserSession),
+	).Methods("DELETE")
+
+	r.HandleFunc("/sessions", sessionMgr.CheckRestSession(sessionController.ListSessions, allRoles)).Methods("GET")
+	r.HandleFunc(
+		"/sessions/{id}",
+		sessionMgr.CheckRestSession(sessionController.RevokeSession, allRoles),
+	).Methods("DELETE")
+
+	r.HandleFunc(
+		"/identities",
+		sessionMgr.CheckRestSession(authController.ListIdentities, allRoles),
+	).Methods("GET")
+	r.HandleFunc(
+		"/identities/{provider}",
+		sessionMgr.CheckRestSession(authController.LinkIdentity, allRoles),
+	).Methods("POST")
+	r.HandleFunc(
+		"/identities/{id}",
+		sessionMgr.CheckRestSession(authController.UnlinkIdentity, allRoles),
+	).Methods("DELETE")
+
+	r.HandleFunc("/.well-known/jwks.json", tokenController.JWKS).Methods("GET")
 	return r
 }
 
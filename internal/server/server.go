@@ -8,7 +8,11 @@ import (
 	"time"
 
 	"github.com/raffops/chat_auth/internal/app/auth"
+	"github.com/raffops/chat_auth/internal/app/authz"
+	"github.com/raffops/chat_auth/internal/app/mfa"
 	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	"github.com/raffops/chat_auth/internal/app/token"
+	"github.com/raffops/chat_auth/internal/app/user"
 
 	_ "github.com/joho/godotenv/autoload"
 
@@ -21,7 +25,16 @@ type Server struct {
 	db   database.Service
 }
 
-func NewServer(authController auth.Controller, sessionMgr sessionManager.Service) *http.Server {
+func NewServer(
+	authController auth.Controller,
+	sessionMgr sessionManager.Service,
+	tokenController token.Controller,
+	mfaController mfa.Controller,
+	authzSrv authz.Service,
+	policyController authz.PolicyController,
+	userController user.Controller,
+	sessionController sessionManager.Controller,
+) *http.Server {
 	port, _ := strconv.Atoi(os.Getenv("PORT"))
 	NewServer := &Server{
 		port: port,
@@ -29,7 +42,16 @@ func NewServer(authController auth.Controller, sessionMgr sessionManager.Service
 		db: database.New(),
 	}
 
-	handler := NewServer.RegisterRoutes(authController, sessionMgr)
+	handler := NewServer.RegisterRoutes(
+		authController,
+		sessionMgr,
+		tokenController,
+		mfaController,
+		authzSrv,
+		policyController,
+		userController,
+		sessionController,
+	)
 	loggedHandler := logger.LoggingMiddleware()(handler)
 
 	// Declare Server config
@@ -3,9 +3,9 @@
 package auth
 
 import (
-	http "net/http"
-
 	mock "github.com/stretchr/testify/mock"
+
+	http "net/http"
 )
 
 // Controller is an autogenerated mock type for the Controller type
@@ -89,6 +89,74 @@ func (_c *Controller_DeleteUser_Call) RunAndReturn(run func(http.ResponseWriter,
 	return _c
 }
 
+// LinkIdentity provides a mock function with given fields: w, r
+func (_m *Controller) LinkIdentity(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// Controller_LinkIdentity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LinkIdentity'
+type Controller_LinkIdentity_Call struct {
+	*mock.Call
+}
+
+// LinkIdentity is a helper method to define mock.On call
+//   - w http.ResponseWriter
+//   - r *http.Request
+func (_e *Controller_Expecter) LinkIdentity(w interface{}, r interface{}) *Controller_LinkIdentity_Call {
+	return &Controller_LinkIdentity_Call{Call: _e.mock.On("LinkIdentity", w, r)}
+}
+
+func (_c *Controller_LinkIdentity_Call) Run(run func(w http.ResponseWriter, r *http.Request)) *Controller_LinkIdentity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(http.ResponseWriter), args[1].(*http.Request))
+	})
+	return _c
+}
+
+func (_c *Controller_LinkIdentity_Call) Return() *Controller_LinkIdentity_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Controller_LinkIdentity_Call) RunAndReturn(run func(http.ResponseWriter, *http.Request)) *Controller_LinkIdentity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListIdentities provides a mock function with given fields: w, r
+func (_m *Controller) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// Controller_ListIdentities_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListIdentities'
+type Controller_ListIdentities_Call struct {
+	*mock.Call
+}
+
+// ListIdentities is a helper method to define mock.On call
+//   - w http.ResponseWriter
+//   - r *http.Request
+func (_e *Controller_Expecter) ListIdentities(w interface{}, r interface{}) *Controller_ListIdentities_Call {
+	return &Controller_ListIdentities_Call{Call: _e.mock.On("ListIdentities", w, r)}
+}
+
+func (_c *Controller_ListIdentities_Call) Run(run func(w http.ResponseWriter, r *http.Request)) *Controller_ListIdentities_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(http.ResponseWriter), args[1].(*http.Request))
+	})
+	return _c
+}
+
+func (_c *Controller_ListIdentities_Call) Return() *Controller_ListIdentities_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Controller_ListIdentities_Call) RunAndReturn(run func(http.ResponseWriter, *http.Request)) *Controller_ListIdentities_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Login provides a mock function with given fields: w, r
 func (_m *Controller) Login(w http.ResponseWriter, r *http.Request) {
 	_m.Called(w, r)
@@ -123,6 +191,74 @@ func (_c *Controller_Login_Call) RunAndReturn(run func(http.ResponseWriter, *htt
 	return _c
 }
 
+// LoginWithMfa provides a mock function with given fields: w, r
+func (_m *Controller) LoginWithMfa(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// Controller_LoginWithMfa_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoginWithMfa'
+type Controller_LoginWithMfa_Call struct {
+	*mock.Call
+}
+
+// LoginWithMfa is a helper method to define mock.On call
+//   - w http.ResponseWriter
+//   - r *http.Request
+func (_e *Controller_Expecter) LoginWithMfa(w interface{}, r interface{}) *Controller_LoginWithMfa_Call {
+	return &Controller_LoginWithMfa_Call{Call: _e.mock.On("LoginWithMfa", w, r)}
+}
+
+func (_c *Controller_LoginWithMfa_Call) Run(run func(w http.ResponseWriter, r *http.Request)) *Controller_LoginWithMfa_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(http.ResponseWriter), args[1].(*http.Request))
+	})
+	return _c
+}
+
+func (_c *Controller_LoginWithMfa_Call) Return() *Controller_LoginWithMfa_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Controller_LoginWithMfa_Call) RunAndReturn(run func(http.ResponseWriter, *http.Request)) *Controller_LoginWithMfa_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LoginWithPassword provides a mock function with given fields: w, r
+func (_m *Controller) LoginWithPassword(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// Controller_LoginWithPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoginWithPassword'
+type Controller_LoginWithPassword_Call struct {
+	*mock.Call
+}
+
+// LoginWithPassword is a helper method to define mock.On call
+//   - w http.ResponseWriter
+//   - r *http.Request
+func (_e *Controller_Expecter) LoginWithPassword(w interface{}, r interface{}) *Controller_LoginWithPassword_Call {
+	return &Controller_LoginWithPassword_Call{Call: _e.mock.On("LoginWithPassword", w, r)}
+}
+
+func (_c *Controller_LoginWithPassword_Call) Run(run func(w http.ResponseWriter, r *http.Request)) *Controller_LoginWithPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(http.ResponseWriter), args[1].(*http.Request))
+	})
+	return _c
+}
+
+func (_c *Controller_LoginWithPassword_Call) Return() *Controller_LoginWithPassword_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Controller_LoginWithPassword_Call) RunAndReturn(run func(http.ResponseWriter, *http.Request)) *Controller_LoginWithPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Logout provides a mock function with given fields: w, r
 func (_m *Controller) Logout(w http.ResponseWriter, r *http.Request) {
 	_m.Called(w, r)
@@ -225,6 +361,74 @@ func (_c *Controller_SignUp_Call) RunAndReturn(run func(http.ResponseWriter, *ht
 	return _c
 }
 
+// SignUpWithPassword provides a mock function with given fields: w, r
+func (_m *Controller) SignUpWithPassword(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// Controller_SignUpWithPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SignUpWithPassword'
+type Controller_SignUpWithPassword_Call struct {
+	*mock.Call
+}
+
+// SignUpWithPassword is a helper method to define mock.On call
+//   - w http.ResponseWriter
+//   - r *http.Request
+func (_e *Controller_Expecter) SignUpWithPassword(w interface{}, r interface{}) *Controller_SignUpWithPassword_Call {
+	return &Controller_SignUpWithPassword_Call{Call: _e.mock.On("SignUpWithPassword", w, r)}
+}
+
+func (_c *Controller_SignUpWithPassword_Call) Run(run func(w http.ResponseWriter, r *http.Request)) *Controller_SignUpWithPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(http.ResponseWriter), args[1].(*http.Request))
+	})
+	return _c
+}
+
+func (_c *Controller_SignUpWithPassword_Call) Return() *Controller_SignUpWithPassword_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Controller_SignUpWithPassword_Call) RunAndReturn(run func(http.ResponseWriter, *http.Request)) *Controller_SignUpWithPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnlinkIdentity provides a mock function with given fields: w, r
+func (_m *Controller) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// Controller_UnlinkIdentity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnlinkIdentity'
+type Controller_UnlinkIdentity_Call struct {
+	*mock.Call
+}
+
+// UnlinkIdentity is a helper method to define mock.On call
+//   - w http.ResponseWriter
+//   - r *http.Request
+func (_e *Controller_Expecter) UnlinkIdentity(w interface{}, r interface{}) *Controller_UnlinkIdentity_Call {
+	return &Controller_UnlinkIdentity_Call{Call: _e.mock.On("UnlinkIdentity", w, r)}
+}
+
+func (_c *Controller_UnlinkIdentity_Call) Run(run func(w http.ResponseWriter, r *http.Request)) *Controller_UnlinkIdentity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(http.ResponseWriter), args[1].(*http.Request))
+	})
+	return _c
+}
+
+func (_c *Controller_UnlinkIdentity_Call) Return() *Controller_UnlinkIdentity_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Controller_UnlinkIdentity_Call) RunAndReturn(run func(http.ResponseWriter, *http.Request)) *Controller_UnlinkIdentity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewController creates a new instance of Controller. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewController(t interface {
@@ -5,12 +5,15 @@ package auth
 import (
 	context "context"
 
-	errs "github.com/raffops/chat_commons/pkg/errs"
-	mock "github.com/stretchr/testify/mock"
-
 	model "github.com/raffops/chat_auth/internal/app/auth/model"
 
 	user "github.com/raffops/chat_auth/internal/app/user/models"
+
+	errs "github.com/raffops/chat_commons/pkg/errs"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // Service is an autogenerated mock type for the Service type
@@ -75,27 +78,148 @@ func (_c *Service_DeleteUser_Call) RunAndReturn(run func(context.Context, user.U
 	return _c
 }
 
-// Login provides a mock function with given fields: ctx, username, email
-func (_m *Service) Login(ctx context.Context, username string, email string) (string, errs.ChatError) {
-	ret := _m.Called(ctx, username, email)
+// LinkIdentity provides a mock function with given fields: ctx, userId, identity
+func (_m *Service) LinkIdentity(ctx context.Context, userId string, identity user.Identity) (user.Identity, errs.ChatError) {
+	ret := _m.Called(ctx, userId, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LinkIdentity")
+	}
+
+	var r0 user.Identity
+	var r1 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string, user.Identity) (user.Identity, errs.ChatError)); ok {
+		return rf(ctx, userId, identity)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, user.Identity) user.Identity); ok {
+		r0 = rf(ctx, userId, identity)
+	} else {
+		r0 = ret.Get(0).(user.Identity)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, user.Identity) errs.ChatError); ok {
+		r1 = rf(ctx, userId, identity)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(errs.ChatError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Service_LinkIdentity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LinkIdentity'
+type Service_LinkIdentity_Call struct {
+	*mock.Call
+}
+
+// LinkIdentity is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+//   - identity user.Identity
+func (_e *Service_Expecter) LinkIdentity(ctx interface{}, userId interface{}, identity interface{}) *Service_LinkIdentity_Call {
+	return &Service_LinkIdentity_Call{Call: _e.mock.On("LinkIdentity", ctx, userId, identity)}
+}
+
+func (_c *Service_LinkIdentity_Call) Run(run func(ctx context.Context, userId string, identity user.Identity)) *Service_LinkIdentity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(user.Identity))
+	})
+	return _c
+}
+
+func (_c *Service_LinkIdentity_Call) Return(_a0 user.Identity, _a1 errs.ChatError) *Service_LinkIdentity_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Service_LinkIdentity_Call) RunAndReturn(run func(context.Context, string, user.Identity) (user.Identity, errs.ChatError)) *Service_LinkIdentity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListIdentities provides a mock function with given fields: ctx, userId
+func (_m *Service) ListIdentities(ctx context.Context, userId string) ([]user.Identity, errs.ChatError) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListIdentities")
+	}
+
+	var r0 []user.Identity
+	var r1 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]user.Identity, errs.ChatError)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []user.Identity); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]user.Identity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) errs.ChatError); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(errs.ChatError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Service_ListIdentities_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListIdentities'
+type Service_ListIdentities_Call struct {
+	*mock.Call
+}
+
+// ListIdentities is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+func (_e *Service_Expecter) ListIdentities(ctx interface{}, userId interface{}) *Service_ListIdentities_Call {
+	return &Service_ListIdentities_Call{Call: _e.mock.On("ListIdentities", ctx, userId)}
+}
+
+func (_c *Service_ListIdentities_Call) Run(run func(ctx context.Context, userId string)) *Service_ListIdentities_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Service_ListIdentities_Call) Return(_a0 []user.Identity, _a1 errs.ChatError) *Service_ListIdentities_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Service_ListIdentities_Call) RunAndReturn(run func(context.Context, string) ([]user.Identity, errs.ChatError)) *Service_ListIdentities_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Login provides a mock function with given fields: ctx, identity
+func (_m *Service) Login(ctx context.Context, identity user.Identity) (model.Token, errs.ChatError) {
+	ret := _m.Called(ctx, identity)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Login")
 	}
 
-	var r0 string
+	var r0 model.Token
 	var r1 errs.ChatError
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, errs.ChatError)); ok {
-		return rf(ctx, username, email)
+	if rf, ok := ret.Get(0).(func(context.Context, user.Identity) (model.Token, errs.ChatError)); ok {
+		return rf(ctx, identity)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
-		r0 = rf(ctx, username, email)
+	if rf, ok := ret.Get(0).(func(context.Context, user.Identity) model.Token); ok {
+		r0 = rf(ctx, identity)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(model.Token)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) errs.ChatError); ok {
-		r1 = rf(ctx, username, email)
+	if rf, ok := ret.Get(1).(func(context.Context, user.Identity) errs.ChatError); ok {
+		r1 = rf(ctx, identity)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(errs.ChatError)
@@ -112,25 +236,144 @@ type Service_Login_Call struct {
 
 // Login is a helper method to define mock.On call
 //   - ctx context.Context
+//   - identity user.Identity
+func (_e *Service_Expecter) Login(ctx interface{}, identity interface{}) *Service_Login_Call {
+	return &Service_Login_Call{Call: _e.mock.On("Login", ctx, identity)}
+}
+
+func (_c *Service_Login_Call) Run(run func(ctx context.Context, identity user.Identity)) *Service_Login_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(user.Identity))
+	})
+	return _c
+}
+
+func (_c *Service_Login_Call) Return(_a0 model.Token, _a1 errs.ChatError) *Service_Login_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Service_Login_Call) RunAndReturn(run func(context.Context, user.Identity) (model.Token, errs.ChatError)) *Service_Login_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LoginWithMfa provides a mock function with given fields: ctx, challenge, code
+func (_m *Service) LoginWithMfa(ctx context.Context, challenge string, code string) (model.Token, errs.ChatError) {
+	ret := _m.Called(ctx, challenge, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoginWithMfa")
+	}
+
+	var r0 model.Token
+	var r1 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (model.Token, errs.ChatError)); ok {
+		return rf(ctx, challenge, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) model.Token); ok {
+		r0 = rf(ctx, challenge, code)
+	} else {
+		r0 = ret.Get(0).(model.Token)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) errs.ChatError); ok {
+		r1 = rf(ctx, challenge, code)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(errs.ChatError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Service_LoginWithMfa_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoginWithMfa'
+type Service_LoginWithMfa_Call struct {
+	*mock.Call
+}
+
+// LoginWithMfa is a helper method to define mock.On call
+//   - ctx context.Context
+//   - challenge string
+//   - code string
+func (_e *Service_Expecter) LoginWithMfa(ctx interface{}, challenge interface{}, code interface{}) *Service_LoginWithMfa_Call {
+	return &Service_LoginWithMfa_Call{Call: _e.mock.On("LoginWithMfa", ctx, challenge, code)}
+}
+
+func (_c *Service_LoginWithMfa_Call) Run(run func(ctx context.Context, challenge string, code string)) *Service_LoginWithMfa_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Service_LoginWithMfa_Call) Return(_a0 model.Token, _a1 errs.ChatError) *Service_LoginWithMfa_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Service_LoginWithMfa_Call) RunAndReturn(run func(context.Context, string, string) (model.Token, errs.ChatError)) *Service_LoginWithMfa_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LoginWithPassword provides a mock function with given fields: ctx, username, password
+func (_m *Service) LoginWithPassword(ctx context.Context, username string, password string) (model.Token, errs.ChatError) {
+	ret := _m.Called(ctx, username, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoginWithPassword")
+	}
+
+	var r0 model.Token
+	var r1 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (model.Token, errs.ChatError)); ok {
+		return rf(ctx, username, password)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) model.Token); ok {
+		r0 = rf(ctx, username, password)
+	} else {
+		r0 = ret.Get(0).(model.Token)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) errs.ChatError); ok {
+		r1 = rf(ctx, username, password)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(errs.ChatError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Service_LoginWithPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoginWithPassword'
+type Service_LoginWithPassword_Call struct {
+	*mock.Call
+}
+
+// LoginWithPassword is a helper method to define mock.On call
+//   - ctx context.Context
 //   - username string
-//   - email string
-func (_e *Service_Expecter) Login(ctx interface{}, username interface{}, email interface{}) *Service_Login_Call {
-	return &Service_Login_Call{Call: _e.mock.On("Login", ctx, username, email)}
+//   - password string
+func (_e *Service_Expecter) LoginWithPassword(ctx interface{}, username interface{}, password interface{}) *Service_LoginWithPassword_Call {
+	return &Service_LoginWithPassword_Call{Call: _e.mock.On("LoginWithPassword", ctx, username, password)}
 }
 
-func (_c *Service_Login_Call) Run(run func(ctx context.Context, username string, email string)) *Service_Login_Call {
+func (_c *Service_LoginWithPassword_Call) Run(run func(ctx context.Context, username string, password string)) *Service_LoginWithPassword_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *Service_Login_Call) Return(_a0 string, _a1 errs.ChatError) *Service_Login_Call {
+func (_c *Service_LoginWithPassword_Call) Return(_a0 model.Token, _a1 errs.ChatError) *Service_LoginWithPassword_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Service_Login_Call) RunAndReturn(run func(context.Context, string, string) (string, errs.ChatError)) *Service_Login_Call {
+func (_c *Service_LoginWithPassword_Call) RunAndReturn(run func(context.Context, string, string) (model.Token, errs.ChatError)) *Service_LoginWithPassword_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -184,24 +427,34 @@ func (_c *Service_Logout_Call) RunAndReturn(run func(context.Context, string) er
 	return _c
 }
 
-// Refresh provides a mock function with given fields: ctx, sessionId
-func (_m *Service) Refresh(ctx context.Context, sessionId string) errs.ChatError {
-	ret := _m.Called(ctx, sessionId)
+// Refresh provides a mock function with given fields: ctx, refreshToken
+func (_m *Service) Refresh(ctx context.Context, refreshToken string) (model.Token, errs.ChatError) {
+	ret := _m.Called(ctx, refreshToken)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Refresh")
 	}
 
-	var r0 errs.ChatError
-	if rf, ok := ret.Get(0).(func(context.Context, string) errs.ChatError); ok {
-		r0 = rf(ctx, sessionId)
+	var r0 model.Token
+	var r1 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string) (model.Token, errs.ChatError)); ok {
+		return rf(ctx, refreshToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.Token); ok {
+		r0 = rf(ctx, refreshToken)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(errs.ChatError)
+		r0 = ret.Get(0).(model.Token)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) errs.ChatError); ok {
+		r1 = rf(ctx, refreshToken)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(errs.ChatError)
 		}
 	}
 
-	return r0
+	return r0, r1
 }
 
 // Service_Refresh_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Refresh'
@@ -211,49 +464,49 @@ type Service_Refresh_Call struct {
 
 // Refresh is a helper method to define mock.On call
 //   - ctx context.Context
-//   - sessionId string
-func (_e *Service_Expecter) Refresh(ctx interface{}, sessionId interface{}) *Service_Refresh_Call {
-	return &Service_Refresh_Call{Call: _e.mock.On("Refresh", ctx, sessionId)}
+//   - refreshToken string
+func (_e *Service_Expecter) Refresh(ctx interface{}, refreshToken interface{}) *Service_Refresh_Call {
+	return &Service_Refresh_Call{Call: _e.mock.On("Refresh", ctx, refreshToken)}
 }
 
-func (_c *Service_Refresh_Call) Run(run func(ctx context.Context, sessionId string)) *Service_Refresh_Call {
+func (_c *Service_Refresh_Call) Run(run func(ctx context.Context, refreshToken string)) *Service_Refresh_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *Service_Refresh_Call) Return(_a0 errs.ChatError) *Service_Refresh_Call {
-	_c.Call.Return(_a0)
+func (_c *Service_Refresh_Call) Return(_a0 model.Token, _a1 errs.ChatError) *Service_Refresh_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Service_Refresh_Call) RunAndReturn(run func(context.Context, string) errs.ChatError) *Service_Refresh_Call {
+func (_c *Service_Refresh_Call) RunAndReturn(run func(context.Context, string) (model.Token, errs.ChatError)) *Service_Refresh_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SignUp provides a mock function with given fields: ctx, username, email, authType, role
-func (_m *Service) SignUp(ctx context.Context, username string, email string, authType user.AuthTypeId, role model.RoleId) (string, errs.ChatError) {
-	ret := _m.Called(ctx, username, email, authType, role)
+// SignUp provides a mock function with given fields: ctx, username, identity, role
+func (_m *Service) SignUp(ctx context.Context, username string, identity user.Identity, role model.RoleId) (model.Token, errs.ChatError) {
+	ret := _m.Called(ctx, username, identity, role)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SignUp")
 	}
 
-	var r0 string
+	var r0 model.Token
 	var r1 errs.ChatError
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, user.AuthTypeId, model.RoleId) (string, errs.ChatError)); ok {
-		return rf(ctx, username, email, authType, role)
+	if rf, ok := ret.Get(0).(func(context.Context, string, user.Identity, model.RoleId) (model.Token, errs.ChatError)); ok {
+		return rf(ctx, username, identity, role)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, user.AuthTypeId, model.RoleId) string); ok {
-		r0 = rf(ctx, username, email, authType, role)
+	if rf, ok := ret.Get(0).(func(context.Context, string, user.Identity, model.RoleId) model.Token); ok {
+		r0 = rf(ctx, username, identity, role)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(model.Token)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string, user.AuthTypeId, model.RoleId) errs.ChatError); ok {
-		r1 = rf(ctx, username, email, authType, role)
+	if rf, ok := ret.Get(1).(func(context.Context, string, user.Identity, model.RoleId) errs.ChatError); ok {
+		r1 = rf(ctx, username, identity, role)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(errs.ChatError)
@@ -271,26 +524,170 @@ type Service_SignUp_Call struct {
 // SignUp is a helper method to define mock.On call
 //   - ctx context.Context
 //   - username string
-//   - email string
-//   - authType user.AuthTypeId
+//   - identity user.Identity
 //   - role model.RoleId
-func (_e *Service_Expecter) SignUp(ctx interface{}, username interface{}, email interface{}, authType interface{}, role interface{}) *Service_SignUp_Call {
-	return &Service_SignUp_Call{Call: _e.mock.On("SignUp", ctx, username, email, authType, role)}
+func (_e *Service_Expecter) SignUp(ctx interface{}, username interface{}, identity interface{}, role interface{}) *Service_SignUp_Call {
+	return &Service_SignUp_Call{Call: _e.mock.On("SignUp", ctx, username, identity, role)}
 }
 
-func (_c *Service_SignUp_Call) Run(run func(ctx context.Context, username string, email string, authType user.AuthTypeId, role model.RoleId)) *Service_SignUp_Call {
+func (_c *Service_SignUp_Call) Run(run func(ctx context.Context, username string, identity user.Identity, role model.RoleId)) *Service_SignUp_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(user.AuthTypeId), args[4].(model.RoleId))
+		run(args[0].(context.Context), args[1].(string), args[2].(user.Identity), args[3].(model.RoleId))
 	})
 	return _c
 }
 
-func (_c *Service_SignUp_Call) Return(_a0 string, _a1 errs.ChatError) *Service_SignUp_Call {
+func (_c *Service_SignUp_Call) Return(_a0 model.Token, _a1 errs.ChatError) *Service_SignUp_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Service_SignUp_Call) RunAndReturn(run func(context.Context, string, string, user.AuthTypeId, model.RoleId) (string, errs.ChatError)) *Service_SignUp_Call {
+func (_c *Service_SignUp_Call) RunAndReturn(run func(context.Context, string, user.Identity, model.RoleId) (model.Token, errs.ChatError)) *Service_SignUp_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SignUpWithPassword provides a mock function with given fields: ctx, username, email, password
+func (_m *Service) SignUpWithPassword(ctx context.Context, username string, email string, password string) (model.Token, errs.ChatError) {
+	ret := _m.Called(ctx, username, email, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SignUpWithPassword")
+	}
+
+	var r0 model.Token
+	var r1 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (model.Token, errs.ChatError)); ok {
+		return rf(ctx, username, email, password)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) model.Token); ok {
+		r0 = rf(ctx, username, email, password)
+	} else {
+		r0 = ret.Get(0).(model.Token)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) errs.ChatError); ok {
+		r1 = rf(ctx, username, email, password)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(errs.ChatError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Service_SignUpWithPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SignUpWithPassword'
+type Service_SignUpWithPassword_Call struct {
+	*mock.Call
+}
+
+// SignUpWithPassword is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+//   - email string
+//   - password string
+func (_e *Service_Expecter) SignUpWithPassword(ctx interface{}, username interface{}, email interface{}, password interface{}) *Service_SignUpWithPassword_Call {
+	return &Service_SignUpWithPassword_Call{Call: _e.mock.On("SignUpWithPassword", ctx, username, email, password)}
+}
+
+func (_c *Service_SignUpWithPassword_Call) Run(run func(ctx context.Context, username string, email string, password string)) *Service_SignUpWithPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *Service_SignUpWithPassword_Call) Return(_a0 model.Token, _a1 errs.ChatError) *Service_SignUpWithPassword_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Service_SignUpWithPassword_Call) RunAndReturn(run func(context.Context, string, string, string) (model.Token, errs.ChatError)) *Service_SignUpWithPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StartClosingLogins provides a mock function with given fields: ctx, interval
+func (_m *Service) StartClosingLogins(ctx context.Context, interval time.Duration) {
+	_m.Called(ctx, interval)
+}
+
+// Service_StartClosingLogins_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartClosingLogins'
+type Service_StartClosingLogins_Call struct {
+	*mock.Call
+}
+
+// StartClosingLogins is a helper method to define mock.On call
+//   - ctx context.Context
+//   - interval time.Duration
+func (_e *Service_Expecter) StartClosingLogins(ctx interface{}, interval interface{}) *Service_StartClosingLogins_Call {
+	return &Service_StartClosingLogins_Call{Call: _e.mock.On("StartClosingLogins", ctx, interval)}
+}
+
+func (_c *Service_StartClosingLogins_Call) Run(run func(ctx context.Context, interval time.Duration)) *Service_StartClosingLogins_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *Service_StartClosingLogins_Call) Return() *Service_StartClosingLogins_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Service_StartClosingLogins_Call) RunAndReturn(run func(context.Context, time.Duration)) *Service_StartClosingLogins_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnlinkIdentity provides a mock function with given fields: ctx, userId, identityId
+func (_m *Service) UnlinkIdentity(ctx context.Context, userId string, identityId int64) errs.ChatError {
+	ret := _m.Called(ctx, userId, identityId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnlinkIdentity")
+	}
+
+	var r0 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) errs.ChatError); ok {
+		r0 = rf(ctx, userId, identityId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(errs.ChatError)
+		}
+	}
+
+	return r0
+}
+
+// Service_UnlinkIdentity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnlinkIdentity'
+type Service_UnlinkIdentity_Call struct {
+	*mock.Call
+}
+
+// UnlinkIdentity is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+//   - identityId int64
+func (_e *Service_Expecter) UnlinkIdentity(ctx interface{}, userId interface{}, identityId interface{}) *Service_UnlinkIdentity_Call {
+	return &Service_UnlinkIdentity_Call{Call: _e.mock.On("UnlinkIdentity", ctx, userId, identityId)}
+}
+
+func (_c *Service_UnlinkIdentity_Call) Run(run func(ctx context.Context, userId string, identityId int64)) *Service_UnlinkIdentity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *Service_UnlinkIdentity_Call) Return(_a0 errs.ChatError) *Service_UnlinkIdentity_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Service_UnlinkIdentity_Call) RunAndReturn(run func(context.Context, string, int64) errs.ChatError) *Service_UnlinkIdentity_Call {
 	_c.Call.Return(run)
 	return _c
 }
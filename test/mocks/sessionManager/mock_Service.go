@@ -7,13 +7,15 @@ import (
 
 	auth "github.com/raffops/chat_auth/internal/app/auth/model"
 
+	sessionManager "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+
 	errs "github.com/raffops/chat_commons/pkg/errs"
 
+	mock "github.com/stretchr/testify/mock"
+
 	grpc "google.golang.org/grpc"
 
 	http "net/http"
-
-	mock "github.com/stretchr/testify/mock"
 )
 
 // Service is an autogenerated mock type for the Service type
@@ -78,6 +80,67 @@ func (_c *Service_CheckGrpcSession_Call) RunAndReturn(run func(interface{}, grpc
 	return _c
 }
 
+// CheckGrpcUnarySession provides a mock function with given fields: ctx, req, info, handler
+func (_m *Service) CheckGrpcUnarySession(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ret := _m.Called(ctx, req, info, handler)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckGrpcUnarySession")
+	}
+
+	var r0 interface{}
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) (interface{}, error)); ok {
+		return rf(ctx, req, info, handler)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) interface{}); ok {
+		r0 = rf(ctx, req, info, handler)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interface{})
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) error); ok {
+		r1 = rf(ctx, req, info, handler)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Service_CheckGrpcUnarySession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckGrpcUnarySession'
+type Service_CheckGrpcUnarySession_Call struct {
+	*mock.Call
+}
+
+// CheckGrpcUnarySession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req interface{}
+//   - info *grpc.UnaryServerInfo
+//   - handler grpc.UnaryHandler
+func (_e *Service_Expecter) CheckGrpcUnarySession(ctx interface{}, req interface{}, info interface{}, handler interface{}) *Service_CheckGrpcUnarySession_Call {
+	return &Service_CheckGrpcUnarySession_Call{Call: _e.mock.On("CheckGrpcUnarySession", ctx, req, info, handler)}
+}
+
+func (_c *Service_CheckGrpcUnarySession_Call) Run(run func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler)) *Service_CheckGrpcUnarySession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(interface{}), args[2].(*grpc.UnaryServerInfo), args[3].(grpc.UnaryHandler))
+	})
+	return _c
+}
+
+func (_c *Service_CheckGrpcUnarySession_Call) Return(_a0 interface{}, _a1 error) *Service_CheckGrpcUnarySession_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Service_CheckGrpcUnarySession_Call) RunAndReturn(run func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) (interface{}, error)) *Service_CheckGrpcUnarySession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CheckRestSession provides a mock function with given fields: next, roles
 func (_m *Service) CheckRestSession(next http.HandlerFunc, roles []auth.RoleId) http.HandlerFunc {
 	ret := _m.Called(next, roles)
@@ -127,8 +190,117 @@ func (_c *Service_CheckRestSession_Call) RunAndReturn(run func(http.HandlerFunc,
 	return _c
 }
 
+// CheckRestSessionWithMfa provides a mock function with given fields: next, roles
+func (_m *Service) CheckRestSessionWithMfa(next http.HandlerFunc, roles []auth.RoleId) http.HandlerFunc {
+	ret := _m.Called(next, roles)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckRestSessionWithMfa")
+	}
+
+	var r0 http.HandlerFunc
+	if rf, ok := ret.Get(0).(func(http.HandlerFunc, []auth.RoleId) http.HandlerFunc); ok {
+		r0 = rf(next, roles)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// Service_CheckRestSessionWithMfa_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckRestSessionWithMfa'
+type Service_CheckRestSessionWithMfa_Call struct {
+	*mock.Call
+}
+
+// CheckRestSessionWithMfa is a helper method to define mock.On call
+//   - next http.HandlerFunc
+//   - roles []auth.RoleId
+func (_e *Service_Expecter) CheckRestSessionWithMfa(next interface{}, roles interface{}) *Service_CheckRestSessionWithMfa_Call {
+	return &Service_CheckRestSessionWithMfa_Call{Call: _e.mock.On("CheckRestSessionWithMfa", next, roles)}
+}
+
+func (_c *Service_CheckRestSessionWithMfa_Call) Run(run func(next http.HandlerFunc, roles []auth.RoleId)) *Service_CheckRestSessionWithMfa_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(http.HandlerFunc), args[1].([]auth.RoleId))
+	})
+	return _c
+}
+
+func (_c *Service_CheckRestSessionWithMfa_Call) Return(_a0 http.HandlerFunc) *Service_CheckRestSessionWithMfa_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Service_CheckRestSessionWithMfa_Call) RunAndReturn(run func(http.HandlerFunc, []auth.RoleId) http.HandlerFunc) *Service_CheckRestSessionWithMfa_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateRefreshToken provides a mock function with given fields: ctx, userId, sessionId
+func (_m *Service) CreateRefreshToken(ctx context.Context, userId string, sessionId string) (string, errs.ChatError) {
+	ret := _m.Called(ctx, userId, sessionId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRefreshToken")
+	}
+
+	var r0 string
+	var r1 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, errs.ChatError)); ok {
+		return rf(ctx, userId, sessionId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(ctx, userId, sessionId)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) errs.ChatError); ok {
+		r1 = rf(ctx, userId, sessionId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(errs.ChatError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Service_CreateRefreshToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateRefreshToken'
+type Service_CreateRefreshToken_Call struct {
+	*mock.Call
+}
+
+// CreateRefreshToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+//   - sessionId string
+func (_e *Service_Expecter) CreateRefreshToken(ctx interface{}, userId interface{}, sessionId interface{}) *Service_CreateRefreshToken_Call {
+	return &Service_CreateRefreshToken_Call{Call: _e.mock.On("CreateRefreshToken", ctx, userId, sessionId)}
+}
+
+func (_c *Service_CreateRefreshToken_Call) Run(run func(ctx context.Context, userId string, sessionId string)) *Service_CreateRefreshToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Service_CreateRefreshToken_Call) Return(_a0 string, _a1 errs.ChatError) *Service_CreateRefreshToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Service_CreateRefreshToken_Call) RunAndReturn(run func(context.Context, string, string) (string, errs.ChatError)) *Service_CreateRefreshToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CreateSession provides a mock function with given fields: ctx, userId, payload
-func (_m *Service) CreateSession(ctx context.Context, userId string, payload map[string]interface{}) (string, errs.ChatError) {
+func (_m *Service) CreateSession(ctx context.Context, userId string, payload map[string]interface{}) (string, []string, errs.ChatError) {
 	ret := _m.Called(ctx, userId, payload)
 
 	if len(ret) == 0 {
@@ -136,8 +308,9 @@ func (_m *Service) CreateSession(ctx context.Context, userId string, payload map
 	}
 
 	var r0 string
-	var r1 errs.ChatError
-	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]interface{}) (string, errs.ChatError)); ok {
+	var r1 []string
+	var r2 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]interface{}) (string, []string, errs.ChatError)); ok {
 		return rf(ctx, userId, payload)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]interface{}) string); ok {
@@ -146,15 +319,23 @@ func (_m *Service) CreateSession(ctx context.Context, userId string, payload map
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, map[string]interface{}) errs.ChatError); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, string, map[string]interface{}) []string); ok {
 		r1 = rf(ctx, userId, payload)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(errs.ChatError)
+			r1 = ret.Get(1).([]string)
 		}
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(context.Context, string, map[string]interface{}) errs.ChatError); ok {
+		r2 = rf(ctx, userId, payload)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(errs.ChatError)
+		}
+	}
+
+	return r0, r1, r2
 }
 
 // Service_CreateSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateSession'
@@ -177,12 +358,12 @@ func (_c *Service_CreateSession_Call) Run(run func(ctx context.Context, userId s
 	return _c
 }
 
-func (_c *Service_CreateSession_Call) Return(_a0 string, _a1 errs.ChatError) *Service_CreateSession_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *Service_CreateSession_Call) Return(_a0 string, _a1 []string, _a2 errs.ChatError) *Service_CreateSession_Call {
+	_c.Call.Return(_a0, _a1, _a2)
 	return _c
 }
 
-func (_c *Service_CreateSession_Call) RunAndReturn(run func(context.Context, string, map[string]interface{}) (string, errs.ChatError)) *Service_CreateSession_Call {
+func (_c *Service_CreateSession_Call) RunAndReturn(run func(context.Context, string, map[string]interface{}) (string, []string, errs.ChatError)) *Service_CreateSession_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -236,6 +417,56 @@ func (_c *Service_FinishSession_Call) RunAndReturn(run func(context.Context, str
 	return _c
 }
 
+// FinishUserSession provides a mock function with given fields: ctx, userId, handle
+func (_m *Service) FinishUserSession(ctx context.Context, userId string, handle string) errs.ChatError {
+	ret := _m.Called(ctx, userId, handle)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FinishUserSession")
+	}
+
+	var r0 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) errs.ChatError); ok {
+		r0 = rf(ctx, userId, handle)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(errs.ChatError)
+		}
+	}
+
+	return r0
+}
+
+// Service_FinishUserSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FinishUserSession'
+type Service_FinishUserSession_Call struct {
+	*mock.Call
+}
+
+// FinishUserSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+//   - handle string
+func (_e *Service_Expecter) FinishUserSession(ctx interface{}, userId interface{}, handle interface{}) *Service_FinishUserSession_Call {
+	return &Service_FinishUserSession_Call{Call: _e.mock.On("FinishUserSession", ctx, userId, handle)}
+}
+
+func (_c *Service_FinishUserSession_Call) Run(run func(ctx context.Context, userId string, handle string)) *Service_FinishUserSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Service_FinishUserSession_Call) Return(_a0 errs.ChatError) *Service_FinishUserSession_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Service_FinishUserSession_Call) RunAndReturn(run func(context.Context, string, string) errs.ChatError) *Service_FinishUserSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // FinishUserSessions provides a mock function with given fields: ctx, userId
 func (_m *Service) FinishUserSessions(ctx context.Context, userId string) errs.ChatError {
 	ret := _m.Called(ctx, userId)
@@ -407,6 +638,67 @@ func (_c *Service_GetSession_Call) RunAndReturn(run func(context.Context, string
 	return _c
 }
 
+// ListUserSessions provides a mock function with given fields: ctx, userId
+func (_m *Service) ListUserSessions(ctx context.Context, userId string) ([]sessionManager.Session, errs.ChatError) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUserSessions")
+	}
+
+	var r0 []sessionManager.Session
+	var r1 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]sessionManager.Session, errs.ChatError)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []sessionManager.Session); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]sessionManager.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) errs.ChatError); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(errs.ChatError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Service_ListUserSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUserSessions'
+type Service_ListUserSessions_Call struct {
+	*mock.Call
+}
+
+// ListUserSessions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+func (_e *Service_Expecter) ListUserSessions(ctx interface{}, userId interface{}) *Service_ListUserSessions_Call {
+	return &Service_ListUserSessions_Call{Call: _e.mock.On("ListUserSessions", ctx, userId)}
+}
+
+func (_c *Service_ListUserSessions_Call) Run(run func(ctx context.Context, userId string)) *Service_ListUserSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Service_ListUserSessions_Call) Return(_a0 []sessionManager.Session, _a1 errs.ChatError) *Service_ListUserSessions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Service_ListUserSessions_Call) RunAndReturn(run func(context.Context, string) ([]sessionManager.Session, errs.ChatError)) *Service_ListUserSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RefreshSession provides a mock function with given fields: ctx, sessionId
 func (_m *Service) RefreshSession(ctx context.Context, sessionId string) errs.ChatError {
 	ret := _m.Called(ctx, sessionId)
@@ -456,9 +748,90 @@ func (_c *Service_RefreshSession_Call) RunAndReturn(run func(context.Context, st
 	return _c
 }
 
-// SetRoles provides a mock function with given fields: method, roles
-func (_m *Service) SetRoles(method string, roles []auth.RoleId) {
-	_m.Called(method, roles)
+// RotateRefreshToken provides a mock function with given fields: ctx, refreshToken
+func (_m *Service) RotateRefreshToken(ctx context.Context, refreshToken string) (string, string, errs.ChatError) {
+	ret := _m.Called(ctx, refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateRefreshToken")
+	}
+
+	var r0 string
+	var r1 string
+	var r2 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, string, errs.ChatError)); ok {
+		return rf(ctx, refreshToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, refreshToken)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) string); ok {
+		r1 = rf(ctx, refreshToken)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) errs.ChatError); ok {
+		r2 = rf(ctx, refreshToken)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(errs.ChatError)
+		}
+	}
+
+	return r0, r1, r2
+}
+
+// Service_RotateRefreshToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RotateRefreshToken'
+type Service_RotateRefreshToken_Call struct {
+	*mock.Call
+}
+
+// RotateRefreshToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - refreshToken string
+func (_e *Service_Expecter) RotateRefreshToken(ctx interface{}, refreshToken interface{}) *Service_RotateRefreshToken_Call {
+	return &Service_RotateRefreshToken_Call{Call: _e.mock.On("RotateRefreshToken", ctx, refreshToken)}
+}
+
+func (_c *Service_RotateRefreshToken_Call) Run(run func(ctx context.Context, refreshToken string)) *Service_RotateRefreshToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Service_RotateRefreshToken_Call) Return(_a0 string, _a1 string, _a2 errs.ChatError) *Service_RotateRefreshToken_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Service_RotateRefreshToken_Call) RunAndReturn(run func(context.Context, string) (string, string, errs.ChatError)) *Service_RotateRefreshToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetRoles provides a mock function with given fields: ctx, method, roles
+func (_m *Service) SetRoles(ctx context.Context, method string, roles []auth.RoleId) errs.ChatError {
+	ret := _m.Called(ctx, method, roles)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRoles")
+	}
+
+	var r0 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string, []auth.RoleId) errs.ChatError); ok {
+		r0 = rf(ctx, method, roles)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(errs.ChatError)
+		}
+	}
+
+	return r0
 }
 
 // Service_SetRoles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetRoles'
@@ -467,25 +840,26 @@ type Service_SetRoles_Call struct {
 }
 
 // SetRoles is a helper method to define mock.On call
+//   - ctx context.Context
 //   - method string
 //   - roles []auth.RoleId
-func (_e *Service_Expecter) SetRoles(method interface{}, roles interface{}) *Service_SetRoles_Call {
-	return &Service_SetRoles_Call{Call: _e.mock.On("SetRoles", method, roles)}
+func (_e *Service_Expecter) SetRoles(ctx interface{}, method interface{}, roles interface{}) *Service_SetRoles_Call {
+	return &Service_SetRoles_Call{Call: _e.mock.On("SetRoles", ctx, method, roles)}
 }
 
-func (_c *Service_SetRoles_Call) Run(run func(method string, roles []auth.RoleId)) *Service_SetRoles_Call {
+func (_c *Service_SetRoles_Call) Run(run func(ctx context.Context, method string, roles []auth.RoleId)) *Service_SetRoles_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].([]auth.RoleId))
+		run(args[0].(context.Context), args[1].(string), args[2].([]auth.RoleId))
 	})
 	return _c
 }
 
-func (_c *Service_SetRoles_Call) Return() *Service_SetRoles_Call {
-	_c.Call.Return()
+func (_c *Service_SetRoles_Call) Return(_a0 errs.ChatError) *Service_SetRoles_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Service_SetRoles_Call) RunAndReturn(run func(string, []auth.RoleId)) *Service_SetRoles_Call {
+func (_c *Service_SetRoles_Call) RunAndReturn(run func(context.Context, string, []auth.RoleId) errs.ChatError) *Service_SetRoles_Call {
 	_c.Call.Return(run)
 	return _c
 }
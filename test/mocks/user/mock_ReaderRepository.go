@@ -5,10 +5,11 @@ package user
 import (
 	context "context"
 
+	user "github.com/raffops/chat_auth/internal/app/user/models"
+
 	errs "github.com/raffops/chat_commons/pkg/errs"
-	mock "github.com/stretchr/testify/mock"
 
-	user "github.com/raffops/chat_auth/internal/app/user/models"
+	mock "github.com/stretchr/testify/mock"
 )
 
 // ReaderRepository is an autogenerated mock type for the ReaderRepository type
@@ -24,9 +25,128 @@ func (_m *ReaderRepository) EXPECT() *ReaderRepository_Expecter {
 	return &ReaderRepository_Expecter{mock: &_m.Mock}
 }
 
-// GetUser provides a mock function with given fields: ctx, key, value
-func (_m *ReaderRepository) GetUser(ctx context.Context, key string, value interface{}) (user.User, errs.ChatError) {
-	ret := _m.Called(ctx, key, value)
+// CountUsers provides a mock function with given fields: ctx, filters, includeDeleted
+func (_m *ReaderRepository) CountUsers(ctx context.Context, filters []user.Filter, includeDeleted bool) (int, errs.ChatError) {
+	ret := _m.Called(ctx, filters, includeDeleted)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountUsers")
+	}
+
+	var r0 int
+	var r1 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, []user.Filter, bool) (int, errs.ChatError)); ok {
+		return rf(ctx, filters, includeDeleted)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []user.Filter, bool) int); ok {
+		r0 = rf(ctx, filters, includeDeleted)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []user.Filter, bool) errs.ChatError); ok {
+		r1 = rf(ctx, filters, includeDeleted)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(errs.ChatError)
+		}
+	}
+
+	return r0, r1
+}
+
+// ReaderRepository_CountUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountUsers'
+type ReaderRepository_CountUsers_Call struct {
+	*mock.Call
+}
+
+// CountUsers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filters []user.Filter
+//   - includeDeleted bool
+func (_e *ReaderRepository_Expecter) CountUsers(ctx interface{}, filters interface{}, includeDeleted interface{}) *ReaderRepository_CountUsers_Call {
+	return &ReaderRepository_CountUsers_Call{Call: _e.mock.On("CountUsers", ctx, filters, includeDeleted)}
+}
+
+func (_c *ReaderRepository_CountUsers_Call) Run(run func(ctx context.Context, filters []user.Filter, includeDeleted bool)) *ReaderRepository_CountUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]user.Filter), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *ReaderRepository_CountUsers_Call) Return(_a0 int, _a1 errs.ChatError) *ReaderRepository_CountUsers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ReaderRepository_CountUsers_Call) RunAndReturn(run func(context.Context, []user.Filter, bool) (int, errs.ChatError)) *ReaderRepository_CountUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPasswordHash provides a mock function with given fields: ctx, userId
+func (_m *ReaderRepository) GetPasswordHash(ctx context.Context, userId string) (string, errs.ChatError) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPasswordHash")
+	}
+
+	var r0 string
+	var r1 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, errs.ChatError)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) errs.ChatError); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(errs.ChatError)
+		}
+	}
+
+	return r0, r1
+}
+
+// ReaderRepository_GetPasswordHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPasswordHash'
+type ReaderRepository_GetPasswordHash_Call struct {
+	*mock.Call
+}
+
+// GetPasswordHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+func (_e *ReaderRepository_Expecter) GetPasswordHash(ctx interface{}, userId interface{}) *ReaderRepository_GetPasswordHash_Call {
+	return &ReaderRepository_GetPasswordHash_Call{Call: _e.mock.On("GetPasswordHash", ctx, userId)}
+}
+
+func (_c *ReaderRepository_GetPasswordHash_Call) Run(run func(ctx context.Context, userId string)) *ReaderRepository_GetPasswordHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ReaderRepository_GetPasswordHash_Call) Return(_a0 string, _a1 errs.ChatError) *ReaderRepository_GetPasswordHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ReaderRepository_GetPasswordHash_Call) RunAndReturn(run func(context.Context, string) (string, errs.ChatError)) *ReaderRepository_GetPasswordHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUser provides a mock function with given fields: ctx, key, value, includeDeleted
+func (_m *ReaderRepository) GetUser(ctx context.Context, key string, value interface{}, includeDeleted bool) (user.User, errs.ChatError) {
+	ret := _m.Called(ctx, key, value, includeDeleted)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUser")
@@ -34,17 +154,17 @@ func (_m *ReaderRepository) GetUser(ctx context.Context, key string, value inter
 
 	var r0 user.User
 	var r1 errs.ChatError
-	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}) (user.User, errs.ChatError)); ok {
-		return rf(ctx, key, value)
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, bool) (user.User, errs.ChatError)); ok {
+		return rf(ctx, key, value, includeDeleted)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}) user.User); ok {
-		r0 = rf(ctx, key, value)
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, bool) user.User); ok {
+		r0 = rf(ctx, key, value, includeDeleted)
 	} else {
 		r0 = ret.Get(0).(user.User)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, interface{}) errs.ChatError); ok {
-		r1 = rf(ctx, key, value)
+	if rf, ok := ret.Get(1).(func(context.Context, string, interface{}, bool) errs.ChatError); ok {
+		r1 = rf(ctx, key, value, includeDeleted)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(errs.ChatError)
@@ -63,13 +183,14 @@ type ReaderRepository_GetUser_Call struct {
 //   - ctx context.Context
 //   - key string
 //   - value interface{}
-func (_e *ReaderRepository_Expecter) GetUser(ctx interface{}, key interface{}, value interface{}) *ReaderRepository_GetUser_Call {
-	return &ReaderRepository_GetUser_Call{Call: _e.mock.On("GetUser", ctx, key, value)}
+//   - includeDeleted bool
+func (_e *ReaderRepository_Expecter) GetUser(ctx interface{}, key interface{}, value interface{}, includeDeleted interface{}) *ReaderRepository_GetUser_Call {
+	return &ReaderRepository_GetUser_Call{Call: _e.mock.On("GetUser", ctx, key, value, includeDeleted)}
 }
 
-func (_c *ReaderRepository_GetUser_Call) Run(run func(ctx context.Context, key string, value interface{})) *ReaderRepository_GetUser_Call {
+func (_c *ReaderRepository_GetUser_Call) Run(run func(ctx context.Context, key string, value interface{}, includeDeleted bool)) *ReaderRepository_GetUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(interface{}))
+		run(args[0].(context.Context), args[1].(string), args[2].(interface{}), args[3].(bool))
 	})
 	return _c
 }
@@ -79,14 +200,14 @@ func (_c *ReaderRepository_GetUser_Call) Return(_a0 user.User, _a1 errs.ChatErro
 	return _c
 }
 
-func (_c *ReaderRepository_GetUser_Call) RunAndReturn(run func(context.Context, string, interface{}) (user.User, errs.ChatError)) *ReaderRepository_GetUser_Call {
+func (_c *ReaderRepository_GetUser_Call) RunAndReturn(run func(context.Context, string, interface{}, bool) (user.User, errs.ChatError)) *ReaderRepository_GetUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListUsers provides a mock function with given fields: ctx, columns, filters, sorts, page
-func (_m *ReaderRepository) ListUsers(ctx context.Context, columns []string, filters []user.Filter, sorts []user.Sort, page user.Pagination) ([]user.User, errs.ChatError) {
-	ret := _m.Called(ctx, columns, filters, sorts, page)
+// ListUsers provides a mock function with given fields: ctx, columns, filters, sorts, page, includeDeleted
+func (_m *ReaderRepository) ListUsers(ctx context.Context, columns []string, filters []user.Filter, sorts []user.Sort, page user.Pagination, includeDeleted bool) ([]user.User, errs.ChatError) {
+	ret := _m.Called(ctx, columns, filters, sorts, page, includeDeleted)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ListUsers")
@@ -94,19 +215,19 @@ func (_m *ReaderRepository) ListUsers(ctx context.Context, columns []string, fil
 
 	var r0 []user.User
 	var r1 errs.ChatError
-	if rf, ok := ret.Get(0).(func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination) ([]user.User, errs.ChatError)); ok {
-		return rf(ctx, columns, filters, sorts, page)
+	if rf, ok := ret.Get(0).(func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination, bool) ([]user.User, errs.ChatError)); ok {
+		return rf(ctx, columns, filters, sorts, page, includeDeleted)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination) []user.User); ok {
-		r0 = rf(ctx, columns, filters, sorts, page)
+	if rf, ok := ret.Get(0).(func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination, bool) []user.User); ok {
+		r0 = rf(ctx, columns, filters, sorts, page, includeDeleted)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]user.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination) errs.ChatError); ok {
-		r1 = rf(ctx, columns, filters, sorts, page)
+	if rf, ok := ret.Get(1).(func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination, bool) errs.ChatError); ok {
+		r1 = rf(ctx, columns, filters, sorts, page, includeDeleted)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(errs.ChatError)
@@ -127,13 +248,14 @@ type ReaderRepository_ListUsers_Call struct {
 //   - filters []user.Filter
 //   - sorts []user.Sort
 //   - page user.Pagination
-func (_e *ReaderRepository_Expecter) ListUsers(ctx interface{}, columns interface{}, filters interface{}, sorts interface{}, page interface{}) *ReaderRepository_ListUsers_Call {
-	return &ReaderRepository_ListUsers_Call{Call: _e.mock.On("ListUsers", ctx, columns, filters, sorts, page)}
+//   - includeDeleted bool
+func (_e *ReaderRepository_Expecter) ListUsers(ctx interface{}, columns interface{}, filters interface{}, sorts interface{}, page interface{}, includeDeleted interface{}) *ReaderRepository_ListUsers_Call {
+	return &ReaderRepository_ListUsers_Call{Call: _e.mock.On("ListUsers", ctx, columns, filters, sorts, page, includeDeleted)}
 }
 
-func (_c *ReaderRepository_ListUsers_Call) Run(run func(ctx context.Context, columns []string, filters []user.Filter, sorts []user.Sort, page user.Pagination)) *ReaderRepository_ListUsers_Call {
+func (_c *ReaderRepository_ListUsers_Call) Run(run func(ctx context.Context, columns []string, filters []user.Filter, sorts []user.Sort, page user.Pagination, includeDeleted bool)) *ReaderRepository_ListUsers_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].([]string), args[2].([]user.Filter), args[3].([]user.Sort), args[4].(user.Pagination))
+		run(args[0].(context.Context), args[1].([]string), args[2].([]user.Filter), args[3].([]user.Sort), args[4].(user.Pagination), args[5].(bool))
 	})
 	return _c
 }
@@ -143,7 +265,7 @@ func (_c *ReaderRepository_ListUsers_Call) Return(_a0 []user.User, _a1 errs.Chat
 	return _c
 }
 
-func (_c *ReaderRepository_ListUsers_Call) RunAndReturn(run func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination) ([]user.User, errs.ChatError)) *ReaderRepository_ListUsers_Call {
+func (_c *ReaderRepository_ListUsers_Call) RunAndReturn(run func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination, bool) ([]user.User, errs.ChatError)) *ReaderRepository_ListUsers_Call {
 	_c.Call.Return(run)
 	return _c
 }
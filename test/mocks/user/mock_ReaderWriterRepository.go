@@ -5,12 +5,13 @@ package user
 import (
 	context "context"
 
-	errs "github.com/raffops/chat_commons/pkg/errs"
-	mock "github.com/stretchr/testify/mock"
-
 	sql "database/sql"
 
 	user "github.com/raffops/chat_auth/internal/app/user/models"
+
+	errs "github.com/raffops/chat_commons/pkg/errs"
+
+	mock "github.com/stretchr/testify/mock"
 )
 
 // ReaderWriterRepository is an autogenerated mock type for the ReaderWriterRepository type
@@ -26,6 +27,66 @@ func (_m *ReaderWriterRepository) EXPECT() *ReaderWriterRepository_Expecter {
 	return &ReaderWriterRepository_Expecter{mock: &_m.Mock}
 }
 
+// CountUsers provides a mock function with given fields: ctx, filters, includeDeleted
+func (_m *ReaderWriterRepository) CountUsers(ctx context.Context, filters []user.Filter, includeDeleted bool) (int, errs.ChatError) {
+	ret := _m.Called(ctx, filters, includeDeleted)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountUsers")
+	}
+
+	var r0 int
+	var r1 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, []user.Filter, bool) (int, errs.ChatError)); ok {
+		return rf(ctx, filters, includeDeleted)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []user.Filter, bool) int); ok {
+		r0 = rf(ctx, filters, includeDeleted)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []user.Filter, bool) errs.ChatError); ok {
+		r1 = rf(ctx, filters, includeDeleted)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(errs.ChatError)
+		}
+	}
+
+	return r0, r1
+}
+
+// ReaderWriterRepository_CountUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountUsers'
+type ReaderWriterRepository_CountUsers_Call struct {
+	*mock.Call
+}
+
+// CountUsers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filters []user.Filter
+//   - includeDeleted bool
+func (_e *ReaderWriterRepository_Expecter) CountUsers(ctx interface{}, filters interface{}, includeDeleted interface{}) *ReaderWriterRepository_CountUsers_Call {
+	return &ReaderWriterRepository_CountUsers_Call{Call: _e.mock.On("CountUsers", ctx, filters, includeDeleted)}
+}
+
+func (_c *ReaderWriterRepository_CountUsers_Call) Run(run func(ctx context.Context, filters []user.Filter, includeDeleted bool)) *ReaderWriterRepository_CountUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]user.Filter), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *ReaderWriterRepository_CountUsers_Call) Return(_a0 int, _a1 errs.ChatError) *ReaderWriterRepository_CountUsers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ReaderWriterRepository_CountUsers_Call) RunAndReturn(run func(context.Context, []user.Filter, bool) (int, errs.ChatError)) *ReaderWriterRepository_CountUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CreateUser provides a mock function with given fields: ctx, tx, u
 func (_m *ReaderWriterRepository) CreateUser(ctx context.Context, tx *sql.Tx, u user.User) (user.User, errs.ChatError) {
 	ret := _m.Called(ctx, tx, u)
@@ -193,9 +254,68 @@ func (_c *ReaderWriterRepository_GetDB_Call) RunAndReturn(run func() *sql.DB) *R
 	return _c
 }
 
-// GetUser provides a mock function with given fields: ctx, key, value
-func (_m *ReaderWriterRepository) GetUser(ctx context.Context, key string, value interface{}) (user.User, errs.ChatError) {
-	ret := _m.Called(ctx, key, value)
+// GetPasswordHash provides a mock function with given fields: ctx, userId
+func (_m *ReaderWriterRepository) GetPasswordHash(ctx context.Context, userId string) (string, errs.ChatError) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPasswordHash")
+	}
+
+	var r0 string
+	var r1 errs.ChatError
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, errs.ChatError)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) errs.ChatError); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(errs.ChatError)
+		}
+	}
+
+	return r0, r1
+}
+
+// ReaderWriterRepository_GetPasswordHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPasswordHash'
+type ReaderWriterRepository_GetPasswordHash_Call struct {
+	*mock.Call
+}
+
+// GetPasswordHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+func (_e *ReaderWriterRepository_Expecter) GetPasswordHash(ctx interface{}, userId interface{}) *ReaderWriterRepository_GetPasswordHash_Call {
+	return &ReaderWriterRepository_GetPasswordHash_Call{Call: _e.mock.On("GetPasswordHash", ctx, userId)}
+}
+
+func (_c *ReaderWriterRepository_GetPasswordHash_Call) Run(run func(ctx context.Context, userId string)) *ReaderWriterRepository_GetPasswordHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ReaderWriterRepository_GetPasswordHash_Call) Return(_a0 string, _a1 errs.ChatError) *ReaderWriterRepository_GetPasswordHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ReaderWriterRepository_GetPasswordHash_Call) RunAndReturn(run func(context.Context, string) (string, errs.ChatError)) *ReaderWriterRepository_GetPasswordHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUser provides a mock function with given fields: ctx, key, value, includeDeleted
+func (_m *ReaderWriterRepository) GetUser(ctx context.Context, key string, value interface{}, includeDeleted bool) (user.User, errs.ChatError) {
+	ret := _m.Called(ctx, key, value, includeDeleted)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUser")
@@ -203,17 +323,17 @@ func (_m *ReaderWriterRepository) GetUser(ctx context.Context, key string, value
 
 	var r0 user.User
 	var r1 errs.ChatError
-	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}) (user.User, errs.ChatError)); ok {
-		return rf(ctx, key, value)
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, bool) (user.User, errs.ChatError)); ok {
+		return rf(ctx, key, value, includeDeleted)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}) user.User); ok {
-		r0 = rf(ctx, key, value)
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, bool) user.User); ok {
+		r0 = rf(ctx, key, value, includeDeleted)
 	} else {
 		r0 = ret.Get(0).(user.User)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, interface{}) errs.ChatError); ok {
-		r1 = rf(ctx, key, value)
+	if rf, ok := ret.Get(1).(func(context.Context, string, interface{}, bool) errs.ChatError); ok {
+		r1 = rf(ctx, key, value, includeDeleted)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(errs.ChatError)
@@ -232,13 +352,14 @@ type ReaderWriterRepository_GetUser_Call struct {
 //   - ctx context.Context
 //   - key string
 //   - value interface{}
-func (_e *ReaderWriterRepository_Expecter) GetUser(ctx interface{}, key interface{}, value interface{}) *ReaderWriterRepository_GetUser_Call {
-	return &ReaderWriterRepository_GetUser_Call{Call: _e.mock.On("GetUser", ctx, key, value)}
+//   - includeDeleted bool
+func (_e *ReaderWriterRepository_Expecter) GetUser(ctx interface{}, key interface{}, value interface{}, includeDeleted interface{}) *ReaderWriterRepository_GetUser_Call {
+	return &ReaderWriterRepository_GetUser_Call{Call: _e.mock.On("GetUser", ctx, key, value, includeDeleted)}
 }
 
-func (_c *ReaderWriterRepository_GetUser_Call) Run(run func(ctx context.Context, key string, value interface{})) *ReaderWriterRepository_GetUser_Call {
+func (_c *ReaderWriterRepository_GetUser_Call) Run(run func(ctx context.Context, key string, value interface{}, includeDeleted bool)) *ReaderWriterRepository_GetUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(interface{}))
+		run(args[0].(context.Context), args[1].(string), args[2].(interface{}), args[3].(bool))
 	})
 	return _c
 }
@@ -248,14 +369,14 @@ func (_c *ReaderWriterRepository_GetUser_Call) Return(_a0 user.User, _a1 errs.Ch
 	return _c
 }
 
-func (_c *ReaderWriterRepository_GetUser_Call) RunAndReturn(run func(context.Context, string, interface{}) (user.User, errs.ChatError)) *ReaderWriterRepository_GetUser_Call {
+func (_c *ReaderWriterRepository_GetUser_Call) RunAndReturn(run func(context.Context, string, interface{}, bool) (user.User, errs.ChatError)) *ReaderWriterRepository_GetUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListUsers provides a mock function with given fields: ctx, columns, filters, sorts, page
-func (_m *ReaderWriterRepository) ListUsers(ctx context.Context, columns []string, filters []user.Filter, sorts []user.Sort, page user.Pagination) ([]user.User, errs.ChatError) {
-	ret := _m.Called(ctx, columns, filters, sorts, page)
+// ListUsers provides a mock function with given fields: ctx, columns, filters, sorts, page, includeDeleted
+func (_m *ReaderWriterRepository) ListUsers(ctx context.Context, columns []string, filters []user.Filter, sorts []user.Sort, page user.Pagination, includeDeleted bool) ([]user.User, errs.ChatError) {
+	ret := _m.Called(ctx, columns, filters, sorts, page, includeDeleted)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ListUsers")
@@ -263,19 +384,19 @@ func (_m *ReaderWriterRepository) ListUsers(ctx context.Context, columns []strin
 
 	var r0 []user.User
 	var r1 errs.ChatError
-	if rf, ok := ret.Get(0).(func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination) ([]user.User, errs.ChatError)); ok {
-		return rf(ctx, columns, filters, sorts, page)
+	if rf, ok := ret.Get(0).(func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination, bool) ([]user.User, errs.ChatError)); ok {
+		return rf(ctx, columns, filters, sorts, page, includeDeleted)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination) []user.User); ok {
-		r0 = rf(ctx, columns, filters, sorts, page)
+	if rf, ok := ret.Get(0).(func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination, bool) []user.User); ok {
+		r0 = rf(ctx, columns, filters, sorts, page, includeDeleted)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]user.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination) errs.ChatError); ok {
-		r1 = rf(ctx, columns, filters, sorts, page)
+	if rf, ok := ret.Get(1).(func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination, bool) errs.ChatError); ok {
+		r1 = rf(ctx, columns, filters, sorts, page, includeDeleted)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(errs.ChatError)
@@ -296,13 +417,14 @@ type ReaderWriterRepository_ListUsers_Call struct {
 //   - filters []user.Filter
 //   - sorts []user.Sort
 //   - page user.Pagination
-func (_e *ReaderWriterRepository_Expecter) ListUsers(ctx interface{}, columns interface{}, filters interface{}, sorts interface{}, page interface{}) *ReaderWriterRepository_ListUsers_Call {
-	return &ReaderWriterRepository_ListUsers_Call{Call: _e.mock.On("ListUsers", ctx, columns, filters, sorts, page)}
+//   - includeDeleted bool
+func (_e *ReaderWriterRepository_Expecter) ListUsers(ctx interface{}, columns interface{}, filters interface{}, sorts interface{}, page interface{}, includeDeleted interface{}) *ReaderWriterRepository_ListUsers_Call {
+	return &ReaderWriterRepository_ListUsers_Call{Call: _e.mock.On("ListUsers", ctx, columns, filters, sorts, page, includeDeleted)}
 }
 
-func (_c *ReaderWriterRepository_ListUsers_Call) Run(run func(ctx context.Context, columns []string, filters []user.Filter, sorts []user.Sort, page user.Pagination)) *ReaderWriterRepository_ListUsers_Call {
+func (_c *ReaderWriterRepository_ListUsers_Call) Run(run func(ctx context.Context, columns []string, filters []user.Filter, sorts []user.Sort, page user.Pagination, includeDeleted bool)) *ReaderWriterRepository_ListUsers_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].([]string), args[2].([]user.Filter), args[3].([]user.Sort), args[4].(user.Pagination))
+		run(args[0].(context.Context), args[1].([]string), args[2].([]user.Filter), args[3].([]user.Sort), args[4].(user.Pagination), args[5].(bool))
 	})
 	return _c
 }
@@ -312,7 +434,7 @@ func (_c *ReaderWriterRepository_ListUsers_Call) Return(_a0 []user.User, _a1 err
 	return _c
 }
 
-func (_c *ReaderWriterRepository_ListUsers_Call) RunAndReturn(run func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination) ([]user.User, errs.ChatError)) *ReaderWriterRepository_ListUsers_Call {
+func (_c *ReaderWriterRepository_ListUsers_Call) RunAndReturn(run func(context.Context, []string, []user.Filter, []user.Sort, user.Pagination, bool) ([]user.User, errs.ChatError)) *ReaderWriterRepository_ListUsers_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -5,12 +5,13 @@ package user
 import (
 	context "context"
 
-	errs "github.com/raffops/chat_commons/pkg/errs"
-	mock "github.com/stretchr/testify/mock"
-
 	sql "database/sql"
 
 	user "github.com/raffops/chat_auth/internal/app/user/models"
+
+	errs "github.com/raffops/chat_commons/pkg/errs"
+
+	mock "github.com/stretchr/testify/mock"
 )
 
 // WriterRepository is an autogenerated mock type for the WriterRepository type
@@ -0,0 +1,301 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/oidc"
+	oidcModels "github.com/raffops/chat_auth/internal/app/oidc/model"
+	oidcService "github.com/raffops/chat_auth/internal/app/oidc/service"
+	tokenModels "github.com/raffops/chat_auth/internal/app/token/model"
+	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	clientId     = "chat_auth"
+	clientSecret = "Q3hhdF9hdXRoX3NlY3JldA"
+	redirectUrl  = "http://localhost:8080/login/sso/callback"
+)
+
+// ssoAuthType is the auth type of the provider, as if it was loaded from the 'auth_type' table.
+var ssoAuthType = userModels.AuthTypeId(4)
+
+type OidcTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	server   *mockOidcServer
+	provider oidc.Provider
+	rsaKey   signingKey
+	code     string
+	request  oidcModels.AuthRequest
+}
+
+func (s *OidcTestSuite) TestSetupSuite() {
+	s.ctx = context.Background()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		s.T().Fatalf("GenerateKey() error = %v", err)
+	}
+	s.rsaKey = signingKey{id: "rsa-1", algorithm: tokenModels.AlgorithmRS256, privateKey: privateKey}
+	s.server = newMockOidcServer(clientId, clientSecret, s.rsaKey)
+	defer s.server.server.Close()
+	s.server.setUserClaims(map[string]interface{}{
+		"email":              "john@doe",
+		"email_verified":     true,
+		"preferred_username": "john",
+		"groups":             []string{"dev", "chat-admins"},
+	})
+	userModels.RegisterAuthType(ssoAuthType, "sso")
+
+	success := s.Run("discoverProvider", s.discoverProvider)
+	if !success {
+		s.T().Fatalf("discoverProvider() failed")
+	}
+	success = s.Run("rejectUnknownIssuer", s.rejectUnknownIssuer)
+	if !success {
+		s.T().Fatalf("rejectUnknownIssuer() failed")
+	}
+	success = s.Run("authorizeWithPkce", s.authorizeWithPkce)
+	if !success {
+		s.T().Fatalf("authorizeWithPkce() failed")
+	}
+	success = s.Run("rejectWrongCodeVerifier", s.rejectWrongCodeVerifier)
+	if !success {
+		s.T().Fatalf("rejectWrongCodeVerifier() failed")
+	}
+	success = s.Run("exchangeCode", s.exchangeCode)
+	if !success {
+		s.T().Fatalf("exchangeCode() failed")
+	}
+	success = s.Run("rejectReusedCode", s.rejectReusedCode)
+	if !success {
+		s.T().Fatalf("rejectReusedCode() failed")
+	}
+	success = s.Run("rejectUnverifiedEmail", s.rejectUnverifiedEmail)
+	if !success {
+		s.T().Fatalf("rejectUnverifiedEmail() failed")
+	}
+	success = s.Run("rejectInvalidIdTokens", s.rejectInvalidIdTokens)
+	if !success {
+		s.T().Fatalf("rejectInvalidIdTokens() failed")
+	}
+	success = s.Run("verifyWithRotatedKey", s.verifyWithRotatedKey)
+	if !success {
+		s.T().Fatalf("verifyWithRotatedKey() failed")
+	}
+}
+
+func (s *OidcTestSuite) config() oidcModels.Config {
+	return oidcModels.Config{
+		DiscoveryUrl: s.server.discoveryUrl(),
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		RedirectUrl:  redirectUrl,
+		Scopes:       []string{"openid", "email", "profile", "groups"},
+		AdminGroups:  []string{"chat-admins"},
+	}
+}
+
+func (s *OidcTestSuite) discoverProvider() {
+	provider, err := oidcService.NewProvider(s.ctx, "sso", s.config(), s.server.server.Client())
+	if err != nil {
+		s.T().Fatalf("NewProvider() error = %v", err)
+	}
+	s.Equal("sso", provider.Name())
+	s.provider = provider
+}
+
+// rejectUnknownIssuer discovers a provider that claims to be another issuer.
+func (s *OidcTestSuite) rejectUnknownIssuer() {
+	s.server.setIssuer("https://sso.example.com")
+	defer s.server.setIssuer("")
+	_, err := oidcService.NewProvider(s.ctx, "sso", s.config(), s.server.server.Client())
+	s.NotNil(err)
+}
+
+// authorizeWithPkce follows the authorization url, like the browser of the user, up to the
+// redirect to the callback.
+func (s *OidcTestSuite) authorizeWithPkce() {
+	var err error
+	s.request, err = oidcModels.NewAuthRequest()
+	if err != nil {
+		s.T().Fatalf("NewAuthRequest() error = %v", err)
+	}
+	authCodeUrl, err := url.Parse(s.provider.AuthCodeUrl(s.request))
+	if err != nil {
+		s.T().Fatalf("AuthCodeUrl() error = %v", err)
+	}
+	query := authCodeUrl.Query()
+	s.Equal("openid email profile groups", query.Get("scope"))
+	s.Equal(oidcModels.CodeChallenge(s.request.CodeVerifier), query.Get("code_challenge"))
+	s.Equal(s.request.Nonce, query.Get("nonce"))
+
+	s.code = s.authorize(s.request)
+}
+
+func (s *OidcTestSuite) authorize(request oidcModels.AuthRequest) string {
+	client := s.server.server.Client()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := client.Get(s.provider.AuthCodeUrl(request))
+	if err != nil {
+		s.T().Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		s.T().Fatalf("Get() status = %v, want %v", resp.StatusCode, http.StatusFound)
+	}
+	location, _ := url.Parse(resp.Header.Get("Location"))
+	s.Equal(request.State, location.Query().Get("state"))
+	return location.Query().Get("code")
+}
+
+func (s *OidcTestSuite) rejectWrongCodeVerifier() {
+	request, _ := oidcModels.NewAuthRequest()
+	code := s.authorize(request)
+	request.CodeVerifier = s.request.CodeVerifier
+	_, _, err := s.provider.Exchange(s.ctx, code, request)
+	s.NotNil(err)
+}
+
+func (s *OidcTestSuite) exchangeCode() {
+	got, identity, err := s.provider.Exchange(s.ctx, s.code, s.request)
+	if err != nil {
+		s.T().Fatalf("Exchange() error = %v", err)
+	}
+	s.Equal(userModels.User{
+		Username: "john",
+		Email:    "john@doe",
+		AuthType: ssoAuthType,
+		Role:     authModels.RoleAdmin,
+		Status:   userModels.StatusActive,
+	}, got)
+	s.Equal(userModels.Identity{Provider: "sso", Subject: "248289761001", Email: "john@doe"}, identity)
+}
+
+func (s *OidcTestSuite) rejectReusedCode() {
+	_, _, err := s.provider.Exchange(s.ctx, s.code, s.request)
+	s.NotNil(err)
+}
+
+func (s *OidcTestSuite) rejectUnverifiedEmail() {
+	s.server.setUserClaims(map[string]interface{}{
+		"email":              "john@doe",
+		"email_verified":     false,
+		"preferred_username": "john",
+	})
+	defer s.server.setUserClaims(nil)
+	request, _ := oidcModels.NewAuthRequest()
+	_, _, err := s.provider.Exchange(s.ctx, s.authorize(request), request)
+	s.NotNil(err)
+}
+
+func (s *OidcTestSuite) rejectInvalidIdTokens() {
+	nonce := "n-0S6_WzA2Mj"
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	tests := []struct {
+		name    string
+		claims  func(claims map[string]interface{})
+		sign    func(claims map[string]interface{}) string
+		wantErr bool
+	}{
+		{name: "Test valid id token"},
+		{
+			name:    "Test wrong issuer",
+			claims:  func(claims map[string]interface{}) { claims["iss"] = "https://sso.example.com" },
+			wantErr: true,
+		},
+		{
+			name:    "Test wrong audience",
+			claims:  func(claims map[string]interface{}) { claims["aud"] = "other" },
+			wantErr: true,
+		},
+		{
+			name:    "Test audiences without authorized party",
+			claims:  func(claims map[string]interface{}) { claims["aud"] = []string{clientId, "other"} },
+			wantErr: true,
+		},
+		{
+			name: "Test audiences with authorized party",
+			claims: func(claims map[string]interface{}) {
+				claims["aud"] = []string{clientId, "other"}
+				claims["azp"] = clientId
+			},
+		},
+		{
+			name: "Test expired",
+			claims: func(claims map[string]interface{}) {
+				claims["exp"] = time.Now().Add(-2 * time.Minute).Unix()
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Test wrong nonce",
+			claims:  func(claims map[string]interface{}) { claims["nonce"] = "other" },
+			wantErr: true,
+		},
+		{
+			name: "Test signed by unknown key",
+			sign: func(claims map[string]interface{}) string {
+				key := signingKey{id: "rsa-1", privateKey: otherKey}
+				return signToken(key, tokenModels.AlgorithmRS256, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test unsigned",
+			sign: func(claims map[string]interface{}) string {
+				token := signToken(s.rsaKey, "none", claims)
+				return token[:strings.LastIndex(token, ".")+1]
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			claims := s.server.idTokenClaims(nonce)
+			if tt.claims != nil {
+				tt.claims(claims)
+			}
+			idToken := s.server.sign(claims)
+			if tt.sign != nil {
+				idToken = tt.sign(claims)
+			}
+			got, err := s.provider.Verify(s.ctx, idToken, nonce)
+			if (err != nil) != tt.wantErr {
+				s.T().Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				s.Equal("248289761001", got.Subject)
+			}
+		})
+	}
+}
+
+// verifyWithRotatedKey signs with a key published after the provider was created, which is
+// fetched on the first token that uses it.
+func (s *OidcTestSuite) verifyWithRotatedKey() {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		s.T().Fatalf("GenerateKey() error = %v", err)
+	}
+	s.server.addKey(signingKey{id: "ec-1", algorithm: tokenModels.AlgorithmES256, privateKey: privateKey})
+	nonce := "n-0S6_WzA2Mj"
+	_, errVerify := s.provider.Verify(s.ctx, s.server.sign(s.server.idTokenClaims(nonce)), nonce)
+	s.Nil(errVerify)
+}
+
+func TestOidc(t *testing.T) {
+	suite.Run(t, new(OidcTestSuite))
+}
@@ -0,0 +1,246 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	oidcModels "github.com/raffops/chat_auth/internal/app/oidc/model"
+	tokenModels "github.com/raffops/chat_auth/internal/app/token/model"
+)
+
+var encoding = base64.RawURLEncoding
+
+type signingKey struct {
+	id         string
+	algorithm  string
+	privateKey crypto.Signer
+}
+
+type authorization struct {
+	codeChallenge string
+	nonce         string
+	redirectUrl   string
+}
+
+// mockOidcServer is an OpenID Connect provider for the tests, with the discovery, keys,
+// authorization and token endpoints. It signs the ID tokens with its last key and the claims
+// of the user.
+type mockOidcServer struct {
+	server       *httptest.Server
+	clientId     string
+	clientSecret string
+
+	mu             sync.Mutex
+	issuerUrl      string
+	keys           []signingKey
+	userClaims     map[string]interface{}
+	authorizations map[string]authorization
+}
+
+func newMockOidcServer(clientId, clientSecret string, key signingKey) *mockOidcServer {
+	m := &mockOidcServer{
+		clientId:       clientId,
+		clientSecret:   clientSecret,
+		keys:           []signingKey{key},
+		authorizations: map[string]authorization{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("GET /keys", m.jwks)
+	mux.HandleFunc("GET /authorize", m.authorize)
+	mux.HandleFunc("POST /token", m.token)
+	m.server = httptest.NewServer(mux)
+	return m
+}
+
+func (m *mockOidcServer) issuer() string {
+	if m.issuerUrl != "" {
+		return m.issuerUrl
+	}
+	return m.server.URL
+}
+
+// setIssuer makes the server claim to be another issuer, or itself when empty.
+func (m *mockOidcServer) setIssuer(issuerUrl string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.issuerUrl = issuerUrl
+}
+
+func (m *mockOidcServer) discoveryUrl() string {
+	return m.server.URL + "/.well-known/openid-configuration"
+}
+
+func (m *mockOidcServer) setUserClaims(claims map[string]interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.userClaims = claims
+}
+
+// addKey publishes a new key, which signs the next ID tokens.
+func (m *mockOidcServer) addKey(key signingKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys = append(m.keys, key)
+}
+
+func (m *mockOidcServer) discovery(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                m.issuer(),
+		"authorization_endpoint":                m.server.URL + "/authorize",
+		"token_endpoint":                        m.server.URL + "/token",
+		"jwks_uri":                              m.server.URL + "/keys",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256", "ES256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (m *mockOidcServer) jwks(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jwks := tokenModels.JWKS{}
+	for _, key := range m.keys {
+		jwk := tokenModels.JWK{Use: "sig", KeyId: key.id, Algorithm: key.algorithm}
+		switch publicKey := key.privateKey.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = encoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = encoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case *ecdsa.PublicKey:
+			jwk.KeyType = "EC"
+			jwk.Curve = "P-256"
+			jwk.X = encoding.EncodeToString(publicKey.X.FillBytes(make([]byte, 32)))
+			jwk.Y = encoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, 32)))
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	writeJson(w, http.StatusOK, jwks)
+}
+
+// authorize authorizes any user right away, redirecting back with a code.
+func (m *mockOidcServer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != m.clientId ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	code := randomString()
+	m.mu.Lock()
+	m.authorizations[code] = authorization{
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		redirectUrl:   query.Get("redirect_uri"),
+	}
+	m.mu.Unlock()
+
+	redirectUrl, _ := url.Parse(query.Get("redirect_uri"))
+	values := redirectUrl.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirectUrl.RawQuery = values.Encode()
+	http.Redirect(w, r, redirectUrl.String(), http.StatusFound)
+}
+
+// token redeems a code once, checking the client, the redirect url and the code verifier.
+func (m *mockOidcServer) token(w http.ResponseWriter, r *http.Request) {
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok || clientId != m.clientId || clientSecret != m.clientSecret {
+		writeJson(w, http.StatusUnauthorized, oidcModels.TokenResponse{Error: "invalid_client"})
+		return
+	}
+	m.mu.Lock()
+	auth, ok := m.authorizations[r.PostFormValue("code")]
+	delete(m.authorizations, r.PostFormValue("code"))
+	m.mu.Unlock()
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" ||
+		r.PostFormValue("redirect_uri") != auth.redirectUrl {
+		writeJson(w, http.StatusBadRequest, oidcModels.TokenResponse{Error: "invalid_grant"})
+		return
+	}
+	if oidcModels.CodeChallenge(r.PostFormValue("code_verifier")) != auth.codeChallenge {
+		writeJson(w, http.StatusBadRequest, oidcModels.TokenResponse{
+			Error:            "invalid_grant",
+			ErrorDescription: "invalid code verifier",
+		})
+		return
+	}
+
+	claims := m.idTokenClaims(auth.nonce)
+	writeJson(w, http.StatusOK, oidcModels.TokenResponse{
+		AccessToken: randomString(),
+		TokenType:   "Bearer",
+		IdToken:     m.sign(claims),
+	})
+}
+
+// idTokenClaims are valid claims of an ID token for the client, with the claims of the user.
+func (m *mockOidcServer) idTokenClaims(nonce string) map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":   m.issuer(),
+		"sub":   "248289761001",
+		"aud":   m.clientId,
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"iat":   now.Unix(),
+		"nonce": nonce,
+	}
+	for name, value := range m.userClaims {
+		claims[name] = value
+	}
+	return claims
+}
+
+// sign signs the claims with the last key of the server.
+func (m *mockOidcServer) sign(claims map[string]interface{}) string {
+	m.mu.Lock()
+	key := m.keys[len(m.keys)-1]
+	m.mu.Unlock()
+	return signToken(key, key.algorithm, claims)
+}
+
+func signToken(key signingKey, algorithm string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": algorithm, "typ": "JWT", "kid": key.id})
+	payload, _ := json.Marshal(claims)
+	signingInput := encoding.EncodeToString(header) + "." + encoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch privateKey := key.privateKey.(type) {
+	case *rsa.PrivateKey:
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		r, s, _ := ecdsa.Sign(rand.Reader, privateKey, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signingInput + "." + encoding.EncodeToString(signature)
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return encoding.EncodeToString(b)
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/gorilla/mux"
 	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
 	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
+	sessionRepository "github.com/raffops/chat_auth/internal/app/sessionManager/repository"
 	"github.com/raffops/chat_auth/internal/app/sessionManager/service"
 	userModels "github.com/raffops/chat_auth/internal/app/user/models"
 	grpcMock "github.com/raffops/chat_auth/test/mocks/grpc"
@@ -59,14 +61,24 @@ func (s *SessionManagerDynamodbTestSuite) TestSetupSuite() {
 	defer databaseDynamo.Close(s.dynamoConn)
 
 	s.defaultEncryptor = encryptor.NewDefaultEncryptor()
-	//s.sessionRepo, err = sessionRepository.NewDynamodbRepository(s.dynamoConn, s.defaultEncryptor)
-	if err != nil {
-		s.T().Fatalf("NewDynamodbRepository() error = %v", err)
+	sessionRepo, errRepo := sessionRepository.NewDynamodbRepository(s.ctx, s.dynamoConn, s.defaultEncryptor)
+	if errRepo != nil {
+		s.T().Fatalf("NewDynamodbRepository() error = %v", errRepo)
 	}
+	s.sessionRepo = sessionRepo
 
 	timeout, _ := time.ParseDuration(os.Getenv("SESSION_TIMEOUT"))
 	s.secret = os.Getenv("SESSION_MANAGER_SECRET")
-	s.sessionSrv = service.NewDefaultService(s.sessionRepo, timeout, s.secret)
+	refreshTimeout, _ := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TIMEOUT"))
+	s.sessionSrv = service.NewDefaultService(
+		s.sessionRepo,
+		timeout,
+		refreshTimeout,
+		s.secret,
+		newPolicyService(s.T()),
+		nil,
+		nil,
+	)
 
 	s.johnUser = userModels.User{
 		Id:       "1",
@@ -85,6 +97,16 @@ func (s *SessionManagerDynamodbTestSuite) TestSetupSuite() {
 		s.T().Fatalf("createJohnSecondSession() failed")
 	}
 
+	success = s.Run("listJohnSessions", s.listJohnSessions)
+	if !success {
+		s.T().Fatalf("listJohnSessions() failed")
+	}
+
+	success = s.Run("limitAdminSessions", s.limitAdminSessions)
+	if !success {
+		s.T().Fatalf("limitAdminSessions() failed")
+	}
+
 	success = s.Run("checkInvalidSessionId", s.checkInvalidSessionId)
 	if !success {
 		s.T().Fatalf("checkInvalidSessionId() failed")
@@ -154,7 +176,7 @@ func (s *SessionManagerDynamodbTestSuite) createJohnFirstSession() {
 		"user_id":   id,
 	}
 
-	got, err := s.sessionSrv.CreateSession(s.ctx, id, payload)
+	got, _, err := s.sessionSrv.CreateSession(s.ctx, id, payload)
 	if got == "" {
 		s.T().Fatalf("CreateSession() got = %v, want not empty", got)
 	}
@@ -186,7 +208,7 @@ func (s *SessionManagerDynamodbTestSuite) createJohnSecondSession() {
 		"user_id":   id,
 	}
 	payloadString, _ := json.Marshal(payload)
-	got, err := s.sessionSrv.CreateSession(s.ctx, id, payload)
+	got, _, err := s.sessionSrv.CreateSession(s.ctx, id, payload)
 	if got == "" {
 		s.T().Fatalf("CreateSession() got = %v, want not empty", got)
 	}
@@ -208,6 +230,53 @@ func (s *SessionManagerDynamodbTestSuite) createJohnSecondSession() {
 	s.johnSecondSession = got
 }
 
+func (s *SessionManagerDynamodbTestSuite) listJohnSessions() {
+	sessions, err := s.sessionSrv.ListUserSessions(s.ctx, s.johnUser.Id)
+	if err != nil {
+		s.T().Fatalf("ListUserSessions() error = %v", err)
+	}
+	if len(sessions) != 2 {
+		s.T().Fatalf("ListUserSessions() got = %v sessions, want 2", len(sessions))
+	}
+	for _, session := range sessions {
+		s.Contains([]string{
+			sessionModels.SessionHandle(s.johnFirstSession),
+			sessionModels.SessionHandle(s.johnSecondSession),
+		}, session.Id)
+		s.True(session.ExpiresAt.After(time.Now()))
+	}
+}
+
+func (s *SessionManagerDynamodbTestSuite) limitAdminSessions() {
+	timeout, _ := time.ParseDuration(os.Getenv("SESSION_TIMEOUT"))
+	refreshTimeout, _ := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TIMEOUT"))
+	adminSrv := service.NewDefaultService(
+		s.sessionRepo,
+		timeout,
+		refreshTimeout,
+		s.secret,
+		newPolicyService(s.T()),
+		map[authModels.RoleId]sessionModels.Limit{
+			authModels.RoleAdmin: {Max: 1, Mode: sessionModels.LimitModeEvict},
+		},
+		nil,
+	)
+	payload := map[string]interface{}{"role": authModels.RoleAdmin}
+	firstSession, _, err := adminSrv.CreateSession(s.ctx, "4", payload)
+	if err != nil {
+		s.T().Fatalf("CreateSession() error = %v", err)
+	}
+	_, evicted, err := adminSrv.CreateSession(s.ctx, "4", payload)
+	if err != nil {
+		s.T().Fatalf("CreateSession() error = %v", err)
+	}
+	s.Equal([]string{firstSession}, evicted)
+	_, err = adminSrv.GetSession(s.ctx, firstSession)
+	if err == nil {
+		s.T().Fatalf("GetSession() of the evicted session got nil error")
+	}
+}
+
 func (s *SessionManagerDynamodbTestSuite) checkInvalidSessionId() {
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/", nil)
@@ -281,7 +350,9 @@ func (s *SessionManagerDynamodbTestSuite) checkJohnFirstSessionWithExpiredTTL()
 	}
 	router := mux.NewRouter()
 	router.HandleFunc("/", s.sessionSrv.CheckRestSession(f, []authModels.RoleId{authModels.RoleUser}))
-	time.Sleep(time.Until(s.johnSessionTimeout))
+	// The calls of the previous steps slid the idle timeout past the refresh.
+	s.johnSessionTimeout, _ = s.sessionRepo.GetTTL(s.ctx, "session", s.johnFirstSession)
+	time.Sleep(time.Until(s.johnSessionTimeout.Add(time.Duration(1) * time.Second)))
 
 	router.ServeHTTP(w, r)
 
@@ -343,9 +414,15 @@ func (s *SessionManagerDynamodbTestSuite) UseJohnFirstSessionAfterTimeout() {
 }
 
 func (s *SessionManagerDynamodbTestSuite) checkCorruptedSession() {
-	errSet := s.sessionRepo.HashSet(s.ctx, nil, "session", s.johnSecondSession, map[string]interface{}{"encrypted_value": "corrupted"})
+	tx, _ := s.sessionRepo.BeginTransaction(s.ctx)
+	errSet := s.sessionRepo.HashSet(s.ctx, tx, "session", s.johnSecondSession, map[string]interface{}{
+		"encrypted_value": "corrupted",
+	})
 	if errSet != nil {
-		s.T().Fatalf("Set() error = %v", errSet)
+		s.T().Fatalf("HashSet() error = %v", errSet)
+	}
+	if errCommit := s.sessionRepo.CommitTransaction(s.ctx, tx); errCommit != nil {
+		s.T().Fatalf("CommitTransaction() error = %v", errCommit)
 	}
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/", nil)
@@ -403,7 +480,8 @@ func (s *SessionManagerDynamodbTestSuite) CheckGrpcSessionValidToken() {
 	ss.EXPECT().Context().Return(ctx)
 	info := &grpc.StreamServerInfo{}
 	info.FullMethod = "/test"
-	s.sessionSrv.SetRoles("/test", []authModels.RoleId{s.johnUser.Role})
+	errSetRoles := s.sessionSrv.SetRoles(s.ctx, "/test", []authModels.RoleId{s.johnUser.Role})
+	s.Nil(errSetRoles)
 	handler := func(srv interface{}, stream grpc.ServerStream) error {
 		return nil
 	}
@@ -421,7 +499,8 @@ func (s *SessionManagerDynamodbTestSuite) CheckGrpcSessionValidTokenButInvalidRo
 	ss.EXPECT().Context().Return(ctx)
 	info := &grpc.StreamServerInfo{}
 	info.FullMethod = "/test"
-	s.sessionSrv.SetRoles("/test", []authModels.RoleId{authModels.RoleAdmin})
+	errSetRoles := s.sessionSrv.SetRoles(s.ctx, "/test", []authModels.RoleId{authModels.RoleAdmin})
+	s.Nil(errSetRoles)
 	handler := func(srv interface{}, stream grpc.ServerStream) error {
 		return nil
 	}
@@ -432,7 +511,11 @@ func (s *SessionManagerDynamodbTestSuite) CheckGrpcSessionValidTokenButInvalidRo
 }
 
 func TestSessionManagerDynamo(t *testing.T) {
+	os.Setenv("AWS_REGION", "us-east-1")
+	os.Setenv("AWS_ACCESS_KEY_ID", "test")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "test")
 	os.Setenv("SESSION_MANAGER_SECRET", "7CIuQStxETYG3x0qVO7TcZF7vUNnKlMz")
 	os.Setenv("SESSION_TIMEOUT", "6s")
+	os.Setenv("REFRESH_TOKEN_TIMEOUT", "10s")
 	suite.Run(t, new(SessionManagerDynamodbTestSuite))
 }
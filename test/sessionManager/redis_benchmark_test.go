@@ -0,0 +1,128 @@
+package sessionManager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	sessionRepository "github.com/raffops/chat_auth/internal/app/sessionManager/repository"
+	"github.com/raffops/chat_auth/internal/app/sessionManager/service"
+	databaseRedis "github.com/raffops/chat_commons/pkg/database/redis"
+	"github.com/raffops/chat_commons/pkg/encryptor"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	benchOtherUsers   = 10_000
+	benchUserSessions = 10
+)
+
+// BenchmarkFinishUserSessions compares revoking the sessions of a user through the set of
+// sessions by user with the former KEYS scan, in a Redis holding the sessions of many
+// other users, which KEYS walks on every call.
+func BenchmarkFinishUserSessions(b *testing.B) {
+	ctx := context.Background()
+	redisContainer, err := databaseRedis.GetRedisTestContainer(ctx)
+	if err != nil {
+		b.Fatalf("cannot start redis container: %v", err)
+	}
+	defer func() {
+		if err := redisContainer.Terminate(ctx); err != nil {
+			b.Logf("cannot stop redis container: %v", err)
+		}
+	}()
+	port, _ := redisContainer.MappedPort(ctx, "6379")
+	os.Setenv("REDIS_HOST", "127.0.0.1")
+	os.Setenv("REDIS_PASSWORD", "")
+	os.Setenv("REDIS_PORT", port.Port())
+	os.Setenv("SESSION_MANAGER_SECRET", "7CIuQStxETYG3x0qVO7TcZF7vUNnKlMz")
+	os.Setenv("SESSION_TIMEOUT", "1h")
+	os.Setenv("REFRESH_TOKEN_TIMEOUT", "1h")
+
+	redisCon := databaseRedis.GetRedisConn(ctx)
+	defer redisCon.Close()
+	sessionRepo := sessionRepository.NewRedisRepository(redisCon, encryptor.NewDefaultEncryptor())
+	sessionSrv := service.NewDefaultService(
+		sessionRepo,
+		time.Hour,
+		time.Hour,
+		os.Getenv("SESSION_MANAGER_SECRET"),
+		newPolicyService(b),
+		nil,
+		nil,
+	)
+
+	pipe := redisCon.Pipeline()
+	for i := 0; i < benchOtherUsers; i++ {
+		pipe.Set(ctx, fmt.Sprintf("session:other-%d", i), "", time.Hour)
+		pipe.Set(ctx, fmt.Sprintf("user_session:other-%d:other-%d", i, i), "", time.Hour)
+		pipe.ZAdd(ctx, fmt.Sprintf("user_sessions:other-%d", i), redis.Z{Score: 0, Member: fmt.Sprintf("other-%d", i)})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		b.Fatalf("cannot seed sessions: %v", err)
+	}
+
+	b.Run("keys", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			pipe := redisCon.Pipeline()
+			for j := 0; j < benchUserSessions; j++ {
+				pipe.Set(ctx, fmt.Sprintf("session:%d-%d", i, j), "", time.Hour)
+				pipe.Set(ctx, fmt.Sprintf("user_session:bench:%d-%d", i, j), "", time.Hour)
+			}
+			if _, err := pipe.Exec(ctx); err != nil {
+				b.Fatalf("cannot seed sessions: %v", err)
+			}
+			b.StartTimer()
+
+			if err := finishUserSessionsWithKeys(ctx, redisCon, "bench"); err != nil {
+				b.Fatalf("finishUserSessionsWithKeys() error = %v", err)
+			}
+		}
+	})
+
+	b.Run("set", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			for j := 0; j < benchUserSessions; j++ {
+				if _, _, err := sessionSrv.CreateSession(ctx, "bench", map[string]interface{}{}); err != nil {
+					b.Fatalf("CreateSession() error = %v", err)
+				}
+			}
+			b.StartTimer()
+
+			if err := sessionSrv.FinishUserSessions(ctx, "bench"); err != nil {
+				b.Fatalf("FinishUserSessions() error = %v", err)
+			}
+		}
+	})
+}
+
+// finishUserSessionsWithKeys is the former FinishUserSessions, which found the sessions of
+// the user and their refresh tokens with KEYS.
+func finishUserSessionsWithKeys(ctx context.Context, db *redis.Client, userId string) error {
+	prefix := fmt.Sprintf("user_session:%s:", userId)
+	userSessions, err := db.Keys(ctx, prefix+"*").Result()
+	if err != nil {
+		return err
+	}
+	pipe := db.TxPipeline()
+	for _, userSession := range userSessions {
+		sessionId, _ := strings.CutPrefix(userSession, prefix)
+		familyPrefix := fmt.Sprintf("refresh_family:%s:", sessionId)
+		familyTokens, err := db.Keys(ctx, familyPrefix+"*").Result()
+		if err != nil {
+			return err
+		}
+		pipe.Del(ctx, "session:"+sessionId, userSession)
+		for _, familyToken := range familyTokens {
+			tokenHash, _ := strings.CutPrefix(familyToken, familyPrefix)
+			pipe.Del(ctx, "refresh_token:"+tokenHash, familyToken)
+		}
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
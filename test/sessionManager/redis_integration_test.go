@@ -3,23 +3,37 @@ package sessionManager
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/gorilla/mux"
 	authModels "github.com/raffops/chat_auth/internal/app/auth/model"
+	"github.com/raffops/chat_auth/internal/app/authz"
+	authzModels "github.com/raffops/chat_auth/internal/app/authz/model"
+	authzService "github.com/raffops/chat_auth/internal/app/authz/service"
 	"github.com/raffops/chat_auth/internal/app/sessionManager"
+	sessionModels "github.com/raffops/chat_auth/internal/app/sessionManager/model"
 	sessionRepository "github.com/raffops/chat_auth/internal/app/sessionManager/repository"
 	"github.com/raffops/chat_auth/internal/app/sessionManager/service"
 	userModels "github.com/raffops/chat_auth/internal/app/user/models"
+	"github.com/raffops/chat_auth/internal/app/user/repository/migrations"
+	authzMock "github.com/raffops/chat_auth/test/mocks/authz"
 	grpcMock "github.com/raffops/chat_auth/test/mocks/grpc"
+	databasePostgres "github.com/raffops/chat_commons/pkg/database/postgres"
 	databaseRedis "github.com/raffops/chat_commons/pkg/database/redis"
 	"github.com/raffops/chat_commons/pkg/encryptor"
+	"github.com/raffops/chat_commons/pkg/errs"
 	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -31,6 +45,7 @@ type SessionManagerTestSuite struct {
 	suite.Suite
 	ctx                context.Context
 	secret             string
+	backend            string
 	redisCon           *redis.Client
 	defaultEncryptor   encryptor.Encryptor
 	sessionRepo        sessionManager.ReaderWriterRepository
@@ -43,35 +58,28 @@ type SessionManagerTestSuite struct {
 
 func (s *SessionManagerTestSuite) TestSetupSuite() {
 	s.ctx = context.Background()
-	redisContainer, err := databaseRedis.GetRedisTestContainer(s.ctx)
-	if err != nil {
-		log.Fatalf("cannot start redis container: %v", err)
-	}
-	defer func() {
-		err := redisContainer.Terminate(s.ctx)
-		if err != nil {
-			log.Printf("cannot stop redis container: %v", err)
-		}
-	}()
-	port, _ := redisContainer.MappedPort(s.ctx, "6379")
-	os.Setenv("REDIS_PORT", port.Port())
-
-	log.Printf("redis container started on port %s", port.Port())
-
-	s.redisCon = databaseRedis.GetRedisConn(s.ctx)
-	defer func() {
-		err := s.redisCon.Close()
-		if err != nil {
-			log.Printf("cannot close redis connection: %v", err)
-		}
-	}()
-
 	s.defaultEncryptor = encryptor.NewDefaultEncryptor()
-	s.sessionRepo = sessionRepository.NewRedisRepository(s.redisCon, s.defaultEncryptor)
+	switch s.backend {
+	case "memory":
+		s.sessionRepo = sessionRepository.NewMemoryRepository(s.defaultEncryptor)
+	case "postgres":
+		defer s.setupPostgres()()
+	default:
+		defer s.setupRedis()()
+	}
 
 	timeout, _ := time.ParseDuration(os.Getenv("SESSION_TIMEOUT"))
 	s.secret = os.Getenv("SESSION_MANAGER_SECRET")
-	s.sessionSrv = service.NewDefaultService(s.sessionRepo, timeout, s.secret)
+	refreshTimeout, _ := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TIMEOUT"))
+	s.sessionSrv = service.NewDefaultService(
+		s.sessionRepo,
+		timeout,
+		refreshTimeout,
+		s.secret,
+		newPolicyService(s.T()),
+		nil,
+		nil,
+	)
 
 	s.johnUser = userModels.User{
 		Id:       "1",
@@ -90,6 +98,21 @@ func (s *SessionManagerTestSuite) TestSetupSuite() {
 		s.T().Fatalf("createJohnSecondSession() failed")
 	}
 
+	success = s.Run("listJohnSessions", s.listJohnSessions)
+	if !success {
+		s.T().Fatalf("listJohnSessions() failed")
+	}
+
+	success = s.Run("revokeJohnSession", s.revokeJohnSession)
+	if !success {
+		s.T().Fatalf("revokeJohnSession() failed")
+	}
+
+	success = s.Run("limitAdminSessions", s.limitAdminSessions)
+	if !success {
+		s.T().Fatalf("limitAdminSessions() failed")
+	}
+
 	success = s.Run("checkInvalidSessionId", s.checkInvalidSessionId)
 	if !success {
 		s.T().Fatalf("checkInvalidSessionId() failed")
@@ -115,6 +138,11 @@ func (s *SessionManagerTestSuite) TestSetupSuite() {
 		s.T().Fatalf("CheckRestSession() failed")
 	}
 
+	success = s.Run("CheckRestSessionWithoutMfa", s.CheckRestSessionWithoutMfa)
+	if !success {
+		s.T().Fatalf("CheckRestSessionWithoutMfa() failed")
+	}
+
 	success = s.Run("CheckGrpcSessionValidToken", s.CheckGrpcSessionValidToken)
 	if !success {
 		s.T().Fatalf("CheckGrpcSessionValidToken() failed")
@@ -125,6 +153,11 @@ func (s *SessionManagerTestSuite) TestSetupSuite() {
 		s.T().Fatalf("CheckGrpcSessionValidTokenButInvalidRole() failed")
 	}
 
+	success = s.Run("CheckGrpcUnarySessionValidToken", s.CheckGrpcUnarySessionValidToken)
+	if !success {
+		s.T().Fatalf("CheckGrpcUnarySessionValidToken() failed")
+	}
+
 	success = s.Run("checkJohnFirstSessionWithExpiredTTL", s.checkJohnFirstSessionWithExpiredTTL)
 	if !success {
 		s.T().Fatalf("checkJohnFirstSessionWithExpiredTTL() failed")
@@ -139,6 +172,26 @@ func (s *SessionManagerTestSuite) TestSetupSuite() {
 		s.T().Fatalf("checkCorruptedSession() failed")
 	}
 
+	success = s.Run("expireAdminSessionAtMaxLifetime", s.expireAdminSessionAtMaxLifetime)
+	if !success {
+		s.T().Fatalf("expireAdminSessionAtMaxLifetime() failed")
+	}
+
+	success = s.Run("rotateJohnRefreshToken", s.rotateJohnRefreshToken)
+	if !success {
+		s.T().Fatalf("rotateJohnRefreshToken() failed")
+	}
+
+	success = s.Run("rotateJohnRefreshTokenConcurrently", s.rotateJohnRefreshTokenConcurrently)
+	if !success {
+		s.T().Fatalf("rotateJohnRefreshTokenConcurrently() failed")
+	}
+
+	success = s.Run("migrateSessionIndex", s.migrateSessionIndex)
+	if !success {
+		s.T().Fatalf("migrateSessionIndex() failed")
+	}
+
 	success = s.Run("TestCheckGrpcSession_MissingToken", s.CheckGrpcSessionMissingToken)
 	if !success {
 		s.T().Fatalf("TestCheckGrpcSession_MissingToken() failed")
@@ -150,6 +203,71 @@ func (s *SessionManagerTestSuite) TestSetupSuite() {
 	}
 }
 
+// setupRedis starts a Redis container for the repository, and returns the function
+// stopping it.
+func (s *SessionManagerTestSuite) setupRedis() func() {
+	redisContainer, err := databaseRedis.GetRedisTestContainer(s.ctx)
+	if err != nil {
+		log.Fatalf("cannot start redis container: %v", err)
+	}
+	port, _ := redisContainer.MappedPort(s.ctx, "6379")
+	os.Setenv("REDIS_PORT", port.Port())
+
+	log.Printf("redis container started on port %s", port.Port())
+
+	s.redisCon = databaseRedis.GetRedisConn(s.ctx)
+	s.sessionRepo = sessionRepository.NewRedisRepository(s.redisCon, s.defaultEncryptor)
+	return func() {
+		if err := s.redisCon.Close(); err != nil {
+			log.Printf("cannot close redis connection: %v", err)
+		}
+		if err := redisContainer.Terminate(s.ctx); err != nil {
+			log.Printf("cannot stop redis container: %v", err)
+		}
+	}
+}
+
+// setupPostgres starts a Postgres container with the migrations for the repository, and
+// returns the function stopping it.
+func (s *SessionManagerTestSuite) setupPostgres() func() {
+	os.Setenv("DB_DATABASE", "test")
+	os.Setenv("DB_USERNAME", "test")
+	os.Setenv("DB_PASSWORD", "test")
+	os.Setenv("DB_HOST", "localhost")
+	migrationFiles, err := migrations.GetMigrations()
+	if err != nil {
+		log.Fatalf("cannot get migrations: %v", err)
+	}
+	postgresContainer, err := databasePostgres.GetPostgresTestContainer(
+		s.ctx,
+		migrationFiles,
+		os.Getenv("DB_DATABASE"),
+		os.Getenv("DB_USERNAME"),
+		os.Getenv("DB_PASSWORD"),
+	)
+	if err != nil {
+		log.Fatalf("cannot start postgres container: %v", err)
+	}
+	port, _ := postgresContainer.MappedPort(s.ctx, "5432")
+	os.Setenv("DB_PORT", port.Port())
+
+	log.Printf("postgres container started on port %s", port.Port())
+
+	db, err := databasePostgres.GetPostgresConn(false)
+	if err != nil {
+		log.Fatalf("cannot connect to postgres: %v", err)
+	}
+	s.sessionRepo = sessionRepository.NewPostgresRepository(db, s.defaultEncryptor)
+	return func() {
+		if err := db.Close(); err != nil {
+			log.Printf("cannot close postgres connection: %v", err)
+		}
+		if err := postgresContainer.Terminate(s.ctx); err != nil {
+			log.Printf("cannot stop postgres container: %v", err)
+		}
+	}
+}
+
 func (s *SessionManagerTestSuite) createJohnFirstSession() {
 	id := s.johnUser.Id
 	payload := map[string]interface{}{
@@ -159,7 +277,7 @@ func (s *SessionManagerTestSuite) createJohnFirstSession() {
 		"user_id":   id,
 	}
 	payloadString, _ := json.Marshal(payload)
-	got, err := s.sessionSrv.CreateSession(s.ctx, id, payload)
+	got, _, err := s.sessionSrv.CreateSession(s.ctx, id, payload)
 	if got == "" {
 		s.T().Fatalf("CreateSession() got = %v, want not empty", got)
 	}
@@ -185,7 +303,7 @@ func (s *SessionManagerTestSuite) createJohnSecondSession() {
 		"user_id":   id,
 	}
 	payloadString, _ := json.Marshal(payload)
-	got, err := s.sessionSrv.CreateSession(s.ctx, id, payload)
+	got, _, err := s.sessionSrv.CreateSession(s.ctx, id, payload)
 	if got == "" {
 		s.T().Fatalf("CreateSession() got = %v, want not empty", got)
 	}
@@ -202,6 +320,155 @@ func (s *SessionManagerTestSuite) createJohnSecondSession() {
 	s.johnSecondSession = got
 }
 
+func (s *SessionManagerTestSuite) listJohnSessions() {
+	sessions, err := s.sessionSrv.ListUserSessions(s.ctx, s.johnUser.Id)
+	if err != nil {
+		s.T().Fatalf("ListUserSessions() error = %v", err)
+	}
+	if len(sessions) != 2 {
+		s.T().Fatalf("ListUserSessions() got = %v sessions, want 2", len(sessions))
+	}
+	handles := []string{
+		sessionModels.SessionHandle(s.johnFirstSession),
+		sessionModels.SessionHandle(s.johnSecondSession),
+	}
+	for _, session := range sessions {
+		s.Contains(handles, session.Id)
+		s.Equal(s.johnUser.Id, session.UserId)
+		s.NotNil(session.CreatedAt)
+		s.True(session.ExpiresAt.After(time.Now()))
+	}
+}
+
+func (s *SessionManagerTestSuite) revokeJohnSession() {
+	payload := map[string]interface{}{"role": int(s.johnUser.Role)}
+	sessionId, _, err := s.sessionSrv.CreateSession(s.ctx, s.johnUser.Id, payload)
+	if err != nil {
+		s.T().Fatalf("CreateSession() error = %v", err)
+	}
+
+	handle := sessionModels.SessionHandle(sessionId)
+	err = s.sessionSrv.FinishUserSession(s.ctx, "2", handle)
+	if err == nil || !errors.Is(err.SvcError(), errs.ErrNotFound) {
+		s.T().Fatalf("FinishUserSession() of another user error = %v, want %v", err, errs.ErrNotFound)
+	}
+	err = s.sessionSrv.FinishUserSession(s.ctx, s.johnUser.Id, sessionId)
+	if err == nil || !errors.Is(err.SvcError(), errs.ErrNotFound) {
+		s.T().Fatalf("FinishUserSession() by session id error = %v, want %v", err, errs.ErrNotFound)
+	}
+	err = s.sessionSrv.FinishUserSession(s.ctx, s.johnUser.Id, handle)
+	if err != nil {
+		s.T().Fatalf("FinishUserSession() error = %v", err)
+	}
+
+	sessions, err := s.sessionSrv.ListUserSessions(s.ctx, s.johnUser.Id)
+	if err != nil {
+		s.T().Fatalf("ListUserSessions() error = %v", err)
+	}
+	for _, session := range sessions {
+		s.NotEqual(handle, session.Id)
+	}
+}
+
+func (s *SessionManagerTestSuite) limitAdminSessions() {
+	adminId := "4"
+	payload := func() map[string]interface{} {
+		return map[string]interface{}{"role": authModels.RoleAdmin}
+	}
+	evictSrv := s.newAdminService(
+		s.sessionRepo,
+		sessionModels.Limit{Max: 1, Mode: sessionModels.LimitModeEvict},
+		sessionModels.Expiration{},
+	)
+	firstSession, evicted, err := evictSrv.CreateSession(s.ctx, adminId, payload())
+	if err != nil || len(evicted) != 0 {
+		s.T().Fatalf("CreateSession() evicted = %v, error = %v, want none", evicted, err)
+	}
+	secondSession, evicted, err := evictSrv.CreateSession(s.ctx, adminId, payload())
+	if err != nil {
+		s.T().Fatalf("CreateSession() error = %v", err)
+	}
+	s.Equal([]string{firstSession}, evicted)
+	_, err = s.sessionSrv.GetSession(s.ctx, firstSession)
+	if err == nil {
+		s.T().Fatalf("GetSession() of the evicted session got nil error")
+	}
+
+	repo := &writeRecordingRepository{ReaderWriterRepository: s.sessionRepo, table: "session"}
+	rejectSrv := s.newAdminService(
+		repo,
+		sessionModels.Limit{Max: 1, Mode: sessionModels.LimitModeReject},
+		sessionModels.Expiration{},
+	)
+	rejectedSession, _, err := rejectSrv.CreateSession(s.ctx, adminId, payload())
+	if err == nil || !errors.Is(err.SvcError(), errs.ErrConflict) {
+		s.T().Fatalf("CreateSession() over the limit error = %v, want %v", err, errs.ErrConflict)
+	}
+	if slices.Contains(repo.written, rejectedSession) {
+		s.T().Fatalf("CreateSession() over the limit wrote the rejected session")
+	}
+	sessions, err := s.sessionSrv.ListUserSessions(s.ctx, adminId)
+	if err != nil || len(sessions) != 1 || sessions[0].Id != sessionModels.SessionHandle(secondSession) {
+		s.T().Fatalf("ListUserSessions() got = %v, error = %v, want only %v", sessions, err, secondSession)
+	}
+}
+
+func (s *SessionManagerTestSuite) expireAdminSessionAtMaxLifetime() {
+	adminSrv := s.newAdminService(
+		s.sessionRepo,
+		sessionModels.Limit{},
+		sessionModels.Expiration{Idle: time.Minute, MaxLifetime: 2 * time.Second},
+	)
+	maxExpiresAt := time.Now().Add(3 * time.Second)
+	sessionId, _, err := adminSrv.CreateSession(s.ctx, "5", map[string]interface{}{"role": authModels.RoleAdmin})
+	if err != nil {
+		s.T().Fatalf("CreateSession() error = %v", err)
+	}
+
+	err = adminSrv.RefreshSession(s.ctx, sessionId)
+	if err != nil {
+		s.T().Fatalf("RefreshSession() error = %v", err)
+	}
+	expiresAt, _ := s.sessionRepo.GetTTL(s.ctx, "session", sessionId)
+	if expiresAt.After(maxExpiresAt) {
+		s.T().Fatalf("RefreshSession() expires at %v, want before the max lifetime %v", expiresAt, maxExpiresAt)
+	}
+
+	time.Sleep(time.Until(maxExpiresAt))
+	_, err = adminSrv.GetSession(s.ctx, sessionId)
+	if err == nil {
+		s.T().Fatalf("GetSession() after the max lifetime got nil error")
+	}
+}
+
+// newAdminService returns a session service with the given session limit and expiration
+// for admins, left out when zero.
+func (s *SessionManagerTestSuite) newAdminService(
+	repo sessionManager.ReaderWriterRepository,
+	limit sessionModels.Limit,
+	expiration sessionModels.Expiration,
+) sessionManager.Service {
+	timeout, _ := time.ParseDuration(os.Getenv("SESSION_TIMEOUT"))
+	refreshTimeout, _ := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TIMEOUT"))
+	limits := map[authModels.RoleId]sessionModels.Limit{}
+	if limit != (sessionModels.Limit{}) {
+		limits[authModels.RoleAdmin] = limit
+	}
+	expirations := map[authModels.RoleId]sessionModels.Expiration{}
+	if expiration != (sessionModels.Expiration{}) {
+		expirations[authModels.RoleAdmin] = expiration
+	}
+	return service.NewDefaultService(
+		repo,
+		timeout,
+		refreshTimeout,
+		s.secret,
+		newPolicyService(s.T()),
+		limits,
+		expirations,
+	)
+}
+
 func (s *SessionManagerTestSuite) checkInvalidSessionId() {
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/", nil)
@@ -275,6 +542,8 @@ func (s *SessionManagerTestSuite) checkJohnFirstSessionWithExpiredTTL() {
 	}
 	router := mux.NewRouter()
 	router.HandleFunc("/", s.sessionSrv.CheckRestSession(f, []authModels.RoleId{authModels.RoleUser}))
+	// The calls of the previous steps slid the idle timeout past the refresh.
+	s.johnSessionTimeout, _ = s.sessionRepo.GetTTL(s.ctx, "session", s.johnFirstSession)
 	time.Sleep(time.Until(s.johnSessionTimeout.Add(time.Duration(1) * time.Second)))
 
 	router.ServeHTTP(w, r)
@@ -308,6 +577,8 @@ func (s *SessionManagerTestSuite) refreshJohnFirstSession() {
 }
 
 func (s *SessionManagerTestSuite) CheckRestSession() {
+	timeoutBefore, _ := s.sessionRepo.GetTTL(s.ctx, "session", s.johnFirstSession)
+	time.Sleep(time.Second)
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/", nil)
 	r.Header.Set("Authorization", "Bearer "+s.johnFirstSession)
@@ -321,6 +592,23 @@ func (s *SessionManagerTestSuite) CheckRestSession() {
 	if w.Body.String() != "Hello user" {
 		s.T().Fatalf("CheckRestSession() got = %v, want %v", w.Body.String(), "Hello user")
 	}
+	timeoutAfter, _ := s.sessionRepo.GetTTL(s.ctx, "session", s.johnFirstSession)
+	if !timeoutAfter.After(timeoutBefore) {
+		s.T().Fatalf("CheckRestSession() expires at %v, want after %v", timeoutAfter, timeoutBefore)
+	}
+}
+
+func (s *SessionManagerTestSuite) CheckRestSessionWithoutMfa() {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer "+s.johnFirstSession)
+	router := mux.NewRouter()
+	router.HandleFunc("/", s.sessionSrv.CheckRestSessionWithMfa(HelloWorldUser, []authModels.RoleId{s.johnUser.Role}))
+	router.ServeHTTP(w, r)
+
+	if w.Code != http.StatusForbidden {
+		s.T().Fatalf("CheckRestSessionWithMfa() got = %v, want %v", w.Code, http.StatusForbidden)
+	}
 }
 
 func (s *SessionManagerTestSuite) UseJohnFirstSessionAfterTimeout() {
@@ -340,7 +628,17 @@ func (s *SessionManagerTestSuite) UseJohnFirstSessionAfterTimeout() {
 }
 
 func (s *SessionManagerTestSuite) checkCorruptedSession() {
-	s.redisCon.Set(s.ctx, "session:"+s.johnSecondSession, "corrupted", 0)
+	if s.redisCon != nil {
+		s.redisCon.Set(s.ctx, "session:"+s.johnSecondSession, "corrupted", 0)
+	} else {
+		tx, _ := s.sessionRepo.BeginTransaction(s.ctx)
+		_ = s.sessionRepo.HashSet(s.ctx, tx, "session", s.johnSecondSession, map[string]interface{}{
+			"encrypted_value": "corrupted",
+		})
+		if err := s.sessionRepo.CommitTransaction(s.ctx, tx); err != nil {
+			s.T().Fatalf("CommitTransaction() error = %v", err)
+		}
+	}
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/", nil)
 	r.Header.Set("Authorization", "Bearer "+s.johnSecondSession)
@@ -356,6 +654,156 @@ func (s *SessionManagerTestSuite) checkCorruptedSession() {
 	}
 }
 
+func (s *SessionManagerTestSuite) rotateJohnRefreshToken() {
+	id := s.johnUser.Id
+	sessionId, _, err := s.sessionSrv.CreateSession(s.ctx, id, map[string]interface{}{"role": int(s.johnUser.Role)})
+	if err != nil {
+		s.T().Fatalf("CreateSession() error = %v", err)
+	}
+	firstRefreshToken, err := s.sessionSrv.CreateRefreshToken(s.ctx, id, sessionId)
+	if err != nil {
+		s.T().Fatalf("CreateRefreshToken() error = %v", err)
+	}
+
+	gotSessionId, secondRefreshToken, err := s.sessionSrv.RotateRefreshToken(s.ctx, firstRefreshToken)
+	if err != nil {
+		s.T().Fatalf("RotateRefreshToken() error = %v", err)
+	}
+	s.Equal(sessionId, gotSessionId)
+	s.NotEqual(firstRefreshToken, secondRefreshToken)
+
+	_, _, err = s.sessionSrv.RotateRefreshToken(s.ctx, firstRefreshToken)
+	if err == nil {
+		s.T().Fatalf("RotateRefreshToken() with a rotated token got nil error")
+	}
+	_, _, err = s.sessionSrv.RotateRefreshToken(s.ctx, secondRefreshToken)
+	if err == nil {
+		s.T().Fatalf("RotateRefreshToken() after reuse got nil error, want the family revoked")
+	}
+	_, err = s.sessionSrv.GetSession(s.ctx, sessionId)
+	if err == nil {
+		s.T().Fatalf("GetSession() after reuse got nil error, want the session revoked")
+	}
+}
+
+// rotateJohnRefreshTokenConcurrently exchanges the same refresh token concurrently, as a
+// replayed token would be. Every exchange reads the token before any of them writes, and
+// at most one exchange must succeed while the others revoke the session.
+func (s *SessionManagerTestSuite) rotateJohnRefreshTokenConcurrently() {
+	const exchanges = 5
+	id := s.johnUser.Id
+	repo := &readBarrierRepository{ReaderWriterRepository: s.sessionRepo, table: "refresh_token"}
+	timeout, _ := time.ParseDuration(os.Getenv("SESSION_TIMEOUT"))
+	refreshTimeout, _ := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TIMEOUT"))
+	sessionSrv := service.NewDefaultService(
+		repo,
+		timeout,
+		refreshTimeout,
+		s.secret,
+		newPolicyService(s.T()),
+		nil,
+		nil,
+	)
+	sessionId, _, err := sessionSrv.CreateSession(s.ctx, id, map[string]interface{}{"role": int(s.johnUser.Role)})
+	if err != nil {
+		s.T().Fatalf("CreateSession() error = %v", err)
+	}
+	refreshToken, err := sessionSrv.CreateRefreshToken(s.ctx, id, sessionId)
+	if err != nil {
+		s.T().Fatalf("CreateRefreshToken() error = %v", err)
+	}
+
+	repo.reads.Add(exchanges)
+	var wg sync.WaitGroup
+	var rotated atomic.Int32
+	for range exchanges {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, errRotate := sessionSrv.RotateRefreshToken(s.ctx, refreshToken); errRotate == nil {
+				rotated.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.LessOrEqual(rotated.Load(), int32(1))
+	_, err = sessionSrv.GetSession(s.ctx, sessionId)
+	if err == nil {
+		s.T().Fatalf("GetSession() after concurrent exchanges got nil error, want the session revoked")
+	}
+}
+
+// readBarrierRepository holds the encrypted reads of a table until every read added to reads
+// is made, so concurrent callers all read before any of them writes.
+type readBarrierRepository struct {
+	sessionManager.ReaderWriterRepository
+	table string
+	reads sync.WaitGroup
+}
+
+func (r *readBarrierRepository) HashGetEncrypted(
+	ctx context.Context,
+	tableName, key, secret string,
+) (map[string]interface{}, errs.ChatError) {
+	values, err := r.ReaderWriterRepository.HashGetEncrypted(ctx, tableName, key, secret)
+	if tableName == r.table {
+		r.reads.Done()
+		r.reads.Wait()
+	}
+	return values, err
+}
+
+// writeRecordingRepository records the keys of a table written with encrypted values.
+type writeRecordingRepository struct {
+	sessionManager.ReaderWriterRepository
+	table   string
+	written []string
+}
+
+func (r *writeRecordingRepository) HashSetEncrypted(
+	ctx context.Context,
+	tx interface{},
+	tableName, key, secret string,
+	values map[string]interface{},
+) errs.ChatError {
+	if tableName == r.table {
+		r.written = append(r.written, key)
+	}
+	return r.ReaderWriterRepository.HashSetEncrypted(ctx, tx, tableName, key, secret, values)
+}
+
+func (s *SessionManagerTestSuite) migrateSessionIndex() {
+	if s.redisCon == nil {
+		s.T().Skip("the migration only applies to Redis")
+	}
+	s.redisCon.Set(s.ctx, "session:legacy", "", time.Minute)
+	s.redisCon.Set(s.ctx, "user_session:3:legacy", "", time.Minute)
+	s.redisCon.Set(s.ctx, "refresh_family:legacy:hash", "", time.Minute)
+
+	migrated, err := sessionRepository.MigrateSessionIndex(s.ctx, s.redisCon)
+	if err != nil {
+		s.T().Fatalf("MigrateSessionIndex() error = %v", err)
+	}
+	s.GreaterOrEqual(migrated, 2)
+
+	sessions, err := s.sessionSrv.ListUserSessions(s.ctx, "3")
+	if err != nil {
+		s.T().Fatalf("ListUserSessions() error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Id != sessionModels.SessionHandle("legacy") || sessions[0].CreatedAt != nil {
+		s.T().Fatalf("ListUserSessions() got = %v, want the legacy session without metadata", sessions)
+	}
+	s.Equal(int64(0), s.redisCon.Exists(s.ctx, "refresh_family:legacy:hash").Val())
+	s.Equal([]string{"hash"}, s.redisCon.ZRange(s.ctx, "refresh_family:legacy", 0, -1).Val())
+
+	err = s.sessionSrv.FinishUserSessions(s.ctx, "3")
+	if err != nil {
+		s.T().Fatalf("FinishUserSessions() error = %v", err)
+	}
+	s.Equal(int64(0), s.redisCon.Exists(s.ctx, "session:legacy", "user_sessions:3", "refresh_family:legacy").Val())
+}
+
 func (s *SessionManagerTestSuite) CheckGrpcSessionMissingToken() {
 	md := metadata.New(map[string]string{})
 	ctx := metadata.NewIncomingContext(context.Background(), md)
@@ -397,8 +845,13 @@ func (s *SessionManagerTestSuite) CheckGrpcSessionValidToken() {
 	ss.EXPECT().Context().Return(ctx)
 	info := &grpc.StreamServerInfo{}
 	info.FullMethod = "/test"
-	s.sessionSrv.SetRoles("/test", []authModels.RoleId{s.johnUser.Role})
+	errSetRoles := s.sessionSrv.SetRoles(s.ctx, "/test", []authModels.RoleId{s.johnUser.Role})
+	s.Nil(errSetRoles)
 	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		principal, ok := sessionModels.PrincipalFromContext(stream.Context())
+		s.True(ok)
+		s.Equal(s.johnUser.Id, principal.UserId)
+		s.Equal(s.johnFirstSession, principal.SessionId)
 		return nil
 	}
 
@@ -406,6 +859,30 @@ func (s *SessionManagerTestSuite) CheckGrpcSessionValidToken() {
 	s.Equal(nil, err)
 }
 
+func (s *SessionManagerTestSuite) CheckGrpcUnarySessionValidToken() {
+	md := metadata.New(map[string]string{
+		"authorization": s.johnFirstSession,
+	})
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	info := &grpc.UnaryServerInfo{FullMethod: "/test"}
+	errSetRoles := s.sessionSrv.SetRoles(s.ctx, "/test", []authModels.RoleId{s.johnUser.Role})
+	s.Nil(errSetRoles)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		principal, ok := sessionModels.PrincipalFromContext(ctx)
+		s.True(ok)
+		s.Equal(s.johnUser.Id, principal.UserId)
+		s.Equal(s.johnUser.Role, principal.Role)
+		return "ok", nil
+	}
+
+	response, err := s.sessionSrv.CheckGrpcUnarySession(ctx, nil, info, handler)
+	s.Nil(err)
+	s.Equal("ok", response)
+
+	_, err = s.sessionSrv.CheckGrpcUnarySession(context.Background(), nil, info, handler)
+	s.Equal(codes.PermissionDenied, status.Code(err))
+}
+
 func (s *SessionManagerTestSuite) CheckGrpcSessionValidTokenButInvalidRole() {
 	md := metadata.New(map[string]string{
 		"authorization": s.johnFirstSession,
@@ -415,7 +892,8 @@ func (s *SessionManagerTestSuite) CheckGrpcSessionValidTokenButInvalidRole() {
 	ss.EXPECT().Context().Return(ctx)
 	info := &grpc.StreamServerInfo{}
 	info.FullMethod = "/test"
-	s.sessionSrv.SetRoles("/test", []authModels.RoleId{authModels.RoleAdmin})
+	errSetRoles := s.sessionSrv.SetRoles(s.ctx, "/test", []authModels.RoleId{authModels.RoleAdmin})
+	s.Nil(errSetRoles)
 	handler := func(srv interface{}, stream grpc.ServerStream) error {
 		return nil
 	}
@@ -431,10 +909,58 @@ func TestSessionManager(t *testing.T) {
 	os.Setenv("REDIS_PORT", "6379")
 	os.Setenv("SESSION_MANAGER_SECRET", "7CIuQStxETYG3x0qVO7TcZF7vUNnKlMz")
 	os.Setenv("SESSION_TIMEOUT", "3s")
+	os.Setenv("REFRESH_TOKEN_TIMEOUT", "10s")
 	suite.Run(t, new(SessionManagerTestSuite))
 }
 
+// TestSessionManagerInMemory runs the suite against the in-memory repository, which must
+// behave as the Redis one.
+func TestSessionManagerInMemory(t *testing.T) {
+	os.Setenv("SESSION_MANAGER_SECRET", "7CIuQStxETYG3x0qVO7TcZF7vUNnKlMz")
+	os.Setenv("SESSION_TIMEOUT", "3s")
+	os.Setenv("REFRESH_TOKEN_TIMEOUT", "10s")
+	suite.Run(t, &SessionManagerTestSuite{backend: "memory"})
+}
+
+// TestSessionManagerPostgres runs the suite against the Postgres repository.
+func TestSessionManagerPostgres(t *testing.T) {
+	os.Setenv("SESSION_MANAGER_SECRET", "7CIuQStxETYG3x0qVO7TcZF7vUNnKlMz")
+	os.Setenv("SESSION_TIMEOUT", "3s")
+	os.Setenv("REFRESH_TOKEN_TIMEOUT", "10s")
+	suite.Run(t, &SessionManagerTestSuite{backend: "postgres"})
+}
+
+// newPolicyService returns a policy service that denies unknown methods, backed by an
+// in-memory policy repository.
+func newPolicyService(t testing.TB) authz.PolicyService {
+	os.Setenv("GRPC_DEFAULT_POLICY", "deny")
+	os.Setenv("POLICY_RELOAD_INTERVAL", "1m")
+	policies := map[string][]authModels.RoleId{}
+	policyRepo := authzMock.NewPolicyRepository(t)
+	policyRepo.EXPECT().ListPolicies(mock.Anything).RunAndReturn(
+		func(ctx context.Context) (map[string][]authModels.RoleId, errs.ChatError) {
+			return maps.Clone(policies), nil
+		},
+	).Maybe()
+	policyRepo.EXPECT().SetPolicy(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(
+		func(ctx context.Context, method string, roles []authModels.RoleId) errs.ChatError {
+			policies[method] = roles
+			return nil
+		},
+	).Maybe()
+
+	policySrv, err := authzService.NewPolicyService(context.Background(), policyRepo, authzModels.DefaultPolicyDeny)
+	if err != nil {
+		t.Fatalf("NewPolicyService() error = %v", err)
+	}
+	return policySrv
+}
+
 func HelloWorldUser(w http.ResponseWriter, r *http.Request) {
+	if _, ok := sessionModels.PrincipalFromContext(r.Context()); !ok {
+		http.Error(w, "missing principal", http.StatusInternalServerError)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Hello user"))
 }
@@ -62,17 +62,11 @@ var (
 		UpdatedAt: RandomTime,
 	}
 	UserTomDoe = userModels.User{
-		Username: "tom",
-		Email:    "tom@doe",
-		AuthType: userModels.AuthTypeGoogle,
-		Role:     auth.RoleAdmin,
-		Status:   userModels.StatusActive,
-		LoginHistory: []userModels.LoginHistory{
-			{
-				LoginTime:  RandomTime,
-				LogoutTime: RandomTime,
-			},
-		},
+		Username:  "tom",
+		Email:     "tom@doe",
+		AuthType:  userModels.AuthTypeGoogle,
+		Role:      auth.RoleAdmin,
+		Status:    userModels.StatusActive,
 		CreatedAt: RandomTime,
 		UpdatedAt: RandomTime,
 	}
@@ -124,6 +118,53 @@ func TestMain(m *testing.M) {
 	m.Run()
 }
 
+func TestIdentityRepository(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.GetPostgresConn(false)
+	if err != nil {
+		t.Fatalf("Error getting postgres connection: %v", err)
+	}
+	p := userRepo.NewIdentityRepository(db)
+
+	identity, errIdentity := p.CreateIdentity(ctx, nil, userModels.Identity{
+		UserId:   UserJaneDoe.Id,
+		Provider: "github",
+		Subject:  "583231",
+		Email:    "jane@doe",
+	})
+	if errIdentity != nil || identity.Id == 0 || identity.LinkedAt.IsZero() {
+		t.Fatalf("CreateIdentity() got = %v, error = %v", identity, errIdentity)
+	}
+	got, errIdentity := p.GetIdentity(ctx, "github", "583231")
+	if errIdentity != nil || !reflect.DeepEqual(got, identity) {
+		t.Errorf("GetIdentity() got = %v, error = %v, want %v", got, errIdentity, identity)
+	}
+
+	conflicts := []userModels.Identity{
+		{UserId: UserJonhDoe.Id, Provider: "github", Subject: "583231"},
+		{UserId: UserJaneDoe.Id, Provider: "github", Subject: "1"},
+	}
+	for _, conflict := range conflicts {
+		_, errIdentity = p.CreateIdentity(ctx, nil, conflict)
+		if errIdentity == nil || !errors.Is(errIdentity.SvcError(), errs.ErrConflict) {
+			t.Errorf("CreateIdentity(%v) error = %v, want %v", conflict, errIdentity, errs.ErrConflict)
+		}
+	}
+
+	errIdentity = p.DeleteIdentity(ctx, UserJonhDoe.Id, identity.Id)
+	if errIdentity == nil || !errors.Is(errIdentity.SvcError(), errs.ErrNotFound) {
+		t.Errorf("DeleteIdentity() of another user error = %v, want %v", errIdentity, errs.ErrNotFound)
+	}
+	errIdentity = p.DeleteIdentity(ctx, UserJaneDoe.Id, identity.Id)
+	if errIdentity != nil {
+		t.Fatalf("DeleteIdentity() error = %v", errIdentity)
+	}
+	identities, errIdentity := p.ListIdentities(ctx, UserJaneDoe.Id)
+	if errIdentity != nil || len(identities) != 0 {
+		t.Errorf("ListIdentities() got = %v, error = %v, want none", identities, errIdentity)
+	}
+}
+
 func TestPostgresRepository_GetUser(t *testing.T) {
 	type args struct {
 		ctx   context.Context
@@ -187,7 +228,7 @@ func TestPostgresRepository_GetUser(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			p := userRepo.NewPostgresUserRepository(db)
-			got, err := p.GetUser(tt.args.ctx, tt.args.key, tt.args.value)
+			got, err := p.GetUser(tt.args.ctx, tt.args.key, tt.args.value, false)
 			if !reflect.DeepEqual(err, tt.wantErr) {
 				t.Errorf("GetUser()\n\terror = %v\n\twantErr = %v", err, tt.wantErr)
 				return
@@ -200,6 +241,44 @@ func TestPostgresRepository_GetUser(t *testing.T) {
 	}
 }
 
+func TestPostgresRepository_GetPasswordHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		userId  string
+		wantErr errs.ChatError
+	}{
+		{
+			name:   "Test GetPasswordHash with user without password",
+			userId: UserJaneDoe.Id,
+			wantErr: errs.NewError(
+				errs.ErrNotFound,
+				fmt.Errorf("user with id=%s has no password", UserJaneDoe.Id),
+			),
+		},
+		{
+			name:   "Test GetPasswordHash with not found user",
+			userId: "00000000-0000-0000-0000-000000000000",
+			wantErr: errs.NewError(
+				errs.ErrNotFound,
+				fmt.Errorf("user with %s=%s not found", "id", "00000000-0000-0000-0000-000000000000"),
+			),
+		},
+	}
+	db, err := database.GetPostgresConn(false)
+	if err != nil {
+		t.Fatalf("Error getting postgres connection: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := userRepo.NewPostgresUserRepository(db)
+			_, err := p.GetPasswordHash(context.Background(), tt.userId)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("GetPasswordHash()\n\terror = %v\n\twantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestPostgresRepository_CreateUser(t *testing.T) {
 	type args struct {
 		u userModels.User
@@ -342,19 +421,36 @@ func TestPostgresRepository_DeleteUser(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Error committing transaction: %v", err)
 			}
-			if got.DeletedAt.IsZero() {
+			if got.DeletedAt.IsZero() || got.Status != userModels.StatusInactive {
 				t.Errorf("DeleteUser() got = %v, want %v", got, tt.args.u)
 			}
+
+			_, errGet := p.GetUser(ctx, "id", tt.args.u.Id, false)
+			if errGet == nil || !errors.Is(errGet.SvcError(), errs.ErrNotFound) {
+				t.Errorf("GetUser() of deleted user error = %v, want not found", errGet)
+			}
+			deleted, errGet := p.GetUser(ctx, "id", tt.args.u.Id, true)
+			if errGet != nil || deleted.DeletedAt.IsZero() {
+				t.Errorf("GetUser() including deleted got = %v, error = %v", deleted, errGet)
+			}
+
+			tx, _ = db.BeginTx(ctx, nil)
+			defer tx.Rollback()
+			_, errDelete := p.DeleteUser(ctx, tx, tt.args.u)
+			if errDelete == nil || !errors.Is(errDelete.SvcError(), errs.ErrNotFound) {
+				t.Errorf("DeleteUser() twice error = %v, want not found", errDelete)
+			}
 		})
 	}
 }
 
 func TestPostgresRepository_ListUsers(t *testing.T) {
 	type args struct {
-		columns []string
-		filters []userModels.Filter
-		sorts   []userModels.Sort
-		page    userModels.Pagination
+		columns        []string
+		filters        []userModels.Filter
+		sorts          []userModels.Sort
+		page           userModels.Pagination
+		includeDeleted bool
 	}
 	tests := []struct {
 		name       string
@@ -373,6 +469,20 @@ func TestPostgresRepository_ListUsers(t *testing.T) {
 					Limit:  10,
 				},
 			},
+			wantLength: 3,
+		},
+		{
+			name: "Test ListUsers including deleted users",
+			args: args{
+				columns: []string{"id", "username"},
+				filters: []userModels.Filter{},
+				sorts:   []userModels.Sort{},
+				page: userModels.Pagination{
+					Offset: 0,
+					Limit:  10,
+				},
+				includeDeleted: true,
+			},
 			wantLength: 4,
 		},
 	}
@@ -384,7 +494,14 @@ func TestPostgresRepository_ListUsers(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			p := userRepo.NewPostgresUserRepository(db)
-			got, err := p.ListUsers(ctx, tt.args.columns, tt.args.filters, tt.args.sorts, tt.args.page)
+			got, err := p.ListUsers(
+				ctx,
+				tt.args.columns,
+				tt.args.filters,
+				tt.args.sorts,
+				tt.args.page,
+				tt.args.includeDeleted,
+			)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ListUsers() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -396,12 +513,83 @@ func TestPostgresRepository_ListUsers(t *testing.T) {
 	}
 }
 
+func TestPostgresRepository_CountUsers(t *testing.T) {
+	db, err := database.GetPostgresConn(false)
+	if err != nil {
+		t.Fatalf("Error getting postgres connection: %v", err)
+	}
+	p := userRepo.NewPostgresUserRepository(db)
+	got, errCount := p.CountUsers(context.Background(), []userModels.Filter{}, false)
+	if errCount != nil {
+		t.Fatalf("CountUsers() error = %v", errCount)
+	}
+	if got != 3 {
+		t.Errorf("CountUsers() got = %v, want %v", got, 3)
+	}
+	got, errCount = p.CountUsers(context.Background(), []userModels.Filter{}, true)
+	if errCount != nil {
+		t.Fatalf("CountUsers() error = %v", errCount)
+	}
+	if got != 4 {
+		t.Errorf("CountUsers() including deleted got = %v, want %v", got, 4)
+	}
+}
+
+func TestLoginRepository(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.GetPostgresConn(false)
+	if err != nil {
+		t.Fatalf("Error getting postgres connection: %v", err)
+	}
+	p := userRepo.NewLoginRepository(db)
+
+	login, errLogin := p.RecordLogin(ctx, userModels.Login{
+		UserId:        UserJaneDoe.Id,
+		SessionHandle: "session-3",
+		Provider:      "github",
+		Ip:            "10.0.0.2",
+		LoginTime:     time.Now().UTC().Truncate(time.Microsecond),
+	})
+	if errLogin != nil || login.Id == 0 {
+		t.Fatalf("RecordLogin() got = %v, error = %v", login, errLogin)
+	}
+
+	logins, errLogin := p.ListLogins(ctx, UserJaneDoe.Id, userModels.Pagination{Limit: 2})
+	if errLogin != nil {
+		t.Fatalf("ListLogins() error = %v", errLogin)
+	}
+	if len(logins) != 2 || !reflect.DeepEqual(logins[0], login) || logins[1].SessionHandle != "session-2" {
+		t.Errorf("ListLogins() got = %v, want the new login and session-2", logins)
+	}
+	total, errLogin := p.CountLogins(ctx, UserJaneDoe.Id)
+	if errLogin != nil || total != 3 {
+		t.Errorf("CountLogins() got = %v, error = %v, want %v", total, errLogin, 3)
+	}
+
+	errLogin = p.CloseLogins(ctx, []string{"session-1", "session-3"}, time.Now())
+	if errLogin != nil {
+		t.Fatalf("CloseLogins() error = %v", errLogin)
+	}
+	open, errLogin := p.ListOpenLogins(ctx, 0, 100)
+	if errLogin != nil {
+		t.Fatalf("ListOpenLogins() error = %v", errLogin)
+	}
+	if len(open) != 1 || open[0].SessionHandle != "session-2" {
+		t.Errorf("ListOpenLogins() got = %v, want only session-2", open)
+	}
+	closed, _ := p.ListLogins(ctx, UserJaneDoe.Id, userModels.Pagination{Limit: 3})
+	if closed[2].LogoutTime == nil || !closed[2].LogoutTime.Equal(time.Date(2024, 5, 7, 13, 27, 58, 0, time.UTC)) {
+		t.Errorf("CloseLogins() changed the logout time of session-1 to %v", closed[2].LogoutTime)
+	}
+}
+
 func Test_buildListQuery(t *testing.T) {
 	type args struct {
-		columns []string
-		filters []userModels.Filter
-		sorts   []userModels.Sort
-		page    userModels.Pagination
+		columns        []string
+		filters        []userModels.Filter
+		sorts          []userModels.Sort
+		page           userModels.Pagination
+		includeDeleted bool
 	}
 	tests := []struct {
 		name    string
@@ -426,14 +614,33 @@ func Test_buildListQuery(t *testing.T) {
 					Offset: 0,
 					Limit:  10,
 				},
+				includeDeleted: true,
 			},
 			want:    "SELECT id FROM public.user WHERE role = $1 ORDER BY created_at DESC LIMIT 10 OFFSET 0",
 			wantErr: false,
 		},
+		{
+			name: "Test buildListQuery without deleted users",
+			args: args{
+				columns: []string{"id"},
+				page: userModels.Pagination{
+					Offset: 0,
+					Limit:  10,
+				},
+			},
+			want:    "SELECT id FROM public.user WHERE deleted_at IS NULL ORDER BY created_at DESC LIMIT 10 OFFSET 0",
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, _, err := userRepo.BuildListQuery(tt.args.columns, tt.args.filters, tt.args.sorts, tt.args.page)
+			got, _, err := userRepo.BuildListQuery(
+				tt.args.columns,
+				tt.args.filters,
+				tt.args.sorts,
+				tt.args.page,
+				tt.args.includeDeleted,
+			)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("buildListQuery() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -444,3 +651,35 @@ func Test_buildListQuery(t *testing.T) {
 		})
 	}
 }
+
+func Test_buildCountQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []userModels.Filter
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Test buildCountQuery",
+			filters: []userModels.Filter{{Key: "status", Value: 1, Comparison: userModels.ComparisonEqual}},
+			want:    "SELECT COUNT(*) FROM public.user WHERE status = $1 AND deleted_at IS NULL",
+		},
+		{
+			name:    "Test buildCountQuery with invalid filter",
+			filters: []userModels.Filter{{Key: "email", Value: "a", Comparison: userModels.ComparisonEqual}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := userRepo.BuildCountQuery(tt.filters, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("buildCountQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("buildCountQuery() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}